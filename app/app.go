@@ -0,0 +1,226 @@
+// Package app provides a router for Slack apps that does not depend on how
+// requests are delivered. Handlers are registered once on an App, which can
+// then be driven by a socket mode client (see App.RunSocketMode) or by the
+// Events API, slash command and interactivity HTTP endpoints (see
+// App.EventsHandler, App.SlashCommandsHandler and App.InteractionsHandler).
+//
+// Handlers return the response to the request. Over socket mode it is sent as
+// the payload of the ack, over HTTP it is JSON encoded as the response body.
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+	"github.com/incident-io/slack/socketmode"
+)
+
+// RequestType is the kind of request received from Slack.
+type RequestType string
+
+const (
+	RequestTypeEventsAPI    = RequestType(socketmode.RequestTypeEventsAPI)
+	RequestTypeSlashCommand = RequestType(socketmode.RequestTypeSlashCommands)
+	RequestTypeInteractive  = RequestType(socketmode.RequestTypeInteractive)
+)
+
+// ErrUnknownRequestType is returned by Dispatch for a Request without a payload
+// matching its Type.
+var ErrUnknownRequestType = errors.New("unknown request type")
+
+// Request is a request from Slack, independent of the transport it was
+// received on. Exactly one of EventsAPIEvent, SlashCommand and Interaction is
+// set, according to Type.
+type Request struct {
+	Type RequestType
+
+	EventsAPIEvent *slackevents.EventsAPIEvent
+	SlashCommand   *slack.SlashCommand
+	Interaction    *slack.InteractionCallback
+
+	// RetryAttempt and RetryReason are set when Slack redelivers a request
+	// that was not acknowledged in time.
+	RetryAttempt int
+	RetryReason  string
+
+	// SocketModeRequest is the underlying socket mode request, or nil when the
+	// request was received over HTTP.
+	SocketModeRequest *socketmode.Request
+}
+
+// HandlerFunc handles a Request. A non-nil response is sent back to Slack as
+// the ack payload in socket mode, or as the JSON body of the HTTP response.
+// Returning an error leaves the request unacknowledged (socket mode) or fails
+// it with a 500 status (HTTP), so that Slack may retry it.
+type HandlerFunc func(ctx context.Context, req *Request) (interface{}, error)
+
+// App routes requests from Slack to the registered handlers.
+type App struct {
+	events           map[slackevents.EventsAPIType]HandlerFunc
+	functionExecuted map[string]HandlerFunc
+	slashCommands    map[string]HandlerFunc
+
+	// interactions reuses the routing of slack.InteractionHandler. Its
+	// handlers recover the Request from the context.
+	interactions *slack.InteractionHandler
+
+	// socketModePool configures the worker pool of RunSocketMode
+	socketModePool socketmode.WorkerPoolConfig
+
+	Default HandlerFunc
+}
+
+// New returns an App without any handlers. Unhandled requests are
+// acknowledged with an empty response.
+func New() *App {
+	a := &App{
+		events:           make(map[slackevents.EventsAPIType]HandlerFunc),
+		functionExecuted: make(map[string]HandlerFunc),
+		slashCommands:    make(map[string]HandlerFunc),
+		interactions:     slack.NewInteractionHandler(""),
+		Default: func(ctx context.Context, req *Request) (interface{}, error) {
+			return nil, nil
+		},
+	}
+	a.interactions.HandleDefault(func(ctx context.Context, _ *slack.InteractionCallback) (interface{}, error) {
+		return a.Default(ctx, requestFromContext(ctx))
+	})
+	return a
+}
+
+// HandleEvent registers a handler for Events API events of the given inner
+// event type.
+func (a *App) HandleEvent(et slackevents.EventsAPIType, f HandlerFunc) {
+	mustRegister(string(et), f)
+	if _, exist := a.events[et]; exist {
+		panic("multiple registrations for event " + string(et))
+	}
+	a.events[et] = f
+}
+
+// HandleFunctionExecuted registers a handler for function_executed events of
+// the custom function with the given callback_id.
+func (a *App) HandleFunctionExecuted(callbackID string, f HandlerFunc) {
+	mustRegister(callbackID, f)
+	if _, exist := a.functionExecuted[callbackID]; exist {
+		panic("multiple registrations for function " + callbackID)
+	}
+	a.functionExecuted[callbackID] = f
+}
+
+// HandleSlashCommand registers a handler for a slash command, e.g. "/incident".
+// The handler may return a *slack.Msg to respond to the command.
+func (a *App) HandleSlashCommand(command string, f HandlerFunc) {
+	mustRegister(command, f)
+	if _, exist := a.slashCommands[command]; exist {
+		panic("multiple registrations for command " + command)
+	}
+	a.slashCommands[command] = f
+}
+
+// HandleInteraction registers a handler for every interaction of the given
+// type that is not matched by a more specific handler.
+func (a *App) HandleInteraction(t slack.InteractionType, f HandlerFunc) {
+	a.interactions.Handle(t, interactionHandler(f))
+}
+
+// HandleBlockAction registers a handler for block_actions by action_id.
+func (a *App) HandleBlockAction(actionID string, f HandlerFunc) {
+	a.interactions.HandleBlockAction(actionID, interactionHandler(f))
+}
+
+// HandleBlockSuggestion registers a handler for block_suggestion by action_id.
+// The handler usually returns a *slack.OptionsResponse.
+func (a *App) HandleBlockSuggestion(actionID string, f HandlerFunc) {
+	a.interactions.HandleBlockSuggestion(actionID, interactionHandler(f))
+}
+
+// HandleViewSubmission registers a handler for view_submission by the
+// callback_id of the view. The handler may return a
+// *slack.ViewSubmissionResponse.
+func (a *App) HandleViewSubmission(callbackID string, f HandlerFunc) {
+	a.interactions.HandleViewSubmission(callbackID, interactionHandler(f))
+}
+
+// HandleViewClosed registers a handler for view_closed by the callback_id of
+// the view.
+func (a *App) HandleViewClosed(callbackID string, f HandlerFunc) {
+	a.interactions.HandleViewClosed(callbackID, interactionHandler(f))
+}
+
+// HandleShortcut registers a handler for global shortcuts by callback_id.
+func (a *App) HandleShortcut(callbackID string, f HandlerFunc) {
+	a.interactions.HandleShortcut(callbackID, interactionHandler(f))
+}
+
+// HandleMessageShortcut registers a handler for message shortcuts by
+// callback_id.
+func (a *App) HandleMessageShortcut(callbackID string, f HandlerFunc) {
+	a.interactions.HandleMessageAction(callbackID, interactionHandler(f))
+}
+
+// HandleDefault registers a handler to use as a last resort.
+func (a *App) HandleDefault(f HandlerFunc) {
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+	a.Default = f
+}
+
+// Dispatch routes the request to the matching handler and returns its
+// response.
+func (a *App) Dispatch(ctx context.Context, req *Request) (interface{}, error) {
+	switch {
+	case req.Type == RequestTypeEventsAPI && req.EventsAPIEvent != nil:
+		return a.routeEvent(req.EventsAPIEvent)(ctx, req)
+	case req.Type == RequestTypeSlashCommand && req.SlashCommand != nil:
+		if f, ok := a.slashCommands[req.SlashCommand.Command]; ok {
+			return f(ctx, req)
+		}
+		return a.Default(ctx, req)
+	case req.Type == RequestTypeInteractive && req.Interaction != nil:
+		ctx = context.WithValue(ctx, requestContextKey{}, req)
+		return a.interactions.Route(req.Interaction)(ctx, req.Interaction)
+	default:
+		return nil, ErrUnknownRequestType
+	}
+}
+
+func (a *App) routeEvent(event *slackevents.EventsAPIEvent) HandlerFunc {
+	if fe, ok := event.InnerEvent.Data.(*slackevents.FunctionExecutedEvent); ok {
+		if f, ok := a.functionExecuted[fe.Function.CallbackID]; ok {
+			return f
+		}
+	}
+	if f, ok := a.events[slackevents.EventsAPIType(event.InnerEvent.Type)]; ok {
+		return f
+	}
+	return a.Default
+}
+
+type requestContextKey struct{}
+
+func requestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestContextKey{}).(*Request)
+	return req
+}
+
+func interactionHandler(f HandlerFunc) slack.InteractionHandlerFunc {
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+	return func(ctx context.Context, _ *slack.InteractionCallback) (interface{}, error) {
+		return f(ctx, requestFromContext(ctx))
+	}
+}
+
+func mustRegister(id string, f HandlerFunc) {
+	if id == "" {
+		panic("invalid id cannot be empty")
+	}
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+}
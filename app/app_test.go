@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+	"github.com/incident-io/slack/slacktest"
+	"github.com/incident-io/slack/socketmode"
+)
+
+const testSigningSecret = "secret"
+
+func signedRequest(t *testing.T, body string, header http.Header) *http.Request {
+	t.Helper()
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func respond(resp interface{}) HandlerFunc {
+	return func(ctx context.Context, req *Request) (interface{}, error) {
+		return resp, nil
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	a := New()
+	a.HandleEvent(slackevents.AppMention, respond("app_mention"))
+	a.HandleEvent(slackevents.FunctionExecuted, respond("any function"))
+	a.HandleFunctionExecuted("create_incident", respond("create_incident"))
+	a.HandleSlashCommand("/incident", respond("command"))
+	a.HandleBlockAction("approve", respond("block_action"))
+	a.HandleViewSubmission("incident_modal", respond("view_submission"))
+	a.HandleShortcut("declare", respond("shortcut"))
+	a.HandleMessageShortcut("declare", respond("message_shortcut"))
+	a.HandleDefault(respond("default"))
+
+	tests := []struct {
+		name     string
+		req      *Request
+		expected interface{}
+	}{
+		{
+			"event by type",
+			&Request{Type: RequestTypeEventsAPI, EventsAPIEvent: &slackevents.EventsAPIEvent{
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.AppMention)},
+			}},
+			"app_mention",
+		},
+		{
+			"function by callback_id",
+			&Request{Type: RequestTypeEventsAPI, EventsAPIEvent: &slackevents.EventsAPIEvent{
+				InnerEvent: slackevents.EventsAPIInnerEvent{
+					Type: string(slackevents.FunctionExecuted),
+					Data: functionExecutedEvent("create_incident"),
+				},
+			}},
+			"create_incident",
+		},
+		{
+			"function falls back to event type",
+			&Request{Type: RequestTypeEventsAPI, EventsAPIEvent: &slackevents.EventsAPIEvent{
+				InnerEvent: slackevents.EventsAPIInnerEvent{
+					Type: string(slackevents.FunctionExecuted),
+					Data: functionExecutedEvent("other"),
+				},
+			}},
+			"any function",
+		},
+		{
+			"slash command",
+			&Request{Type: RequestTypeSlashCommand, SlashCommand: &slack.SlashCommand{Command: "/incident"}},
+			"command",
+		},
+		{
+			"block action",
+			&Request{Type: RequestTypeInteractive, Interaction: &slack.InteractionCallback{
+				Type: slack.InteractionTypeBlockActions,
+				ActionCallback: slack.ActionCallbacks{
+					BlockActions: []*slack.BlockAction{{ActionID: "approve"}},
+				},
+			}},
+			"block_action",
+		},
+		{
+			"view submission",
+			&Request{Type: RequestTypeInteractive, Interaction: &slack.InteractionCallback{
+				Type: slack.InteractionTypeViewSubmission,
+				View: slack.View{CallbackID: "incident_modal"},
+			}},
+			"view_submission",
+		},
+		{
+			"message shortcut",
+			&Request{Type: RequestTypeInteractive, Interaction: &slack.InteractionCallback{
+				Type:       slack.InteractionTypeMessageAction,
+				CallbackID: "declare",
+			}},
+			"message_shortcut",
+		},
+		{
+			"unhandled interaction",
+			&Request{Type: RequestTypeInteractive, Interaction: &slack.InteractionCallback{
+				Type: slack.InteractionTypeViewClosed,
+			}},
+			"default",
+		},
+		{
+			"unhandled command",
+			&Request{Type: RequestTypeSlashCommand, SlashCommand: &slack.SlashCommand{Command: "/other"}},
+			"default",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := a.Dispatch(context.Background(), test.req)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, resp)
+		})
+	}
+
+	_, err := a.Dispatch(context.Background(), &Request{Type: RequestTypeEventsAPI})
+	assert.Equal(t, ErrUnknownRequestType, err)
+}
+
+func functionExecutedEvent(callbackID string) *slackevents.FunctionExecutedEvent {
+	e := &slackevents.FunctionExecutedEvent{}
+	e.Function.CallbackID = callbackID
+	return e
+}
+
+func TestInteractionHandlerReceivesRequest(t *testing.T) {
+	a := New()
+	a.HandleShortcut("declare", func(ctx context.Context, req *Request) (interface{}, error) {
+		return req.RetryAttempt, nil
+	})
+
+	resp, err := a.Dispatch(context.Background(), &Request{
+		Type:         RequestTypeInteractive,
+		Interaction:  &slack.InteractionCallback{Type: slack.InteractionTypeShortcut, CallbackID: "declare"},
+		RetryAttempt: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp)
+}
+
+func TestEventsHandler(t *testing.T) {
+	var received *Request
+	a := New()
+	a.HandleEvent(slackevents.AppMention, func(ctx context.Context, req *Request) (interface{}, error) {
+		received = req
+		return nil, nil
+	})
+	h := a.EventsHandler(testSigningSecret)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, `{"type":"url_verification","challenge":"abc","token":"t"}`, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "abc", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	header := http.Header{}
+	header.Set("X-Slack-Retry-Num", "1")
+	header.Set("X-Slack-Retry-Reason", "http_timeout")
+	h.ServeHTTP(rec, signedRequest(t, `{"type":"event_callback","event":{"type":"app_mention","text":"hi"}}`, header))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	if assert.NotNil(t, received) {
+		assert.Equal(t, RequestTypeEventsAPI, received.Type)
+		assert.Equal(t, 1, received.RetryAttempt)
+		assert.Equal(t, "http_timeout", received.RetryReason)
+		assert.Nil(t, received.SocketModeRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestSlashCommandsHandler(t *testing.T) {
+	a := New()
+	a.HandleSlashCommand("/incident", func(ctx context.Context, req *Request) (interface{}, error) {
+		return &slack.Msg{Text: "declared " + req.SlashCommand.Text}, nil
+	})
+	h := a.SlashCommandsHandler(testSigningSecret)
+
+	body := url.Values{"command": {"/incident"}, "text": {"outage"}}.Encode()
+	req := signedRequest(t, body, http.Header{"Content-Type": {"application/x-www-form-urlencoded"}})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var msg slack.Msg
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &msg))
+	assert.Equal(t, "declared outage", msg.Text)
+}
+
+func TestInteractionsHandler(t *testing.T) {
+	a := New()
+	a.HandleViewSubmission("incident_modal", func(ctx context.Context, req *Request) (interface{}, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	h := a.InteractionsHandler(testSigningSecret)
+
+	body := url.Values{"payload": {`{"type":"view_submission","view":{"callback_id":"incident_modal"}}`}}.Encode()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, body, nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestRequestFromSocketModeEvent(t *testing.T) {
+	smReq := &socketmode.Request{EnvelopeID: "e1", RetryAttempt: 3, RetryReason: "timeout"}
+
+	req := RequestFromSocketModeEvent(socketmode.Event{
+		Type:    socketmode.EventTypeSlashCommand,
+		Data:    slack.SlashCommand{Command: "/incident"},
+		Request: smReq,
+	})
+	if assert.NotNil(t, req) {
+		assert.Equal(t, RequestTypeSlashCommand, req.Type)
+		assert.Equal(t, "/incident", req.SlashCommand.Command)
+		assert.Equal(t, 3, req.RetryAttempt)
+		assert.Equal(t, "timeout", req.RetryReason)
+		assert.Equal(t, smReq, req.SocketModeRequest)
+	}
+
+	assert.Nil(t, RequestFromSocketModeEvent(socketmode.Event{Type: socketmode.EventTypeConnected}))
+	assert.Nil(t, RequestFromSocketModeEvent(socketmode.Event{Type: socketmode.EventTypeHello, Request: smReq}))
+}
+
+func TestRunSocketModeWorkerPool(t *testing.T) {
+	s := slacktest.NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	var running, peak int32
+	a := New()
+	a.UseSocketModeWorkerPool(socketmode.WorkerPoolConfig{MaxConcurrency: 1})
+	a.HandleSlashCommand("/incident", func(ctx context.Context, req *Request) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		if n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+		return map[string]string{"text": req.SlashCommand.Text}, nil
+	})
+
+	api := slack.New("xoxb-test", slack.OptionAPIURL(s.GetAPIURL()), slack.OptionAppLevelToken("xapp-test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.RunSocketMode(ctx, socketmode.New(api))
+
+	var envelopes []string
+	for i := 0; i < 3; i++ {
+		envelopes = append(envelopes, s.SendSlashCommand(slack.SlashCommand{Command: "/incident", Text: strconv.Itoa(i)}))
+	}
+	for _, id := range envelopes {
+		_, ok := s.WaitForSocketModeAck(id, time.Second)
+		assert.True(t, ok, "envelope %s was not acked", id)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&peak))
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+)
+
+// Retry headers sent by Slack when redelivering Events API requests.
+const (
+	hRetryNum    = "X-Slack-Retry-Num"
+	hRetryReason = "X-Slack-Retry-Reason"
+)
+
+// EventsHandler returns an http.Handler for the Events API request URL. It
+// answers url_verification challenges itself and dispatches event callbacks.
+func (a *App) EventsHandler(signingSecret string) http.Handler {
+	return a.httpHandler(signingSecret, func(w http.ResponseWriter, r *http.Request, body []byte) *Request {
+		event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		if event.Type == slackevents.URLVerification {
+			var challenge slackevents.ChallengeResponse
+			if err := json.Unmarshal(body, &challenge); err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return nil
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(challenge.Challenge))
+			return nil
+		}
+
+		retryAttempt, _ := strconv.Atoi(r.Header.Get(hRetryNum))
+		return &Request{
+			Type:           RequestTypeEventsAPI,
+			EventsAPIEvent: &event,
+			RetryAttempt:   retryAttempt,
+			RetryReason:    r.Header.Get(hRetryReason),
+		}
+	})
+}
+
+// SlashCommandsHandler returns an http.Handler for the request URL of slash
+// commands.
+func (a *App) SlashCommandsHandler(signingSecret string) http.Handler {
+	return a.httpHandler(signingSecret, func(w http.ResponseWriter, r *http.Request, body []byte) *Request {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		cmd, err := slack.SlashCommandParse(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+		return &Request{
+			Type:         RequestTypeSlashCommand,
+			SlashCommand: &cmd,
+		}
+	})
+}
+
+// InteractionsHandler returns an http.Handler for the interactivity request
+// URL.
+func (a *App) InteractionsHandler(signingSecret string) http.Handler {
+	return a.httpHandler(signingSecret, func(w http.ResponseWriter, r *http.Request, body []byte) *Request {
+		callback, err := slack.ParseInteractionCallback(body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+		return &Request{
+			Type:        RequestTypeInteractive,
+			Interaction: callback,
+		}
+	})
+}
+
+// httpHandler verifies the request signature, parses the request with parse
+// and writes the response of the dispatched handler. parse writes the response
+// itself and returns nil when there is nothing to dispatch.
+func (a *App) httpHandler(signingSecret string, parse func(http.ResponseWriter, *http.Request, []byte) *Request) http.Handler {
+	secrets := slack.NewSecretsMiddleware([]string{signingSecret})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		if err := secrets.Verify(r.Header, body); err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		req := parse(w, r, body)
+		if req == nil {
+			return
+		}
+
+		a.serve(r.Context(), w, req)
+	})
+}
+
+func (a *App) serve(ctx context.Context, w http.ResponseWriter, req *Request) {
+	resp, err := a.Dispatch(ctx, req)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if resp == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+	"github.com/incident-io/slack/socketmode"
+)
+
+// RunSocketMode connects the socket mode client and dispatches the requests it
+// receives until ctx is done or the client fails. The requests are handled on a
+// bounded worker pool, see UseSocketModeWorkerPool.
+func (a *App) RunSocketMode(ctx context.Context, client *socketmode.Client) error {
+	h := socketmode.NewSocketmodeHandler(client)
+	h.UseWorkerPool(a.socketModePool)
+	h.HandleDefault(func(evt *socketmode.Event, c *socketmode.Client) {
+		a.HandleSocketModeEvent(ctx, c, *evt)
+	})
+	return h.RunEventLoopContext(ctx)
+}
+
+// UseSocketModeWorkerPool configures the worker pool RunSocketMode handles the
+// requests on. Without it, the defaults of socketmode.WorkerPoolConfig apply. It
+// must be called before RunSocketMode.
+func (a *App) UseSocketModeWorkerPool(cfg socketmode.WorkerPoolConfig) {
+	a.socketModePool = cfg
+}
+
+// HandleSocketModeEvent dispatches a single event read from
+// socketmode.Client.Events and acks it with the handler's response. Events that
+// do not carry a request from Slack, such as connection events, are ignored.
+func (a *App) HandleSocketModeEvent(ctx context.Context, client *socketmode.Client, evt socketmode.Event) {
+	req := RequestFromSocketModeEvent(evt)
+	if req == nil {
+		return
+	}
+
+	resp, err := a.Dispatch(ctx, req)
+	if err != nil {
+		client.Debugf("Handler failed for envelope ID %q: %v", evt.Request.EnvelopeID, err)
+		return
+	}
+
+	if err := client.AckCtx(ctx, evt.Request.EnvelopeID, resp); err != nil {
+		client.Debugf("Failed to ack envelope ID %q: %v", evt.Request.EnvelopeID, err)
+	}
+}
+
+// RequestFromSocketModeEvent converts an event read from
+// socketmode.Client.Events into a Request. It returns nil for events that do
+// not carry a request from Slack.
+func RequestFromSocketModeEvent(evt socketmode.Event) *Request {
+	if evt.Request == nil {
+		return nil
+	}
+
+	req := &Request{
+		RetryAttempt:      evt.Request.RetryAttempt,
+		RetryReason:       evt.Request.RetryReason,
+		SocketModeRequest: evt.Request,
+	}
+
+	switch data := evt.Data.(type) {
+	case slackevents.EventsAPIEvent:
+		req.Type = RequestTypeEventsAPI
+		req.EventsAPIEvent = &data
+	case slack.SlashCommand:
+		req.Type = RequestTypeSlashCommand
+		req.SlashCommand = &data
+	case slack.InteractionCallback:
+		req.Type = RequestTypeInteractive
+		req.Interaction = &data
+	default:
+		return nil
+	}
+	return req
+}
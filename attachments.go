@@ -98,4 +98,30 @@ type Attachment struct {
 	FooterIcon string `json:"footer_icon,omitempty"`
 
 	Ts json.Number `json:"ts,omitempty"`
+
+	// UnknownFields holds any fields of the attachment that this library does
+	// not model. They are re-emitted when the attachment is marshalled.
+	UnknownFields map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Attachment,
+// retaining unrecognized fields in UnknownFields.
+func (a *Attachment) UnmarshalJSON(data []byte) error {
+	type alias Attachment
+	if err := json.Unmarshal(data, (*alias)(a)); err != nil {
+		return err
+	}
+	unknown, err := unmarshalUnknownFields(data, (*alias)(a))
+	if err != nil {
+		return err
+	}
+	a.UnknownFields = unknown
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for Attachment,
+// re-emitting any UnknownFields.
+func (a Attachment) MarshalJSON() ([]byte, error) {
+	type alias Attachment
+	return marshalWithUnknownFields((*alias)(&a), a.UnknownFields)
 }
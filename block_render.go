@@ -0,0 +1,763 @@
+package slack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/incident-io/slack/mrkdwn"
+)
+
+// TextFormat is a format blocks and attachments are rendered to by a TextRenderer.
+type TextFormat string
+
+const (
+	// TextFormatPlain is plain text, e.g. for logs and emails
+	TextFormatPlain TextFormat = "plain"
+	// TextFormatMarkdown is CommonMark, with the GitHub Flavored Markdown tables and strikethrough
+	TextFormatMarkdown TextFormat = "markdown"
+	// TextFormatMrkdwn is the mrkdwn of Slack, e.g. for the text of a message with blocks
+	TextFormatMrkdwn TextFormat = "mrkdwn"
+)
+
+// MentionLookup returns the name of the user, channel or user group of the ID, or "" if it is
+// unknown. kind is RTSEUser, RTSEChannel or RTSEUserGroup.
+type MentionLookup func(kind RichTextSectionElementType, id string) string
+
+// TextRenderer renders blocks and legacy attachments to text, e.g. to write the fallback text of
+// a message, or to log it.
+type TextRenderer struct {
+	format TextFormat
+	lookup MentionLookup
+	// controls renders the mentions, broadcasts, dates and emoji as in mrkdwn in every format, so
+	// that they can be parsed back
+	controls bool
+}
+
+// TextRendererOption configures a TextRenderer.
+type TextRendererOption func(*TextRenderer)
+
+// TextRendererOptionMentionLookup sets the lookup of the names of the mentioned users, channels
+// and user groups. Without it, their IDs are rendered. Mentions are not looked up in mrkdwn,
+// where Slack resolves them.
+func TextRendererOptionMentionLookup(lookup MentionLookup) TextRendererOption {
+	return func(r *TextRenderer) {
+		r.lookup = lookup
+	}
+}
+
+// NewTextRenderer returns a TextRenderer to the format
+func NewTextRenderer(format TextFormat, options ...TextRendererOption) *TextRenderer {
+	r := &TextRenderer{format: format}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// RenderBlocks renders the blocks. The interactive blocks, such as actions and inputs, are
+// left out.
+func (r *TextRenderer) RenderBlocks(blocks ...Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if s := r.renderBlock(block); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, r.blockSeparator())
+}
+
+// RenderAttachments renders the attachments, or their fallback when they have no content. Their
+// texts are converted as mrkdwn.
+func (r *TextRenderer) RenderAttachments(attachments ...Attachment) string {
+	parts := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		if s := r.renderAttachment(attachment); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, r.blockSeparator())
+}
+
+func (r *TextRenderer) blockSeparator() string {
+	if r.format == TextFormatMarkdown {
+		return "\n\n"
+	}
+	return "\n"
+}
+
+func (r *TextRenderer) renderBlock(block Block) string {
+	switch b := block.(type) {
+	case *SectionBlock:
+		return r.renderSection(b)
+	case *HeaderBlock:
+		return r.renderHeader(b)
+	case *ContextBlock:
+		return r.renderContext(b)
+	case *DividerBlock:
+		if r.format == TextFormatMarkdown {
+			return "---"
+		}
+		return ""
+	case *ImageBlock:
+		return r.renderImage(b.ImageURL, b.AltText, b.Title)
+	case *RichTextBlock:
+		return r.renderRichText(b)
+	case *TableBlock:
+		return r.renderTable(b)
+	case *MarkdownBlock:
+		return b.Text
+	case *VideoBlock:
+		return r.renderLink(b.VideoURL, r.renderTextObject(b.Title))
+	case *PlanBlock:
+		return r.renderPlan(b)
+	case *TaskCardBlock:
+		return r.renderTaskCard(b, "")
+	}
+	return ""
+}
+
+func (r *TextRenderer) renderSection(b *SectionBlock) string {
+	lines := []string{}
+	if s := r.renderTextObject(b.Text); s != "" {
+		lines = append(lines, s)
+	}
+	for _, field := range b.Fields {
+		if s := r.renderTextObject(field); s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return strings.Join(lines, r.blockSeparator())
+}
+
+func (r *TextRenderer) renderHeader(b *HeaderBlock) string {
+	s := r.renderTextObject(b.Text)
+	if s == "" {
+		return ""
+	}
+	switch r.format {
+	case TextFormatMarkdown:
+		return "# " + s
+	case TextFormatMrkdwn:
+		return wrapStyle(s, "*")
+	default:
+		return s
+	}
+}
+
+func (r *TextRenderer) renderContext(b *ContextBlock) string {
+	parts := []string{}
+	for _, element := range b.ContextElements.Elements {
+		if text, ok := element.(*TextBlockObject); ok {
+			if s := r.renderTextObject(text); s != "" {
+				parts = append(parts, s)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r *TextRenderer) renderImage(imageURL, altText string, title *TextBlockObject) string {
+	if r.format == TextFormatMarkdown && imageURL != "" {
+		return "![" + escapeMarkdown(altText) + "](" + escapeMarkdownURL(imageURL) + ")"
+	}
+	if s := r.renderTextObject(title); s != "" {
+		return s
+	}
+	return r.escape(altText)
+}
+
+func (r *TextRenderer) renderPlan(b *PlanBlock) string {
+	lines := []string{r.escape(b.Title)}
+	for i := range b.Tasks {
+		lines = append(lines, r.renderTaskCard(&b.Tasks[i], r.bullet()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *TextRenderer) renderTaskCard(b *TaskCardBlock, prefix string) string {
+	s := prefix + r.escape(b.Title)
+	if b.Status != "" {
+		s += " (" + string(b.Status) + ")"
+	}
+	return s
+}
+
+// renderTextObject renders a plain_text or mrkdwn text object
+func (r *TextRenderer) renderTextObject(text *TextBlockObject) string {
+	if text == nil {
+		return ""
+	}
+	if text.Type == MarkdownType {
+		return r.convertMrkdwn(text.Text)
+	}
+	return r.escape(text.Text)
+}
+
+// escape escapes plain text for the format
+func (r *TextRenderer) escape(s string) string {
+	switch r.format {
+	case TextFormatMarkdown:
+		return escapeMarkdown(s)
+	case TextFormatMrkdwn:
+		return escapeMrkdwn(s)
+	default:
+		return s
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "|", `\|`, "#", `\#`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownURLEscaper percent-encodes the characters ending the destination of a Markdown link
+var markdownURLEscaper = strings.NewReplacer("(", "%28", ")", "%29", "<", "%3C", ">", "%3E", " ", "%20")
+
+func escapeMarkdownURL(url string) string {
+	return markdownURLEscaper.Replace(url)
+}
+
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func escapeMrkdwn(s string) string {
+	return mrkdwnEscaper.Replace(s)
+}
+
+// convertMrkdwn converts the mrkdwn text of a text object to the format. In plain text, the
+// styles are kept as in mrkdwn, and only the links, mentions and dates are converted.
+func (r *TextRenderer) convertMrkdwn(s string) string {
+	if r.format == TextFormatMrkdwn {
+		return s
+	}
+	return r.renderMrkdwn(mrkdwn.Parse(s))
+}
+
+// renderMrkdwn renders parsed mrkdwn to the plain text or Markdown format
+func (r *TextRenderer) renderMrkdwn(nodes []mrkdwn.Node) string {
+	var sb strings.Builder
+	for i, node := range nodes {
+		switch n := node.(type) {
+		case *mrkdwn.Text:
+			sb.WriteString(r.escape(n.Text))
+		case *mrkdwn.Bold:
+			sb.WriteString(r.mrkdwnStyle(r.renderMrkdwn(n.Children), "*", &RichTextSectionTextStyle{Bold: true}))
+		case *mrkdwn.Italic:
+			sb.WriteString(r.mrkdwnStyle(r.renderMrkdwn(n.Children), "_", &RichTextSectionTextStyle{Italic: true}))
+		case *mrkdwn.Strike:
+			sb.WriteString(r.mrkdwnStyle(r.renderMrkdwn(n.Children), "~", &RichTextSectionTextStyle{Strike: true}))
+		case *mrkdwn.Code:
+			sb.WriteString(r.mrkdwnStyle(n.Text, "`", &RichTextSectionTextStyle{Code: true}))
+		case *mrkdwn.Pre:
+			if r.format != TextFormatMarkdown {
+				sb.WriteString("```" + n.Text + "```")
+				continue
+			}
+			// A fenced code block is on lines of its own
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("```\n" + strings.Trim(n.Text, "\n") + "\n```")
+			if i+1 < len(nodes) {
+				sb.WriteString("\n")
+			}
+		case *mrkdwn.Quote:
+			text := strings.TrimRight(r.renderMrkdwn(n.Children), "\n")
+			sb.WriteString("> " + strings.ReplaceAll(text, "\n", "\n> "))
+			if i+1 < len(nodes) {
+				sb.WriteString("\n")
+			}
+		case *mrkdwn.Link:
+			sb.WriteString(r.renderLink(n.URL, r.escape(n.Text)))
+		case *mrkdwn.UserMention:
+			sb.WriteString(r.mention(RTSEUser, n.ID, n.Label))
+		case *mrkdwn.ChannelMention:
+			sb.WriteString(r.mention(RTSEChannel, n.ID, n.Name))
+		case *mrkdwn.UsergroupMention:
+			sb.WriteString(r.mention(RTSEUserGroup, n.ID, n.Label))
+		case *mrkdwn.SpecialMention:
+			sb.WriteString("@" + n.Name)
+		case *mrkdwn.Date:
+			sb.WriteString(r.escape(n.Fallback))
+		}
+	}
+	return sb.String()
+}
+
+// mrkdwnStyle applies a style of mrkdwn to its rendered text, keeping the marker of mrkdwn in
+// plain text
+func (r *TextRenderer) mrkdwnStyle(s, marker string, style *RichTextSectionTextStyle) string {
+	if r.format == TextFormatPlain {
+		return marker + s + marker
+	}
+	return r.style(s, style)
+}
+
+// mention renders the mention of a user, channel or user group outside of mrkdwn
+func (r *TextRenderer) mention(kind RichTextSectionElementType, id, label string) string {
+	prefix := "@"
+	if kind == RTSEChannel {
+		prefix = "#"
+	}
+
+	name := ""
+	if r.lookup != nil {
+		name = r.lookup(kind, id)
+	}
+	if name == "" {
+		name = strings.TrimPrefix(strings.TrimPrefix(label, "@"), "#")
+	}
+	if name == "" {
+		name = id
+	}
+	return prefix + r.escape(name)
+}
+
+// renderLink renders a link, whose text is already rendered
+func (r *TextRenderer) renderLink(url, text string) string {
+	switch r.format {
+	case TextFormatMarkdown:
+		url = escapeMarkdownURL(url)
+		if text == "" {
+			return "<" + url + ">"
+		}
+		return "[" + text + "](" + url + ")"
+	case TextFormatMrkdwn:
+		if text == "" {
+			return "<" + url + ">"
+		}
+		return "<" + url + "|" + text + ">"
+	default:
+		if text == "" || text == url {
+			return url
+		}
+		return text + " (" + url + ")"
+	}
+}
+
+func (r *TextRenderer) bullet() string {
+	if r.format == TextFormatMarkdown {
+		return "- "
+	}
+	return "• "
+}
+
+func (r *TextRenderer) renderRichText(b *RichTextBlock) string {
+	var sb strings.Builder
+	var previous RichTextElement
+	for _, element := range b.Elements {
+		s := r.renderRichTextElement(element)
+		if s == "" {
+			continue
+		}
+		if previous != nil {
+			sb.WriteString(r.richTextSeparator(previous, element))
+		}
+		sb.WriteString(s)
+		previous = element
+	}
+	return sb.String()
+}
+
+// richTextSeparator returns the separator of two elements of a rich text block: the items of
+// consecutive lists are on consecutive lines, and the other elements are paragraphs in Markdown.
+func (r *TextRenderer) richTextSeparator(previous, next RichTextElement) string {
+	_, previousList := previous.(*RichTextList)
+	_, nextList := next.(*RichTextList)
+	if r.format == TextFormatMarkdown && !(previousList && nextList) {
+		return "\n\n"
+	}
+	return "\n"
+}
+
+func (r *TextRenderer) renderRichTextElement(element RichTextElement) string {
+	switch e := element.(type) {
+	case *RichTextSection:
+		return strings.TrimRight(r.renderInline(e.Elements, false), "\n")
+	case *RichTextList:
+		return r.renderList(e)
+	case *RichTextQuote:
+		text := strings.TrimRight(r.renderInline(e.Elements, false), "\n")
+		return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	case *RichTextPreformatted:
+		text := strings.TrimRight(r.renderInline(e.Elements, true), "\n")
+		if r.format == TextFormatPlain {
+			return text
+		}
+		return "```\n" + text + "\n```"
+	}
+	return ""
+}
+
+func (r *TextRenderer) renderList(list *RichTextList) string {
+	indent := "  "
+	if r.format == TextFormatMarkdown {
+		// The content of a nested item must be indented past the marker of its parent
+		indent = "    "
+	}
+	prefix := strings.Repeat(indent, list.Indent)
+
+	lines := make([]string, 0, len(list.Elements))
+	for i, item := range list.Elements {
+		marker := r.bullet()
+		if list.Style == RTEListOrdered {
+			marker = strconv.Itoa(list.Offset+i+1) + ". "
+		}
+		text := strings.TrimRight(r.renderRichTextElement(item), "\n")
+		text = strings.ReplaceAll(text, "\n", "\n"+prefix+strings.Repeat(" ", utf8.RuneCountInString(marker)))
+		lines = append(lines, prefix+marker+text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline renders the elements of a rich text section. raw elements are rendered without
+// styles nor escaping, as in preformatted text. The consecutive elements of a same style are
+// styled together, e.g. "*Hello <@U1>*".
+func (r *TextRenderer) renderInline(elements []RichTextSectionElement, raw bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(elements); {
+		style := richTextElementStyle(elements[i])
+		j := i + 1
+		for !raw && j < len(elements) && sameTextStyle(style, richTextElementStyle(elements[j])) {
+			j++
+		}
+
+		var group strings.Builder
+		for _, element := range elements[i:j] {
+			group.WriteString(r.renderInlineElement(element, raw))
+		}
+		if raw {
+			sb.WriteString(group.String())
+		} else {
+			sb.WriteString(r.style(group.String(), style))
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+// renderInlineElement renders an element of a rich text section, without its style
+func (r *TextRenderer) renderInlineElement(element RichTextSectionElement, raw bool) string {
+	switch e := element.(type) {
+	case *RichTextSectionTextElement:
+		if raw {
+			return e.Text
+		}
+		return r.renderRichTextText(e.Text, e.Style)
+	case *RichTextSectionLinkElement:
+		if raw && r.format != TextFormatMrkdwn {
+			return e.URL
+		}
+		text := e.Text
+		if !raw {
+			text = r.escape(text)
+		}
+		return r.renderLink(e.URL, text)
+	case *RichTextSectionUserElement:
+		return r.renderMention(RTSEUser, e.UserID, "<@"+e.UserID+">")
+	case *RichTextSectionChannelElement:
+		return r.renderMention(RTSEChannel, e.ChannelID, "<#"+e.ChannelID+">")
+	case *RichTextSectionUserGroupElement:
+		return r.renderMention(RTSEUserGroup, e.UsergroupID, "<!subteam^"+e.UsergroupID+">")
+	case *RichTextSectionTeamElement:
+		return r.escape(e.TeamID)
+	case *RichTextSectionBroadcastElement:
+		if r.format == TextFormatMrkdwn || r.controls {
+			return "<!" + e.Range + ">"
+		}
+		return "@" + e.Range
+	case *RichTextSectionEmojiElement:
+		if r.controls {
+			return emojiCode(e)
+		}
+		return renderEmoji(e)
+	case *RichTextSectionDateElement:
+		return r.renderDate(e)
+	case *RichTextSectionColorElement:
+		return r.escape(e.Value)
+	}
+	return ""
+}
+
+// richTextElementStyle returns the style of an element of a rich text section, or nil
+func richTextElementStyle(element RichTextSectionElement) *RichTextSectionTextStyle {
+	switch e := element.(type) {
+	case *RichTextSectionTextElement:
+		return e.Style
+	case *RichTextSectionLinkElement:
+		return e.Style
+	case *RichTextSectionUserElement:
+		return e.Style
+	case *RichTextSectionChannelElement:
+		return e.Style
+	case *RichTextSectionTeamElement:
+		return e.Style
+	case *RichTextSectionEmojiElement:
+		return e.Style
+	}
+	return nil
+}
+
+// sameTextStyle reports whether two styles are the same, nil being no style
+func sameTextStyle(a, b *RichTextSectionTextStyle) bool {
+	var zero RichTextSectionTextStyle
+	if a == nil {
+		a = &zero
+	}
+	if b == nil {
+		b = &zero
+	}
+	return *a == *b
+}
+
+func (r *TextRenderer) renderRichTextText(text string, style *RichTextSectionTextStyle) string {
+	if style != nil && style.Code {
+		return text
+	}
+	return r.escape(text)
+}
+
+func (r *TextRenderer) renderMention(kind RichTextSectionElementType, id, mrkdwn string) string {
+	if r.format == TextFormatMrkdwn || r.controls {
+		return mrkdwn
+	}
+	return r.mention(kind, id, "")
+}
+
+func (r *TextRenderer) renderDate(e *RichTextSectionDateElement) string {
+	if r.format == TextFormatMrkdwn || r.controls {
+		target := fmt.Sprintf("!date^%d^%s", e.Timestamp.Time().Unix(), e.Format)
+		if e.URL != nil {
+			target += "^" + *e.URL
+		}
+		switch {
+		case e.Fallback != nil:
+			return "<" + target + "|" + escapeMrkdwn(*e.Fallback) + ">"
+		case r.controls:
+			return "<" + target + ">"
+		default:
+			return "<" + target + "|" + e.Timestamp.Time().UTC().Format(time.RFC1123) + ">"
+		}
+	}
+	if e.Fallback != nil {
+		return r.escape(*e.Fallback)
+	}
+	return e.Timestamp.Time().UTC().Format(time.RFC1123)
+}
+
+// emojiCode returns the code of an emoji, e.g. ":thumbsup::skin-tone-2:"
+func emojiCode(e *RichTextSectionEmojiElement) string {
+	if e.SkinTone > 1 {
+		return fmt.Sprintf(":%s::skin-tone-%d:", e.Name, e.SkinTone)
+	}
+	return ":" + e.Name + ":"
+}
+
+// renderEmoji renders the unicode of an emoji, e.g. "1f44d-1f3fb", or its code
+func renderEmoji(e *RichTextSectionEmojiElement) string {
+	if e.Unicode != "" {
+		var sb strings.Builder
+		for _, code := range strings.Split(e.Unicode, "-") {
+			r, err := strconv.ParseInt(code, 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return emojiCode(e)
+			}
+			sb.WriteRune(rune(r))
+		}
+		return sb.String()
+	}
+	return emojiCode(e)
+}
+
+// style applies the style of a rich text element to its rendered text
+func (r *TextRenderer) style(s string, style *RichTextSectionTextStyle) string {
+	if style == nil || r.format == TextFormatPlain || strings.TrimSpace(s) == "" {
+		return s
+	}
+	if style.Code {
+		s = wrapStyle(s, "`")
+	}
+	if style.Strike {
+		if r.format == TextFormatMarkdown {
+			s = wrapStyle(s, "~~")
+		} else {
+			s = wrapStyle(s, "~")
+		}
+	}
+	if style.Italic {
+		if r.format == TextFormatMarkdown {
+			s = wrapStyle(s, "*")
+		} else {
+			s = wrapStyle(s, "_")
+		}
+	}
+	if style.Bold {
+		if r.format == TextFormatMarkdown {
+			s = wrapStyle(s, "**")
+		} else {
+			s = wrapStyle(s, "*")
+		}
+	}
+	return s
+}
+
+// wrapStyle wraps s in the marker, leaving its surrounding spaces out, as a marker followed by a
+// space does not open a style
+func wrapStyle(s, marker string) string {
+	trimmed := strings.TrimSpace(s)
+	start := strings.Index(s, trimmed)
+	return s[:start] + marker + trimmed + marker + s[start+len(trimmed):]
+}
+
+func (r *TextRenderer) renderTable(b *TableBlock) string {
+	rows := make([][]string, len(b.Rows))
+	columns := 0
+	for i, row := range b.Rows {
+		rows[i] = make([]string, len(row))
+		for j, cell := range row {
+			rows[i][j] = r.renderCell(cell)
+		}
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	align := func(column int) ColumnAlignment {
+		if column < len(b.ColumnSettings) {
+			return b.ColumnSettings[column].Align
+		}
+		return ColumnAlignmentLeft
+	}
+
+	if r.format == TextFormatMarkdown {
+		lines := make([]string, 0, len(rows)+1)
+		for i, row := range rows {
+			cells := make([]string, columns)
+			copy(cells, row)
+			lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 {
+				separators := make([]string, columns)
+				for j := range separators {
+					switch align(j) {
+					case ColumnAlignmentCenter:
+						separators[j] = ":---:"
+					case ColumnAlignmentRight:
+						separators[j] = "---:"
+					default:
+						separators[j] = "---"
+					}
+				}
+				lines = append(lines, "| "+strings.Join(separators, " | ")+" |")
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	// The columns are padded to be aligned in a monospace font
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for j, cell := range row {
+			if w := utf8.RuneCountInString(cell); w > widths[j] {
+				widths[j] = w
+			}
+		}
+	}
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]string, columns)
+		for j := range cells {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells[j] = pad(cell, widths[j], align(j))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	table := strings.Join(lines, "\n")
+	if r.format == TextFormatMrkdwn {
+		return "```\n" + table + "\n```"
+	}
+	return table
+}
+
+// renderCell renders a cell of a table on a line. In mrkdwn, the table is preformatted, so the
+// cells are plain text.
+func (r *TextRenderer) renderCell(cell *RichTextBlock) string {
+	if cell == nil {
+		return ""
+	}
+	cr := r
+	if r.format == TextFormatMrkdwn {
+		cr = &TextRenderer{format: TextFormatPlain, lookup: r.lookup}
+	}
+	s := strings.Join(strings.Fields(cr.renderRichText(cell)), " ")
+	if r.format == TextFormatMarkdown {
+		// A pipe ends the cell, even when escaped by the rich text
+		s = strings.ReplaceAll(strings.ReplaceAll(s, `\|`, "|"), "|", `\|`)
+	}
+	return s
+}
+
+func pad(s string, width int, align ColumnAlignment) string {
+	missing := width - utf8.RuneCountInString(s)
+	if missing <= 0 {
+		return s
+	}
+	switch align {
+	case ColumnAlignmentRight:
+		return strings.Repeat(" ", missing) + s
+	case ColumnAlignmentCenter:
+		left := missing / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", missing-left)
+	default:
+		return s + strings.Repeat(" ", missing)
+	}
+}
+
+func (r *TextRenderer) renderAttachment(a Attachment) string {
+	parts := []string{}
+	add := func(s string) {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	add(r.convertMrkdwn(a.Pretext))
+	add(r.escape(a.AuthorName))
+	if a.Title != "" {
+		title := r.escape(a.Title)
+		if a.TitleLink != "" {
+			title = r.renderLink(a.TitleLink, title)
+		}
+		add(title)
+	}
+	add(r.convertMrkdwn(a.Text))
+	for _, field := range a.Fields {
+		value := r.convertMrkdwn(field.Value)
+		switch {
+		case field.Title == "":
+			add(value)
+		case r.format == TextFormatMarkdown:
+			add("**" + escapeMarkdown(field.Title) + "**: " + value)
+		case r.format == TextFormatMrkdwn:
+			add("*" + escapeMrkdwn(field.Title) + "*: " + value)
+		default:
+			add(field.Title + ": " + value)
+		}
+	}
+	add(r.RenderBlocks(a.Blocks.BlockSet...))
+	add(r.convertMrkdwn(a.Footer))
+
+	if len(parts) == 0 {
+		return r.escape(a.Fallback)
+	}
+	return strings.Join(parts, r.blockSeparator())
+}
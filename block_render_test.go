@@ -0,0 +1,142 @@
+package slack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRenderBlocks() []Block {
+	bold := &RichTextSectionTextStyle{Bold: true}
+	return []Block{
+		NewHeaderBlock(NewTextBlockObject(PlainTextType, "Deploy 1.2", false, false)),
+		NewSectionBlock(NewTextBlockObject(MarkdownType, "*Done* by <@U1> in <#C1|ops>, see <https://example.com|the logs>", false, false), nil, nil),
+		NewDividerBlock(),
+		NewRichTextBlock("",
+			NewRichTextSection(
+				NewRichTextSectionTextElement("Hello ", nil),
+				NewRichTextSectionUserElement("U1", nil),
+				NewRichTextSectionTextElement(" shipped ", nil),
+				NewRichTextSectionTextElement("it ", bold),
+				NewRichTextSectionEmojiElement("tada", 0, nil),
+			),
+			NewRichTextList(RTEListBullet, 0,
+				NewRichTextSection(NewRichTextSectionLinkElement("https://example.com/a", "a", nil)),
+				NewRichTextSection(NewRichTextSectionTextElement("b", &RichTextSectionTextStyle{Code: true})),
+			),
+			NewRichTextList(RTEListOrdered, 1,
+				NewRichTextSection(NewRichTextSectionTextElement("c", &RichTextSectionTextStyle{Italic: true})),
+			),
+			&RichTextQuote{Type: RTEQuote, Elements: []RichTextSectionElement{NewRichTextSectionTextElement("quoted\ntwice", nil)}},
+			&RichTextPreformatted{RichTextSection: RichTextSection{Type: RTEPreformatted, Elements: []RichTextSectionElement{NewRichTextSectionTextElement("x := 1", nil)}}},
+		),
+		NewActionBlock("", NewButtonBlockElement("ok", "ok", NewTextBlockObject(PlainTextType, "OK", false, false))),
+	}
+}
+
+func TestTextRendererPlain(t *testing.T) {
+	lookup := func(kind RichTextSectionElementType, id string) string {
+		if kind == RTSEUser && id == "U1" {
+			return "alice"
+		}
+		return ""
+	}
+	r := NewTextRenderer(TextFormatPlain, TextRendererOptionMentionLookup(lookup))
+	assert.Equal(t, "Deploy 1.2\n"+
+		"*Done* by @alice in #ops, see the logs (https://example.com)\n"+
+		"Hello @alice shipped it :tada:\n"+
+		"• a (https://example.com/a)\n"+
+		"• b\n"+
+		"  1. c\n"+
+		"> quoted\n"+
+		"> twice\n"+
+		"x := 1", r.RenderBlocks(testRenderBlocks()...))
+}
+
+func TestTextRendererMarkdown(t *testing.T) {
+	r := NewTextRenderer(TextFormatMarkdown)
+	assert.Equal(t, "# Deploy 1.2\n\n"+
+		"**Done** by @U1 in #ops, see [the logs](https://example.com)\n\n"+
+		"---\n\n"+
+		"Hello @U1 shipped **it** :tada:\n\n"+
+		"- [a](https://example.com/a)\n"+
+		"- `b`\n"+
+		"    1. *c*\n\n"+
+		"> quoted\n"+
+		"> twice\n\n"+
+		"```\nx := 1\n```", r.RenderBlocks(testRenderBlocks()...))
+}
+
+func TestTextRendererConvertsMrkdwn(t *testing.T) {
+	section := NewSectionBlock(NewTextBlockObject(MarkdownType, "*bold _both_* ~gone~ snake_case `a*b*` &lt;tag&gt;\n"+
+		"&gt; quoted\n"+
+		"see ```x := 1``` and <https://en.wikipedia.org/wiki/Go_(language)|Go (language)> <!here>", false, false), nil, nil)
+
+	assert.Equal(t, "*bold _both_* ~gone~ snake_case `a*b*` <tag>\n"+
+		"> quoted\n"+
+		"see ```x := 1``` and Go (language) (https://en.wikipedia.org/wiki/Go_(language)) @here",
+		NewTextRenderer(TextFormatPlain).RenderBlocks(section))
+	assert.Equal(t, "**bold *both*** ~~gone~~ snake\\_case `a*b*` \\<tag\\>\n"+
+		"> quoted\n"+
+		"see \n```\nx := 1\n```\n and [Go (language)](https://en.wikipedia.org/wiki/Go_%28language%29) @here",
+		NewTextRenderer(TextFormatMarkdown).RenderBlocks(section))
+}
+
+func TestTextRendererMrkdwn(t *testing.T) {
+	r := NewTextRenderer(TextFormatMrkdwn)
+	assert.Equal(t, "*Deploy 1.2*\n"+
+		"*Done* by <@U1> in <#C1|ops>, see <https://example.com|the logs>\n"+
+		"Hello <@U1> shipped *it* :tada:\n"+
+		"• <https://example.com/a|a>\n"+
+		"• `b`\n"+
+		"  1. _c_\n"+
+		"> quoted\n"+
+		"> twice\n"+
+		"```\nx := 1\n```", r.RenderBlocks(testRenderBlocks()...))
+}
+
+func TestTextRendererTable(t *testing.T) {
+	cell := func(s string) *RichTextBlock {
+		return NewRichTextBlock("", NewRichTextSection(NewRichTextSectionTextElement(s, nil)))
+	}
+	table := NewTableBlock("").
+		WithColumnSettings(ColumnSetting{Align: ColumnAlignmentLeft}, ColumnSetting{Align: ColumnAlignmentRight}).
+		AddRow(cell("Service"), cell("Errors")).
+		AddRow(cell("api|v2"), cell("12")).
+		AddRow(cell("web"), cell("3"))
+
+	assert.Equal(t, "| Service | Errors |\n"+
+		"| --- | ---: |\n"+
+		"| api\\|v2 | 12 |\n"+
+		"| web | 3 |", NewTextRenderer(TextFormatMarkdown).RenderBlocks(table))
+	assert.Equal(t, "Service  Errors\n"+
+		"api|v2       12\n"+
+		"web           3", NewTextRenderer(TextFormatPlain).RenderBlocks(table))
+}
+
+func TestTextRendererEscapesValues(t *testing.T) {
+	block := NewRichTextBlock("",
+		NewRichTextList(RTEListBullet, 0, NewRichTextSection(
+			NewRichTextSectionTextElement("team ", nil),
+			NewRichTextSectionTeamElement("T<1>", nil),
+			NewRichTextSectionTextElement("\ncolor ", nil),
+			NewRichTextSectionColorElement("#ff0000"),
+		)),
+	)
+	assert.Equal(t, "• team T&lt;1&gt;\n  color #ff0000", NewTextRenderer(TextFormatMrkdwn).RenderBlocks(block))
+	assert.Equal(t, "- team T\\<1\\>\n  color \\#ff0000", NewTextRenderer(TextFormatMarkdown).RenderBlocks(block))
+}
+
+func TestTextRendererAttachments(t *testing.T) {
+	attachments := []Attachment{
+		{
+			Pretext: "Alert",
+			Title:   "CPU high",
+			Text:    "on <https://example.com|host-1> &amp; host-2",
+			Fields:  []AttachmentField{{Title: "Value", Value: "93%"}},
+		},
+		{Fallback: "Only a fallback"},
+	}
+	assert.Equal(t, "Alert\nCPU high\non host-1 (https://example.com) & host-2\nValue: 93%\nOnly a fallback",
+		NewTextRenderer(TextFormatPlain).RenderAttachments(attachments...))
+}
@@ -0,0 +1,704 @@
+package slack
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/incident-io/slack/mrkdwn"
+)
+
+// RichTextToMarkdown converts a rich text block to CommonMark. The mentions, broadcasts and dates
+// are written as in mrkdwn, e.g. <@U024BE7LH>, and the emoji as their codes, e.g. :tada:, so that
+// RichTextFromMarkdown converts them back.
+func RichTextToMarkdown(b *RichTextBlock) string {
+	r := &TextRenderer{format: TextFormatMarkdown, controls: true}
+	return r.renderRichText(b)
+}
+
+// RichTextToMrkdwn converts a rich text block to mrkdwn. The emoji are written as their codes,
+// e.g. :tada:, so that RichTextFromMrkdwn converts them back.
+func RichTextToMrkdwn(b *RichTextBlock) string {
+	r := &TextRenderer{format: TextFormatMrkdwn, controls: true}
+	return r.renderRichText(b)
+}
+
+// RichTextFromMarkdown converts CommonMark to a rich text block: its paragraphs, lists, quotes,
+// code blocks, emphasis, code spans and links, and the mentions, broadcasts, dates and emoji
+// written as in mrkdwn. Line breaks are kept, and the other markup, e.g. headings, is kept as
+// text.
+func RichTextFromMarkdown(blockID, markdown string) *RichTextBlock {
+	return NewRichTextBlock(blockID, parseMarkdown(markdown)...)
+}
+
+// RichTextFromMrkdwn converts mrkdwn to a rich text block: its lines, lists starting with "•" or
+// "-", quotes, preformatted blocks, styles, links, mentions, broadcasts, dates and emoji. The
+// markup is parsed as by mrkdwn.Parse.
+func RichTextFromMrkdwn(blockID, text string) *RichTextBlock {
+	return NewRichTextBlock(blockID, parseMrkdwn(text)...)
+}
+
+var (
+	markdownListItem = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)]) +(.*)$`)
+	mrkdwnListItem   = regexp.MustCompile(`^( *)([-•◦▪]|\d{1,9}\.) +(.*)$`)
+)
+
+// richTextLists groups the items of lists in levels. The items indented more than the previous
+// ones are nested in them.
+type richTextLists struct {
+	list    *RichTextList // the current list
+	indents []int         // the indents of the levels of the current list
+}
+
+// next returns the list of an item of the marker at the indent, and whether the list is new
+func (l *richTextLists) next(indent int, marker string) (*RichTextList, bool) {
+	for len(l.indents) > 0 && l.indents[len(l.indents)-1] > indent {
+		l.indents = l.indents[:len(l.indents)-1]
+	}
+	if len(l.indents) == 0 || l.indents[len(l.indents)-1] < indent {
+		l.indents = append(l.indents, indent)
+	}
+	level := len(l.indents) - 1
+
+	style, offset := RTEListBullet, 0
+	if n, err := strconv.Atoi(strings.TrimRight(marker, ".)")); err == nil {
+		style, offset = RTEListOrdered, n-1
+	}
+	if l.list != nil && l.list.Indent == level && l.list.Style == style {
+		return l.list, false
+	}
+	l.list = NewRichTextList(style, level)
+	l.list.Offset = offset
+	return l.list, true
+}
+
+func (l *richTextLists) reset() {
+	l.list = nil
+	l.indents = nil
+}
+
+// newRichTextPreformatted returns a preformatted block of the text
+func newRichTextPreformatted(text string) *RichTextPreformatted {
+	var elements []RichTextSectionElement
+	if text != "" {
+		elements = append(elements, NewRichTextSectionTextElement(text, nil))
+	}
+	return &RichTextPreformatted{
+		RichTextSection: RichTextSection{Type: RTEPreformatted, Elements: elements},
+	}
+}
+
+// markdownParser parses the lines of Markdown to the elements of a rich text block
+type markdownParser struct {
+	richTextLists
+	elements []RichTextElement
+	lines    []string // of the current section
+	item     []string // the lines of the current item of the list
+}
+
+func parseMarkdown(s string) []RichTextElement {
+	p := &markdownParser{}
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "```") {
+			i = p.parsePreformatted(lines, i)
+			continue
+		}
+		if strings.HasPrefix(line, ">") {
+			i = p.parseQuote(lines, i)
+			continue
+		}
+		if p.parseListItem(line) {
+			continue
+		}
+		if p.list != nil && strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" {
+			p.item = append(p.item, strings.TrimLeft(line, " "))
+			continue
+		}
+		p.endList()
+		p.lines = append(p.lines, line)
+	}
+	p.endList()
+	p.endSection()
+	return p.elements
+}
+
+// endSection appends the current section, without its leading and trailing blank lines
+func (p *markdownParser) endSection() {
+	lines := p.lines
+	p.lines = nil
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 {
+		p.elements = append(p.elements, NewRichTextSection(parseMarkdownInline(strings.Join(lines, "\n"))...))
+	}
+}
+
+// parsePreformatted parses the code block opened at lines[start], and returns the index of its
+// last line. A block which is not closed is parsed as text.
+func (p *markdownParser) parsePreformatted(lines []string, start int) int {
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "```" {
+			p.endList()
+			p.endSection()
+			p.elements = append(p.elements, newRichTextPreformatted(strings.Join(lines[start+1:i], "\n")))
+			return i
+		}
+	}
+
+	p.endList()
+	p.lines = append(p.lines, lines[start])
+	return start
+}
+
+// parseQuote parses the quote starting at lines[start], and returns the index of its last line
+func (p *markdownParser) parseQuote(lines []string, start int) int {
+	p.endList()
+	p.endSection()
+
+	var quote []string
+	end := start
+	for ; end < len(lines); end++ {
+		rest, ok := strings.CutPrefix(lines[end], ">")
+		if !ok {
+			break
+		}
+		quote = append(quote, strings.TrimPrefix(rest, " "))
+	}
+
+	p.elements = append(p.elements, &RichTextQuote{
+		Type:     RTEQuote,
+		Elements: parseMarkdownInline(strings.Join(quote, "\n")),
+	})
+	return end - 1
+}
+
+// parseListItem parses the line if it is an item of a list
+func (p *markdownParser) parseListItem(line string) bool {
+	m := markdownListItem.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	p.endItem()
+	p.endSection()
+
+	if list, ok := p.next(len(m[1]), m[2]); ok {
+		p.elements = append(p.elements, list)
+	}
+	p.item = []string{m[3]}
+	return true
+}
+
+// endItem appends the current item to the current list
+func (p *markdownParser) endItem() {
+	if p.list != nil && p.item != nil {
+		p.list.Elements = append(p.list.Elements, NewRichTextSection(parseMarkdownInline(strings.Join(p.item, "\n"))...))
+	}
+	p.item = nil
+}
+
+// endList ends the current list
+func (p *markdownParser) endList() {
+	p.endItem()
+	p.reset()
+}
+
+var emojiCodePattern = regexp.MustCompile(`^:([a-z0-9_+'-]+):(?::skin-tone-([2-6]):)?`)
+
+// parseMarkdownInline parses the emphasis, code spans, links, mentions, broadcasts, dates and
+// emoji of a section
+func parseMarkdownInline(s string) []RichTextSectionElement {
+	var elements []RichTextSectionElement
+	parseMarkdownStyled(&elements, s, RichTextSectionTextStyle{})
+	return elements
+}
+
+func parseMarkdownStyled(elements *[]RichTextSectionElement, s string, style RichTextSectionTextStyle) {
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			appendRichTextText(elements, text.String(), style)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case '\\':
+			if i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				text.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+		case '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				flush()
+				codeStyle := style
+				codeStyle.Code = true
+				appendRichTextText(elements, s[i+1:i+1+end], codeStyle)
+				i += end + 2
+				continue
+			}
+		case '<':
+			if end := strings.IndexByte(s[i+1:], '>'); end > 0 {
+				if element := markdownControl(s[i:i+end+2], style); element != nil {
+					flush()
+					*elements = append(*elements, element)
+					i += end + 2
+					continue
+				}
+			}
+		case '[':
+			if element, n := parseMarkdownLink(s[i:], style); element != nil {
+				flush()
+				*elements = append(*elements, element)
+				i += n
+				continue
+			}
+		case ':':
+			if emoji, n := cutEmoji(s, i, style); emoji != nil {
+				flush()
+				*elements = append(*elements, emoji)
+				i += n
+				continue
+			}
+		case '*', '_', '~':
+			n, end := emphasis(s, i)
+			if end < 0 {
+				// The whole run is text, so that its end does not open a style
+				text.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			parseMarkdownStyled(elements, s[i+n:end], applyMarker(style, c, n))
+			i = end + n
+			continue
+		}
+		text.WriteByte(s[i])
+		i++
+	}
+	flush()
+}
+
+// emphasis returns the length of the run of markers at start, and the index of the run closing
+// it, or -1. A run opens when it is followed by a non-space, and a run of the same length closes
+// it when it follows a non-space, e.g. "*a **b** c*". The runs of "_" must also be at the
+// boundaries of words.
+func emphasis(s string, start int) (int, int) {
+	c := s[start]
+	n := markerRun(s, start)
+	boundary := c == '_'
+
+	switch {
+	case n > 3, c == '~' && n > 2:
+		return n, -1
+	case start+n >= len(s) || isSpaceByte(s[start+n]):
+		return n, -1
+	}
+	if r, _ := utf8.DecodeLastRuneInString(s[:start]); boundary && start > 0 && isWordRune(r) {
+		return n, -1
+	}
+
+	for j := start + n; j < len(s); {
+		if s[j] != c {
+			j++
+			continue
+		}
+		m := markerRun(s, j)
+		r, _ := utf8.DecodeRuneInString(s[j+m:])
+		if m == n && !isSpaceByte(s[j-1]) && !(boundary && j+m < len(s) && isWordRune(r)) {
+			return n, j
+		}
+		j += m
+	}
+	return n, -1
+}
+
+// applyMarker returns the style applied by a run of n markers
+func applyMarker(style RichTextSectionTextStyle, c byte, n int) RichTextSectionTextStyle {
+	if c == '~' {
+		style.Strike = true
+		return style
+	}
+	style.Italic = style.Italic || n != 2
+	style.Bold = style.Bold || n >= 2
+	return style
+}
+
+func markerRun(s string, start int) int {
+	n := 1
+	for start+n < len(s) && s[start+n] == s[start] {
+		n++
+	}
+	return n
+}
+
+// markdownControl converts a link, mention, broadcast or date written as in mrkdwn, e.g.
+// "<@U024BE7LH>" or "<https://slack.com|Slack>", or returns nil. As in CommonMark, the links
+// without a scheme are text, e.g. "<b>".
+func markdownControl(s string, style RichTextSectionTextStyle) RichTextSectionElement {
+	nodes := mrkdwn.Parse(s)
+	if len(nodes) != 1 {
+		return nil
+	}
+	if link, ok := nodes[0].(*mrkdwn.Link); ok && (!strings.Contains(link.URL, ":") || strings.ContainsAny(link.URL, " \n")) {
+		return nil
+	}
+
+	var elements []RichTextSectionElement
+	appendMrkdwnInline(&elements, nodes, style)
+	if len(elements) != 1 {
+		return nil
+	}
+	if _, ok := elements[0].(*RichTextSectionTextElement); ok {
+		return nil
+	}
+	return elements[0]
+}
+
+// parseMarkdownLink parses the Markdown link at the start of s, [text](url), and returns its
+// length
+func parseMarkdownLink(s string, style RichTextSectionTextStyle) (RichTextSectionElement, int) {
+	end := 1
+	for ; end < len(s) && s[end] != ']'; end++ {
+		if s[end] == '\\' {
+			end++
+		}
+	}
+	if end+1 >= len(s) || s[end+1] != '(' {
+		return nil, 0
+	}
+	urlEnd := strings.IndexByte(s[end+2:], ')')
+	if urlEnd < 0 {
+		return nil, 0
+	}
+	url := strings.TrimSpace(s[end+2 : end+2+urlEnd])
+	if url == "" || strings.ContainsAny(url, " \n") {
+		return nil, 0
+	}
+	return NewRichTextSectionLinkElement(url, unescapeMarkdown(s[1:end]), textStylePtr(style)), end + 3 + urlEnd
+}
+
+// mrkdwnConverter converts parsed mrkdwn to the elements of a rich text block. The preformatted
+// blocks, quotes and inline markup are parsed by the mrkdwn package, and the lists from the lines
+// of the text between them.
+type mrkdwnConverter struct {
+	richTextLists
+	elements []RichTextElement
+	lines    [][]RichTextSectionElement // of the current section
+	item     [][]RichTextSectionElement // the lines of the current item of the list
+}
+
+func parseMrkdwn(s string) []RichTextElement {
+	c := &mrkdwnConverter{}
+	var text []RichTextSectionElement
+	endText := func() {
+		for _, line := range splitRichTextLines(text) {
+			c.addLine(line)
+		}
+		text = nil
+		c.endList()
+		c.endSection()
+	}
+
+	afterPre := false
+	for _, node := range mrkdwn.Parse(strings.ReplaceAll(s, "\r\n", "\n")) {
+		switch n := node.(type) {
+		case *mrkdwn.Pre:
+			endText()
+			c.elements = append(c.elements, newRichTextPreformatted(n.Text))
+		case *mrkdwn.Quote:
+			endText()
+			quote := &RichTextQuote{Type: RTEQuote}
+			appendMrkdwnInline(&quote.Elements, n.Children, RichTextSectionTextStyle{})
+			c.elements = append(c.elements, quote)
+		case *mrkdwn.Text:
+			if afterPre {
+				// The text following a preformatted block on its line starts a section
+				appendMrkdwnText(&text, strings.TrimLeft(n.Text, " "), RichTextSectionTextStyle{})
+			} else {
+				appendMrkdwnText(&text, n.Text, RichTextSectionTextStyle{})
+			}
+		default:
+			appendMrkdwnInline(&text, []mrkdwn.Node{node}, RichTextSectionTextStyle{})
+		}
+		_, afterPre = node.(*mrkdwn.Pre)
+	}
+	endText()
+	return c.elements
+}
+
+// addLine adds a line of text to the current section, or list
+func (c *mrkdwnConverter) addLine(line []RichTextSectionElement) {
+	if indent, marker, rest, ok := cutMrkdwnListItem(line); ok {
+		c.endItem()
+		c.endSection()
+		if list, ok := c.next(indent, marker); ok {
+			c.elements = append(c.elements, list)
+		}
+		c.item = [][]RichTextSectionElement{rest}
+		return
+	}
+	if c.list != nil && !isBlankRichTextLine(line) {
+		if rest, ok := cutRichTextIndent(line); ok {
+			c.item = append(c.item, rest)
+			return
+		}
+	}
+	c.endList()
+	c.lines = append(c.lines, line)
+}
+
+// endSection appends the current section, without its leading and trailing blank lines
+func (c *mrkdwnConverter) endSection() {
+	lines := c.lines
+	c.lines = nil
+	for len(lines) > 0 && isBlankRichTextLine(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && isBlankRichTextLine(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 {
+		c.elements = append(c.elements, NewRichTextSection(joinRichTextLines(lines)...))
+	}
+}
+
+// endItem appends the current item to the current list
+func (c *mrkdwnConverter) endItem() {
+	if c.list != nil && c.item != nil {
+		c.list.Elements = append(c.list.Elements, NewRichTextSection(joinRichTextLines(c.item)...))
+	}
+	c.item = nil
+}
+
+// endList ends the current list
+func (c *mrkdwnConverter) endList() {
+	c.endItem()
+	c.reset()
+}
+
+// cutMrkdwnListItem cuts the marker of a list item at the start of a line, and returns its indent
+func cutMrkdwnListItem(line []RichTextSectionElement) (int, string, []RichTextSectionElement, bool) {
+	if len(line) == 0 {
+		return 0, "", nil, false
+	}
+	first, ok := line[0].(*RichTextSectionTextElement)
+	if !ok || first.Style != nil {
+		return 0, "", nil, false
+	}
+	m := mrkdwnListItem.FindStringSubmatch(first.Text)
+	if m == nil {
+		return 0, "", nil, false
+	}
+
+	rest := line[1:]
+	if m[3] != "" {
+		rest = append([]RichTextSectionElement{NewRichTextSectionTextElement(m[3], nil)}, rest...)
+	}
+	return len(m[1]), m[2], rest, true
+}
+
+// cutRichTextIndent cuts the spaces at the start of a line, and reports whether there were any
+func cutRichTextIndent(line []RichTextSectionElement) ([]RichTextSectionElement, bool) {
+	if len(line) == 0 {
+		return nil, false
+	}
+	first, ok := line[0].(*RichTextSectionTextElement)
+	if !ok || !strings.HasPrefix(first.Text, " ") {
+		return nil, false
+	}
+	if text := strings.TrimLeft(first.Text, " "); text != "" {
+		return append([]RichTextSectionElement{NewRichTextSectionTextElement(text, first.Style)}, line[1:]...), true
+	}
+	return line[1:], true
+}
+
+func isBlankRichTextLine(line []RichTextSectionElement) bool {
+	for _, element := range line {
+		if text, ok := element.(*RichTextSectionTextElement); !ok || strings.TrimSpace(text.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitRichTextLines splits the elements at the line breaks of their text
+func splitRichTextLines(elements []RichTextSectionElement) [][]RichTextSectionElement {
+	if len(elements) == 0 {
+		return nil
+	}
+	lines := [][]RichTextSectionElement{nil}
+	for _, element := range elements {
+		text, ok := element.(*RichTextSectionTextElement)
+		if !ok {
+			lines[len(lines)-1] = append(lines[len(lines)-1], element)
+			continue
+		}
+		for i, part := range strings.Split(text.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], NewRichTextSectionTextElement(part, text.Style))
+			}
+		}
+	}
+	return lines
+}
+
+// joinRichTextLines joins the lines with line breaks
+func joinRichTextLines(lines [][]RichTextSectionElement) []RichTextSectionElement {
+	var elements []RichTextSectionElement
+	for i, line := range lines {
+		if i > 0 {
+			appendRichTextText(&elements, "\n", RichTextSectionTextStyle{})
+		}
+		for _, element := range line {
+			text, ok := element.(*RichTextSectionTextElement)
+			if !ok {
+				elements = append(elements, element)
+				continue
+			}
+			var style RichTextSectionTextStyle
+			if text.Style != nil {
+				style = *text.Style
+			}
+			appendRichTextText(&elements, text.Text, style)
+		}
+	}
+	return elements
+}
+
+// appendMrkdwnInline appends the elements of the inline nodes of parsed mrkdwn
+func appendMrkdwnInline(elements *[]RichTextSectionElement, nodes []mrkdwn.Node, style RichTextSectionTextStyle) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *mrkdwn.Text:
+			appendMrkdwnText(elements, n.Text, style)
+		case *mrkdwn.Bold:
+			bold := style
+			bold.Bold = true
+			appendMrkdwnInline(elements, n.Children, bold)
+		case *mrkdwn.Italic:
+			italic := style
+			italic.Italic = true
+			appendMrkdwnInline(elements, n.Children, italic)
+		case *mrkdwn.Strike:
+			strike := style
+			strike.Strike = true
+			appendMrkdwnInline(elements, n.Children, strike)
+		case *mrkdwn.Code:
+			code := style
+			code.Code = true
+			appendRichTextText(elements, n.Text, code)
+		case *mrkdwn.Link:
+			*elements = append(*elements, NewRichTextSectionLinkElement(n.URL, n.Text, textStylePtr(style)))
+		case *mrkdwn.UserMention:
+			*elements = append(*elements, NewRichTextSectionUserElement(n.ID, textStylePtr(style)))
+		case *mrkdwn.ChannelMention:
+			*elements = append(*elements, NewRichTextSectionChannelElement(n.ID, textStylePtr(style)))
+		case *mrkdwn.UsergroupMention:
+			*elements = append(*elements, NewRichTextSectionUserGroupElement(n.ID))
+		case *mrkdwn.SpecialMention:
+			*elements = append(*elements, NewRichTextSectionBroadcastElement(n.Name))
+		case *mrkdwn.Date:
+			var url, fallback *string
+			if n.Link != "" {
+				url = &n.Link
+			}
+			if n.Fallback != "" {
+				fallback = &n.Fallback
+			}
+			*elements = append(*elements, NewRichTextSectionDateElement(n.Timestamp, n.Format, url, fallback))
+		}
+	}
+}
+
+// appendMrkdwnText appends the text, and the emoji of its codes
+func appendMrkdwnText(elements *[]RichTextSectionElement, s string, style RichTextSectionTextStyle) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != ':' {
+			continue
+		}
+		if emoji, n := cutEmoji(s, i, style); emoji != nil {
+			if i > start {
+				appendRichTextText(elements, s[start:i], style)
+			}
+			*elements = append(*elements, emoji)
+			start = i + n
+			i = start - 1
+		}
+	}
+	if start < len(s) {
+		appendRichTextText(elements, s[start:], style)
+	}
+}
+
+// cutEmoji converts the emoji code at s[i:], e.g. ":tada:" or ":thumbsup::skin-tone-2:", unless
+// it follows a word, and returns its length
+func cutEmoji(s string, i int, style RichTextSectionTextStyle) (RichTextSectionElement, int) {
+	if r, _ := utf8.DecodeLastRuneInString(s[:i]); i > 0 && isWordRune(r) {
+		return nil, 0
+	}
+	m := emojiCodePattern.FindStringSubmatch(s[i:])
+	if m == nil {
+		return nil, 0
+	}
+	skinTone, _ := strconv.Atoi(m[2])
+	return NewRichTextSectionEmojiElement(m[1], skinTone, textStylePtr(style)), len(m[0])
+}
+
+// appendRichTextText appends text, to the last element if it is text of the same style
+func appendRichTextText(elements *[]RichTextSectionElement, text string, style RichTextSectionTextStyle) {
+	if n := len(*elements); n > 0 {
+		if last, ok := (*elements)[n-1].(*RichTextSectionTextElement); ok && sameTextStyle(last.Style, &style) {
+			last.Text += text
+			return
+		}
+	}
+	*elements = append(*elements, NewRichTextSectionTextElement(text, textStylePtr(style)))
+}
+
+// textStylePtr returns the style, or nil if it has no style
+func textStylePtr(style RichTextSectionTextStyle) *RichTextSectionTextStyle {
+	if style == (RichTextSectionTextStyle{}) {
+		return nil
+	}
+	return &style
+}
+
+const asciiPunct = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+func isASCIIPunct(b byte) bool {
+	return strings.IndexByte(asciiPunct, b) >= 0
+}
+
+// unescapeMarkdown removes the backslashes escaping punctuation
+func unescapeMarkdown(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n'
+}
@@ -0,0 +1,144 @@
+package slack
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// richTextConvertFixture is a case of the round-trip corpus in testdata/rich_text: a block, and
+// its Markdown and mrkdwn
+type richTextConvertFixture struct {
+	Markdown string        `json:"markdown"`
+	Mrkdwn   string        `json:"mrkdwn"`
+	Block    RichTextBlock `json:"block"`
+}
+
+func TestRichTextConvertRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "rich_text", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			b, err := os.ReadFile(file)
+			require.NoError(t, err)
+			var fixture richTextConvertFixture
+			require.NoError(t, json.Unmarshal(b, &fixture))
+			block := &fixture.Block
+
+			assert.Equal(t, fixture.Markdown, RichTextToMarkdown(block))
+			assert.Equal(t, block, RichTextFromMarkdown(block.BlockID, fixture.Markdown))
+			assert.Equal(t, fixture.Mrkdwn, RichTextToMrkdwn(block))
+			assert.Equal(t, block, RichTextFromMrkdwn(block.BlockID, fixture.Mrkdwn))
+		})
+	}
+}
+
+func TestRichTextFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []RichTextElement
+	}{
+		{"list markers", "* a\n+ b\n2) c", []RichTextElement{
+			NewRichTextList(RTEListBullet, 0,
+				NewRichTextSection(NewRichTextSectionTextElement("a", nil)),
+				NewRichTextSection(NewRichTextSectionTextElement("b", nil)),
+			),
+			&RichTextList{Type: RTEList, Style: RTEListOrdered, Offset: 1, Elements: []RichTextElement{
+				NewRichTextSection(NewRichTextSectionTextElement("c", nil)),
+			}},
+		}},
+		{"underscores", "__bold__ _italic_ snake_case_name", []RichTextElement{NewRichTextSection(
+			NewRichTextSectionTextElement("bold", &RichTextSectionTextStyle{Bold: true}),
+			NewRichTextSectionTextElement(" ", nil),
+			NewRichTextSectionTextElement("italic", &RichTextSectionTextStyle{Italic: true}),
+			NewRichTextSectionTextElement(" snake_case_name", nil),
+		)}},
+		{"nested styles", "*a **b** c*", []RichTextElement{NewRichTextSection(
+			NewRichTextSectionTextElement("a ", &RichTextSectionTextStyle{Italic: true}),
+			NewRichTextSectionTextElement("b", &RichTextSectionTextStyle{Bold: true, Italic: true}),
+			NewRichTextSectionTextElement(" c", &RichTextSectionTextStyle{Italic: true}),
+		)}},
+		{"not markup", "# Title at 10:30:45, 2 * 3 = 6 <b>", []RichTextElement{NewRichTextSection(
+			NewRichTextSectionTextElement("# Title at 10:30:45, 2 * 3 = 6 <b>", nil),
+		)}},
+		{"fenced code with info", "```go\nfmt.Println(\"hi\")\n```", []RichTextElement{
+			&RichTextPreformatted{RichTextSection: RichTextSection{Type: RTEPreformatted, Elements: []RichTextSectionElement{
+				NewRichTextSectionTextElement("fmt.Println(\"hi\")", nil),
+			}}},
+		}},
+		{"unclosed fence", "```\ncode", []RichTextElement{NewRichTextSection(
+			NewRichTextSectionTextElement("```\ncode", nil),
+		)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, NewRichTextBlock("", tt.expected...), RichTextFromMarkdown("", tt.markdown))
+		})
+	}
+}
+
+func TestRichTextFromMrkdwn(t *testing.T) {
+	tests := []struct {
+		name     string
+		mrkdwn   string
+		expected []RichTextElement
+	}{
+		{"escaped quote", "&gt; a &amp; b\nc", []RichTextElement{
+			&RichTextQuote{Type: RTEQuote, Elements: []RichTextSectionElement{NewRichTextSectionTextElement("a & b", nil)}},
+			NewRichTextSection(NewRichTextSectionTextElement("c", nil)),
+		}},
+		{"multiline quote", "intro\n>>> a\nb", []RichTextElement{
+			NewRichTextSection(NewRichTextSectionTextElement("intro", nil)),
+			&RichTextQuote{Type: RTEQuote, Elements: []RichTextSectionElement{NewRichTextSectionTextElement("a\nb", nil)}},
+		}},
+		{"inline preformatted", "```a &lt; b``` after", []RichTextElement{
+			&RichTextPreformatted{RichTextSection: RichTextSection{Type: RTEPreformatted, Elements: []RichTextSectionElement{
+				NewRichTextSectionTextElement("a < b", nil),
+			}}},
+			NewRichTextSection(NewRichTextSectionTextElement("after", nil)),
+		}},
+		{"dashes", "- a\n- b", []RichTextElement{NewRichTextList(RTEListBullet, 0,
+			NewRichTextSection(NewRichTextSectionTextElement("a", nil)),
+			NewRichTextSection(NewRichTextSectionTextElement("b", nil)),
+		)}},
+		{"not styles", "snake_case 2*3*4 **a** *b\nc*", []RichTextElement{NewRichTextSection(
+			NewRichTextSectionTextElement("snake_case 2*3*4 **a** *b\nc*", nil),
+		)}},
+		{"labels", "<@U1|alice> <mailto:a@example.com|mail> <!channel|channel>", []RichTextElement{NewRichTextSection(
+			NewRichTextSectionUserElement("U1", nil),
+			NewRichTextSectionTextElement(" ", nil),
+			NewRichTextSectionLinkElement("mailto:a@example.com", "mail", nil),
+			NewRichTextSectionTextElement(" ", nil),
+			NewRichTextSectionBroadcastElement("channel"),
+		)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, NewRichTextBlock("", tt.expected...), RichTextFromMrkdwn("", tt.mrkdwn))
+		})
+	}
+}
+
+func TestRichTextToMarkdownLossy(t *testing.T) {
+	block := NewRichTextBlock("",
+		NewRichTextSection(
+			&RichTextSectionEmojiElement{Type: RTSEEmoji, Name: "tada", Unicode: "1f389"},
+			NewRichTextSectionTextElement(" in ", nil),
+			NewRichTextSectionTeamElement("T1", nil),
+			NewRichTextSectionTextElement(" ", nil),
+			NewRichTextSectionColorElement("#ff0000"),
+			NewRichTextSectionTextElement(" ", nil),
+			NewRichTextSectionDateElement(1392734382, "{date}", nil, nil),
+		),
+	)
+	assert.Equal(t, ":tada: in T1 \\#ff0000 <!date^1392734382^{date}>", RichTextToMarkdown(block))
+	assert.Equal(t, ":tada: in T1 #ff0000 <!date^1392734382^{date}>", RichTextToMrkdwn(block))
+}
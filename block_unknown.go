@@ -1,10 +1,16 @@
 package slack
 
+import "encoding/json"
+
 // UnknownBlock represents a block type that is not yet known. This block type exists to prevent Slack from introducing
 // new and unknown block types that break this library.
 type UnknownBlock struct {
 	Type    MessageBlockType `json:"type"`
 	BlockID string           `json:"block_id,omitempty"`
+
+	// UnknownFields holds the remaining fields of the block, so that it can be
+	// marshalled again without losing data.
+	UnknownFields map[string]json.RawMessage `json:"-"`
 }
 
 // BlockType returns the type of the block
@@ -16,3 +22,25 @@ func (b UnknownBlock) BlockType() MessageBlockType {
 func (s UnknownBlock) ID() string {
 	return s.BlockID
 }
+
+// UnmarshalJSON implements the json.Unmarshaler interface for UnknownBlock,
+// retaining unrecognized fields in UnknownFields.
+func (b *UnknownBlock) UnmarshalJSON(data []byte) error {
+	type alias UnknownBlock
+	if err := json.Unmarshal(data, (*alias)(b)); err != nil {
+		return err
+	}
+	unknown, err := unmarshalUnknownFields(data, (*alias)(b))
+	if err != nil {
+		return err
+	}
+	b.UnknownFields = unknown
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for UnknownBlock,
+// re-emitting any UnknownFields.
+func (b UnknownBlock) MarshalJSON() ([]byte, error) {
+	type alias UnknownBlock
+	return marshalWithUnknownFields((*alias)(&b), b.UnknownFields)
+}
@@ -0,0 +1,820 @@
+package slack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of Block Kit documented by Slack
+//
+// For more information: https://api.slack.com/reference/block-kit
+const (
+	maxMessageBlocks = 50
+	maxViewBlocks    = 100
+	maxBlockIDLength = 255
+	maxActionIDLen   = 255
+	maxTextLength    = 3000
+	maxFieldLength   = 2000
+	maxFields        = 10
+	maxHeaderLength  = 150
+	maxActions       = 25
+	maxContextItems  = 10
+	maxLabelLength   = 2000
+	maxPlaceholder   = 150
+	maxButtonText    = 75
+	maxValueLength   = 2000
+	maxURLLength     = 3000
+	maxAltTextLength = 2000
+	maxOptionText    = 75
+	maxOptionValue   = 150
+	maxSelectOptions = 100
+	maxChoiceOptions = 10
+	minOverflowItems = 2
+	maxOverflowItems = 5
+	maxMarkdownText  = 12000
+	maxTableRows     = 100
+	maxTableCells    = 20
+	maxViewTitle     = 24
+	maxViewMetadata  = 3000
+	maxCallbackID    = 255
+	maxVideoTitle    = 200
+	maxConfirmTitle  = 100
+	maxConfirmText   = 300
+	maxConfirmButton = 30
+)
+
+// BlockValidationError is a block, element or composition object breaking a limit of Block Kit.
+// Path locates it from the blocks, e.g. "blocks[3].accessory.options[101]".
+type BlockValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e BlockValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+func validationErrorf(path, format string, args ...interface{}) error {
+	return BlockValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// withPath prefixes the path of a validation error with the path of its container
+func withPath(prefix string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if verr, ok := err.(BlockValidationError); ok {
+		verr.Path = joinPath(prefix, verr.Path)
+		return verr
+	}
+	return BlockValidationError{Path: prefix, Message: err.Error()}
+}
+
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "", strings.HasPrefix(path, "["):
+		return prefix + path
+	default:
+		return prefix + "." + path
+	}
+}
+
+func indexPath(name string, i int) string {
+	return name + "[" + strconv.Itoa(i) + "]"
+}
+
+func checkLength(path, s string, max int) error {
+	if utf8.RuneCountInString(s) > max {
+		return validationErrorf(path, "cannot be longer than %d characters", max)
+	}
+	return nil
+}
+
+// textRule constrains a text object at a location
+type textRule struct {
+	required  bool
+	plainText bool
+	max       int
+}
+
+func validateText(path string, text *TextBlockObject, rule textRule) error {
+	if text == nil {
+		if rule.required {
+			return validationErrorf(path, "is required")
+		}
+		return nil
+	}
+	if err := text.Validate(); err != nil {
+		return withPath(path, err)
+	}
+	if rule.plainText && text.Type != PlainTextType {
+		return validationErrorf(path, "type must be plain_text")
+	}
+	if rule.max > 0 {
+		return checkLength(path+".text", text.Text, rule.max)
+	}
+	return nil
+}
+
+// Validate checks the blocks of a message against the limits of Block Kit
+func (b Blocks) Validate() error {
+	return validateBlocks(b.BlockSet, maxMessageBlocks)
+}
+
+func validateBlocks(blocks []Block, max int) error {
+	if len(blocks) > max {
+		return validationErrorf("blocks", "cannot have more than %d blocks", max)
+	}
+
+	blockIDs := map[string]bool{}
+	for i, block := range blocks {
+		path := indexPath("blocks", i)
+		if block == nil {
+			return validationErrorf(path, "is nil")
+		}
+		if id := block.ID(); id != "" {
+			if blockIDs[id] {
+				return validationErrorf(path+".block_id", "%q is not unique", id)
+			}
+			blockIDs[id] = true
+		}
+		if err := validateBlock(block); err != nil {
+			return withPath(path, err)
+		}
+	}
+	return nil
+}
+
+func validateBlock(block Block) error {
+	if err := checkLength("block_id", block.ID(), maxBlockIDLength); err != nil {
+		return err
+	}
+	if v, ok := block.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// validateElements validates the elements of a block, whose action IDs must be unique
+func validateElements(path string, elements []BlockElement, max int) error {
+	if len(elements) > max {
+		return validationErrorf(path, "cannot have more than %d elements", max)
+	}
+
+	actionIDs := map[string]bool{}
+	for i, element := range elements {
+		epath := indexPath(path, i)
+		if id := elementActionID(element); id != "" {
+			if actionIDs[id] {
+				return validationErrorf(epath+".action_id", "%q is not unique in the block", id)
+			}
+			actionIDs[id] = true
+		}
+		if err := validateElement(epath, element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateElement(path string, element BlockElement) error {
+	if element == nil {
+		return validationErrorf(path, "is nil")
+	}
+	if err := checkLength(joinPath(path, "action_id"), elementActionID(element), maxActionIDLen); err != nil {
+		return err
+	}
+	if v, ok := element.(interface{ Validate() error }); ok {
+		return withPath(path, v.Validate())
+	}
+	return nil
+}
+
+func elementActionID(element BlockElement) string {
+	switch e := element.(type) {
+	case *ButtonBlockElement:
+		return e.ActionID
+	case *SelectBlockElement:
+		return e.ActionID
+	case *MultiSelectBlockElement:
+		return e.ActionID
+	case *OverflowBlockElement:
+		return e.ActionID
+	case *DatePickerBlockElement:
+		return e.ActionID
+	case *TimePickerBlockElement:
+		return e.ActionID
+	case *DateTimePickerBlockElement:
+		return e.ActionID
+	case *EmailTextInputBlockElement:
+		return e.ActionID
+	case *URLTextInputBlockElement:
+		return e.ActionID
+	case *PlainTextInputBlockElement:
+		return e.ActionID
+	case *RichTextInputBlockElement:
+		return e.ActionID
+	case *CheckboxGroupsBlockElement:
+		return e.ActionID
+	case *RadioButtonsBlockElement:
+		return e.ActionID
+	case *NumberInputBlockElement:
+		return e.ActionID
+	case *FileInputBlockElement:
+		return e.ActionID
+	case *FeedbackButtonsBlockElement:
+		return e.ActionID
+	case *IconButtonBlockElement:
+		return e.ActionID
+	case *WorkflowButtonBlockElement:
+		return e.ActionID
+	}
+	return ""
+}
+
+// Validate checks the modal against the limits of Block Kit
+func (v ModalViewRequest) Validate() error {
+	if v.Type != VTModal {
+		return validationErrorf("type", "must be %s", VTModal)
+	}
+	if err := validateText("title", v.Title, textRule{required: true, plainText: true, max: maxViewTitle}); err != nil {
+		return err
+	}
+	if err := validateText("close", v.Close, textRule{plainText: true, max: maxViewTitle}); err != nil {
+		return err
+	}
+	if err := validateText("submit", v.Submit, textRule{plainText: true, max: maxViewTitle}); err != nil {
+		return err
+	}
+	if v.Submit == nil {
+		for _, block := range v.Blocks.BlockSet {
+			if block != nil && block.BlockType() == MBTInput {
+				return validationErrorf("submit", "is required with input blocks")
+			}
+		}
+	}
+	if err := validateViewFields(v.PrivateMetadata, v.CallbackID); err != nil {
+		return err
+	}
+	return validateBlocks(v.Blocks.BlockSet, maxViewBlocks)
+}
+
+// Validate checks the home tab against the limits of Block Kit
+func (v HomeTabViewRequest) Validate() error {
+	if v.Type != VTHomeTab {
+		return validationErrorf("type", "must be %s", VTHomeTab)
+	}
+	if err := validateViewFields(v.PrivateMetadata, v.CallbackID); err != nil {
+		return err
+	}
+	return validateBlocks(v.Blocks.BlockSet, maxViewBlocks)
+}
+
+func validateViewFields(privateMetadata, callbackID string) error {
+	if err := checkLength("private_metadata", privateMetadata, maxViewMetadata); err != nil {
+		return err
+	}
+	return checkLength("callback_id", callbackID, maxCallbackID)
+}
+
+// Validate checks the section against the limits of Block Kit
+func (s SectionBlock) Validate() error {
+	if s.Text == nil && len(s.Fields) == 0 {
+		return validationErrorf("", "text or fields is required")
+	}
+	if err := validateText("text", s.Text, textRule{max: maxTextLength}); err != nil {
+		return err
+	}
+	if len(s.Fields) > maxFields {
+		return validationErrorf("fields", "cannot have more than %d fields", maxFields)
+	}
+	for i, field := range s.Fields {
+		if err := validateText(indexPath("fields", i), field, textRule{required: true, max: maxFieldLength}); err != nil {
+			return err
+		}
+	}
+	if s.Accessory != nil {
+		return withPath("accessory", s.Accessory.Validate())
+	}
+	return nil
+}
+
+// Validate checks the element of the accessory against the limits of Block Kit
+func (a Accessory) Validate() error {
+	var element BlockElement
+	switch {
+	case a.ImageElement != nil:
+		element = a.ImageElement
+	case a.ButtonElement != nil:
+		element = a.ButtonElement
+	case a.OverflowElement != nil:
+		element = a.OverflowElement
+	case a.DatePickerElement != nil:
+		element = a.DatePickerElement
+	case a.TimePickerElement != nil:
+		element = a.TimePickerElement
+	case a.PlainTextInputElement != nil:
+		element = a.PlainTextInputElement
+	case a.RichTextInputElement != nil:
+		element = a.RichTextInputElement
+	case a.RadioButtonsElement != nil:
+		element = a.RadioButtonsElement
+	case a.SelectElement != nil:
+		element = a.SelectElement
+	case a.MultiSelectElement != nil:
+		element = a.MultiSelectElement
+	case a.CheckboxGroupsBlockElement != nil:
+		element = a.CheckboxGroupsBlockElement
+	case a.WorkflowButtonElement != nil:
+		element = a.WorkflowButtonElement
+	default:
+		return nil
+	}
+	return validateElement("", element)
+}
+
+// Validate checks the actions block against the limits of Block Kit
+func (s ActionBlock) Validate() error {
+	if s.Elements == nil || len(s.Elements.ElementSet) == 0 {
+		return validationErrorf("elements", "is required")
+	}
+	return validateElements("elements", s.Elements.ElementSet, maxActions)
+}
+
+// Validate checks the context actions block against the limits of Block Kit
+func (s ContextActionsBlock) Validate() error {
+	if s.Elements == nil || len(s.Elements.ElementSet) == 0 {
+		return validationErrorf("elements", "is required")
+	}
+	return validateElements("elements", s.Elements.ElementSet, maxActions)
+}
+
+// Validate checks the context block against the limits of Block Kit
+func (s ContextBlock) Validate() error {
+	elements := s.ContextElements.Elements
+	if len(elements) == 0 {
+		return validationErrorf("elements", "is required")
+	}
+	if len(elements) > maxContextItems {
+		return validationErrorf("elements", "cannot have more than %d elements", maxContextItems)
+	}
+	for i, element := range elements {
+		path := indexPath("elements", i)
+		switch e := element.(type) {
+		case *TextBlockObject:
+			if err := validateText(path, e, textRule{required: true, max: maxTextLength}); err != nil {
+				return err
+			}
+		case *ImageBlockElement:
+			if err := withPath(path, e.Validate()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the header against the limits of Block Kit
+func (s HeaderBlock) Validate() error {
+	return validateText("text", s.Text, textRule{required: true, plainText: true, max: maxHeaderLength})
+}
+
+// Validate checks the image block against the limits of Block Kit
+func (s ImageBlock) Validate() error {
+	if err := validateImage(s.ImageURL, s.SlackFile, s.AltText); err != nil {
+		return err
+	}
+	return validateText("title", s.Title, textRule{plainText: true, max: maxAltTextLength})
+}
+
+func validateImage(imageURL string, slackFile *SlackFileObject, altText string) error {
+	if imageURL == "" && slackFile == nil {
+		return validationErrorf("image_url", "image_url or slack_file is required")
+	}
+	if err := checkLength("image_url", imageURL, maxURLLength); err != nil {
+		return err
+	}
+	if altText == "" {
+		return validationErrorf("alt_text", "is required")
+	}
+	return checkLength("alt_text", altText, maxAltTextLength)
+}
+
+// Validate checks the input block against the limits of Block Kit
+func (s InputBlock) Validate() error {
+	if err := validateText("label", s.Label, textRule{required: true, plainText: true, max: maxLabelLength}); err != nil {
+		return err
+	}
+	if err := validateText("hint", s.Hint, textRule{plainText: true, max: maxLabelLength}); err != nil {
+		return err
+	}
+	return validateElement("element", s.Element)
+}
+
+// Validate checks the file block against the limits of Block Kit
+func (s FileBlock) Validate() error {
+	if s.ExternalID == "" {
+		return validationErrorf("external_id", "is required")
+	}
+	if s.Source != "remote" {
+		return validationErrorf("source", "must be remote")
+	}
+	return nil
+}
+
+// Validate checks the video block against the limits of Block Kit
+func (s VideoBlock) Validate() error {
+	if s.VideoURL == "" {
+		return validationErrorf("video_url", "is required")
+	}
+	if s.ThumbnailURL == "" {
+		return validationErrorf("thumbnail_url", "is required")
+	}
+	if s.AltText == "" {
+		return validationErrorf("alt_text", "is required")
+	}
+	if err := validateText("title", s.Title, textRule{required: true, plainText: true, max: maxVideoTitle}); err != nil {
+		return err
+	}
+	return validateText("description", s.Description, textRule{plainText: true, max: maxVideoTitle})
+}
+
+// Validate checks the markdown block against the limits of Block Kit
+func (s MarkdownBlock) Validate() error {
+	if s.Text == "" {
+		return validationErrorf("text", "is required")
+	}
+	return checkLength("text", s.Text, maxMarkdownText)
+}
+
+// Validate checks the table against the limits of Block Kit
+func (s TableBlock) Validate() error {
+	if len(s.Rows) == 0 {
+		return validationErrorf("rows", "is required")
+	}
+	if len(s.Rows) > maxTableRows {
+		return validationErrorf("rows", "cannot have more than %d rows", maxTableRows)
+	}
+	for i, row := range s.Rows {
+		if len(row) > maxTableCells {
+			return validationErrorf(indexPath("rows", i), "cannot have more than %d cells", maxTableCells)
+		}
+	}
+	return nil
+}
+
+// Validate checks the button against the limits of Block Kit
+func (s ButtonBlockElement) Validate() error {
+	if err := validateText("text", s.Text, textRule{required: true, plainText: true, max: maxButtonText}); err != nil {
+		return err
+	}
+	if err := checkLength("url", s.URL, maxURLLength); err != nil {
+		return err
+	}
+	if err := checkLength("value", s.Value, maxValueLength); err != nil {
+		return err
+	}
+	if err := validateStyle(s.Style); err != nil {
+		return err
+	}
+	return validateConfirm(s.Confirm)
+}
+
+func validateStyle(style Style) error {
+	if style != StyleDefault && style != StylePrimary && style != StyleDanger {
+		return validationErrorf("style", "must be primary or danger")
+	}
+	return nil
+}
+
+// Validate checks the image element against the limits of Block Kit
+func (s ImageBlockElement) Validate() error {
+	imageURL := ""
+	if s.ImageURL != nil {
+		imageURL = *s.ImageURL
+	}
+	return validateImage(imageURL, s.SlackFile, s.AltText)
+}
+
+// Validate checks the select menu against the limits of Block Kit. The initial option of a
+// static select must be one of its options.
+func (s SelectBlockElement) Validate() error {
+	if err := validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder}); err != nil {
+		return err
+	}
+	if err := validateSelectOptions(s.Type, s.Options, s.OptionGroups); err != nil {
+		return err
+	}
+	if s.InitialOption != nil {
+		if err := validateOption("initial_option", s.InitialOption, true); err != nil {
+			return err
+		}
+		if s.Type == OptTypeStatic && !hasOption(s.Options, s.OptionGroups, s.InitialOption) {
+			return validationErrorf("initial_option", "must be one of the options")
+		}
+	}
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the multi-select menu against the limits of Block Kit. The initial options of
+// a static multi-select must be among its options.
+func (s MultiSelectBlockElement) Validate() error {
+	if err := validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder}); err != nil {
+		return err
+	}
+	if err := validateSelectOptions(s.Type, s.Options, s.OptionGroups); err != nil {
+		return err
+	}
+	for i, option := range s.InitialOptions {
+		path := indexPath("initial_options", i)
+		if err := validateOption(path, option, true); err != nil {
+			return err
+		}
+		if s.Type == MultiOptTypeStatic && !hasOption(s.Options, s.OptionGroups, option) {
+			return validationErrorf(path, "must be one of the options")
+		}
+	}
+	if s.MaxSelectedItems != nil && *s.MaxSelectedItems < 1 {
+		return validationErrorf("max_selected_items", "must be at least 1")
+	}
+	return validateConfirm(s.Confirm)
+}
+
+func validateSelectOptions(selectType string, options []*OptionBlockObject, groups []*OptionGroupBlockObject) error {
+	static := selectType == OptTypeStatic || selectType == MultiOptTypeStatic
+	if static && len(options) == 0 && len(groups) == 0 {
+		return validationErrorf("options", "options or option_groups is required")
+	}
+	if len(options) > 0 && len(groups) > 0 {
+		return validationErrorf("option_groups", "cannot be set with options")
+	}
+	if err := validateOptions("options", options, maxSelectOptions, true); err != nil {
+		return err
+	}
+	if len(groups) > maxSelectOptions {
+		return validationErrorf("option_groups", "cannot have more than %d option groups", maxSelectOptions)
+	}
+	for i, group := range groups {
+		path := indexPath("option_groups", i)
+		if group == nil {
+			return validationErrorf(path, "is nil")
+		}
+		if err := validateText(path+".label", group.Label, textRule{required: true, plainText: true, max: maxOptionText}); err != nil {
+			return err
+		}
+		if err := validateOptions(path+".options", group.Options, maxSelectOptions, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasOption(options []*OptionBlockObject, groups []*OptionGroupBlockObject, option *OptionBlockObject) bool {
+	for _, o := range options {
+		if o != nil && o.Value == option.Value {
+			return true
+		}
+	}
+	for _, group := range groups {
+		if group != nil && hasOption(group.Options, nil, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the overflow menu against the limits of Block Kit
+func (s OverflowBlockElement) Validate() error {
+	if len(s.Options) < minOverflowItems {
+		return validationErrorf("options", "must have at least %d options", minOverflowItems)
+	}
+	if err := validateOptions("options", s.Options, maxOverflowItems, true); err != nil {
+		return err
+	}
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the checkboxes against the limits of Block Kit. The initial options must be
+// among the options.
+func (c CheckboxGroupsBlockElement) Validate() error {
+	if len(c.Options) == 0 {
+		return validationErrorf("options", "is required")
+	}
+	if err := validateOptions("options", c.Options, maxChoiceOptions, false); err != nil {
+		return err
+	}
+	for i, option := range c.InitialOptions {
+		path := indexPath("initial_options", i)
+		if err := validateOption(path, option, false); err != nil {
+			return err
+		}
+		if !hasOption(c.Options, nil, option) {
+			return validationErrorf(path, "must be one of the options")
+		}
+	}
+	return validateConfirm(c.Confirm)
+}
+
+// Validate checks the radio buttons against the limits of Block Kit. The initial option must be
+// one of the options.
+func (s RadioButtonsBlockElement) Validate() error {
+	if len(s.Options) == 0 {
+		return validationErrorf("options", "is required")
+	}
+	if err := validateOptions("options", s.Options, maxChoiceOptions, false); err != nil {
+		return err
+	}
+	if s.InitialOption != nil {
+		if err := validateOption("initial_option", s.InitialOption, false); err != nil {
+			return err
+		}
+		if !hasOption(s.Options, nil, s.InitialOption) {
+			return validationErrorf("initial_option", "must be one of the options")
+		}
+	}
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the date picker against the limits of Block Kit
+func (s DatePickerBlockElement) Validate() error {
+	if err := validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder}); err != nil {
+		return err
+	}
+	if s.InitialDate != "" {
+		if _, err := time.Parse("2006-01-02", s.InitialDate); err != nil {
+			return validationErrorf("initial_date", "must be formatted as YYYY-MM-DD")
+		}
+	}
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the time picker against the limits of Block Kit
+func (s TimePickerBlockElement) Validate() error {
+	if err := validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder}); err != nil {
+		return err
+	}
+	if s.InitialTime != "" {
+		if _, err := time.Parse("15:04", s.InitialTime); err != nil {
+			return validationErrorf("initial_time", "must be formatted as HH:mm")
+		}
+	}
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the date and time picker against the limits of Block Kit
+func (s DateTimePickerBlockElement) Validate() error {
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the plain-text input against the limits of Block Kit
+func (s PlainTextInputBlockElement) Validate() error {
+	if err := validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder}); err != nil {
+		return err
+	}
+	if s.MinLength < 0 || s.MinLength > maxTextLength {
+		return validationErrorf("min_length", "must be between 0 and %d", maxTextLength)
+	}
+	if s.MaxLength != 0 && (s.MaxLength < 1 || s.MaxLength > maxTextLength) {
+		return validationErrorf("max_length", "must be between 1 and %d", maxTextLength)
+	}
+	if s.MaxLength != 0 && s.MaxLength < s.MinLength {
+		return validationErrorf("max_length", "cannot be less than min_length")
+	}
+	return nil
+}
+
+// Validate checks the email input against the limits of Block Kit
+func (s EmailTextInputBlockElement) Validate() error {
+	return validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder})
+}
+
+// Validate checks the URL input against the limits of Block Kit
+func (s URLTextInputBlockElement) Validate() error {
+	return validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder})
+}
+
+// Validate checks the number input against the limits of Block Kit
+func (s NumberInputBlockElement) Validate() error {
+	return validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder})
+}
+
+// Validate checks the rich text input against the limits of Block Kit
+func (s RichTextInputBlockElement) Validate() error {
+	return validateText("placeholder", s.Placeholder, textRule{plainText: true, max: maxPlaceholder})
+}
+
+// Validate checks the file input against the limits of Block Kit
+func (s FileInputBlockElement) Validate() error {
+	// Zero leaves max_files unset, which Slack defaults to 10
+	if s.MaxFiles < 0 || s.MaxFiles > 10 {
+		return validationErrorf("max_files", "must be between 1 and 10, or 0 for the default of 10")
+	}
+	return nil
+}
+
+// Validate checks the feedback buttons against the limits of Block Kit
+func (s FeedbackButtonsBlockElement) Validate() error {
+	buttons := []struct {
+		path   string
+		button *FeedbackButton
+	}{{"positive_button", s.PositiveButton}, {"negative_button", s.NegativeButton}}
+	for _, b := range buttons {
+		if b.button == nil {
+			return validationErrorf(b.path, "is required")
+		}
+		if err := validateText(b.path+".text", b.button.Text, textRule{required: true, plainText: true, max: maxButtonText}); err != nil {
+			return err
+		}
+		if err := checkLength(b.path+".value", b.button.Value, maxValueLength); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the icon button against the limits of Block Kit
+func (s IconButtonBlockElement) Validate() error {
+	if s.Icon == "" {
+		return validationErrorf("icon", "is required")
+	}
+	if err := validateText("text", s.Text, textRule{required: true, plainText: true, max: maxButtonText}); err != nil {
+		return err
+	}
+	if err := checkLength("value", s.Value, maxValueLength); err != nil {
+		return err
+	}
+	return validateConfirm(s.Confirm)
+}
+
+// Validate checks the workflow button against the limits of Block Kit
+func (s WorkflowButtonBlockElement) Validate() error {
+	if err := validateText("text", s.Text, textRule{required: true, plainText: true, max: maxButtonText}); err != nil {
+		return err
+	}
+	if s.Workflow == nil || s.Workflow.Trigger == nil || s.Workflow.Trigger.URL == "" {
+		return validationErrorf("workflow.trigger.url", "is required")
+	}
+	return validateStyle(s.Style)
+}
+
+func validateOptions(path string, options []*OptionBlockObject, max int, plainText bool) error {
+	if len(options) > max {
+		return validationErrorf(path, "cannot have more than %d options", max)
+	}
+	for i, option := range options {
+		if err := validateOption(indexPath(path, i), option, plainText); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateOption validates an option of a select menu or an overflow menu, whose texts must be
+// plain_text, or of checkboxes or radio buttons
+func validateOption(path string, option *OptionBlockObject, plainText bool) error {
+	if option == nil {
+		return validationErrorf(path, "is nil")
+	}
+	if err := validateText(path+".text", option.Text, textRule{required: true, plainText: plainText, max: maxOptionText}); err != nil {
+		return err
+	}
+	if err := validateText(path+".description", option.Description, textRule{plainText: plainText, max: maxOptionText}); err != nil {
+		return err
+	}
+	if err := checkLength(path+".value", option.Value, maxOptionValue); err != nil {
+		return err
+	}
+	return checkLength(path+".url", option.URL, maxURLLength)
+}
+
+// Validate checks the confirmation dialog against the limits of Block Kit
+func (s ConfirmationBlockObject) Validate() error {
+	if err := validateText("title", s.Title, textRule{required: true, plainText: true, max: maxConfirmTitle}); err != nil {
+		return err
+	}
+	if err := validateText("text", s.Text, textRule{required: true, max: maxConfirmText}); err != nil {
+		return err
+	}
+	if err := validateText("confirm", s.Confirm, textRule{required: true, plainText: true, max: maxConfirmButton}); err != nil {
+		return err
+	}
+	if err := validateText("deny", s.Deny, textRule{plainText: true, max: maxConfirmButton}); err != nil {
+		return err
+	}
+	return validateStyle(s.Style)
+}
+
+func validateConfirm(confirm *ConfirmationBlockObject) error {
+	if confirm == nil {
+		return nil
+	}
+	return withPath("confirm", confirm.Validate())
+}
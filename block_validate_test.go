@@ -0,0 +1,117 @@
+package slack
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocksValidate(t *testing.T) {
+	plain := func(s string) *TextBlockObject { return NewTextBlockObject(PlainTextType, s, false, false) }
+	mrkdwn := func(s string) *TextBlockObject { return NewTextBlockObject(MarkdownType, s, false, false) }
+	options := func(n int) []*OptionBlockObject {
+		opts := make([]*OptionBlockObject, n)
+		for i := range opts {
+			opts[i] = NewOptionBlockObject(strconv.Itoa(i), plain("Option "+strconv.Itoa(i)), nil)
+		}
+		return opts
+	}
+	blocks := func(n int) []Block {
+		b := make([]Block, n)
+		for i := range b {
+			b[i] = NewDividerBlock()
+		}
+		return b
+	}
+
+	tests := []struct {
+		name   string
+		blocks []Block
+		path   string
+	}{
+		{"valid", []Block{
+			NewHeaderBlock(plain("Header")),
+			NewSectionBlock(mrkdwn("*Hello*"), []*TextBlockObject{mrkdwn("a"), mrkdwn("b")},
+				NewAccessory(NewOptionsSelectBlockElement(OptTypeStatic, plain("Pick"), "pick", options(3)...))),
+			NewActionBlock("actions", NewButtonBlockElement("ok", "ok", plain("OK")), NewButtonBlockElement("cancel", "cancel", plain("Cancel"))),
+			NewContextBlock("", mrkdwn("context"), NewImageBlockElement("https://example.com/a.png", "a")),
+		}, ""},
+		{"too many blocks", blocks(51), "blocks"},
+		{"block ID not unique", []Block{NewDividerBlock(), NewActionBlock("a", NewButtonBlockElement("x", "", plain("X"))), NewMarkdownBlock("a", "text")}, "blocks[2].block_id"},
+		{"section text", []Block{NewSectionBlock(mrkdwn(strings.Repeat("a", 3001)), nil, nil)}, "blocks[0].text"},
+		{"section fields", []Block{NewSectionBlock(nil, make([]*TextBlockObject, 11), nil)}, "blocks[0].fields"},
+		{"header length", []Block{NewHeaderBlock(plain(strings.Repeat("a", 151)))}, "blocks[0].text.text"},
+		{"header mrkdwn", []Block{NewHeaderBlock(mrkdwn("*Header*"))}, "blocks[0].text"},
+		{"action ID length", []Block{NewActionBlock("", NewButtonBlockElement(strings.Repeat("a", 256), "", plain("X")))}, "blocks[0].elements[0].action_id"},
+		{"action ID not unique", []Block{NewActionBlock("", NewButtonBlockElement("a", "", plain("X")), NewButtonBlockElement("a", "", plain("Y")))}, "blocks[0].elements[1].action_id"},
+		{"select options", []Block{NewDividerBlock(), NewDividerBlock(), NewDividerBlock(),
+			NewSectionBlock(mrkdwn("Pick"), nil, NewAccessory(NewOptionsSelectBlockElement(OptTypeStatic, plain("Pick"), "pick", options(102)...)))}, "blocks[3].accessory.options"},
+		{"select option plain_text", []Block{NewSectionBlock(mrkdwn("Pick"), nil, NewAccessory(NewOptionsSelectBlockElement(OptTypeStatic, plain("Pick"), "pick",
+			NewOptionBlockObject("a", plain("A"), nil), NewOptionBlockObject("b", mrkdwn("*B*"), nil))))}, "blocks[0].accessory.options[1].text"},
+		{"initial option", []Block{NewSectionBlock(mrkdwn("Pick"), nil, NewAccessory(func() BlockElement {
+			e := NewRadioButtonsBlockElement("radio", options(2)...)
+			e.InitialOption = NewOptionBlockObject("other", plain("Other"), nil)
+			return e
+		}()))}, "blocks[0].accessory.initial_option"},
+		{"checkboxes", []Block{NewActionBlock("", NewCheckboxGroupsBlockElement("check", options(11)...))}, "blocks[0].elements[0].options"},
+		{"overflow", []Block{NewActionBlock("", NewOverflowBlockElement("more", options(1)...))}, "blocks[0].elements[0].options"},
+		{"confirm", []Block{NewActionBlock("", func() BlockElement {
+			b := NewButtonBlockElement("delete", "", plain("Delete"))
+			b.Confirm = NewConfirmationBlockObject(plain("Sure?"), mrkdwn("Really?"), mrkdwn("*Yes*"), nil)
+			return b
+		}())}, "blocks[0].elements[0].confirm.confirm"},
+		{"file input max files unset", []Block{NewInputBlock("", plain("Files"), nil, NewFileInputBlockElement("files"))}, ""},
+		{"file input max files", []Block{NewInputBlock("", plain("Files"), nil, NewFileInputBlockElement("files").WithMaxFiles(11))}, "blocks[0].element.max_files"},
+		{"input label", []Block{NewInputBlock("", mrkdwn("Label"), nil, NewPlainTextInputBlockElement(nil, "in"))}, "blocks[0].label"},
+		{"context elements", []Block{NewContextBlock("", func() []MixedElement {
+			e := make([]MixedElement, 11)
+			for i := range e {
+				e[i] = mrkdwn("a")
+			}
+			return e
+		}()...)}, "blocks[0].elements"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Blocks{BlockSet: tt.blocks}.Validate()
+			if tt.path == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var verr BlockValidationError
+			require.True(t, errors.As(err, &verr), "got %v", err)
+			assert.Equal(t, tt.path, verr.Path, verr.Error())
+		})
+	}
+}
+
+func TestViewValidate(t *testing.T) {
+	plain := func(s string) *TextBlockObject { return NewTextBlockObject(PlainTextType, s, false, false) }
+	input := NewInputBlock("name", plain("Name"), nil, NewPlainTextInputBlockElement(nil, "name"))
+
+	modal := ModalViewRequest{Type: VTModal, Title: plain("Title"), Submit: plain("Save"), Blocks: Blocks{BlockSet: []Block{input}}}
+	assert.NoError(t, modal.Validate())
+
+	modal.Submit = nil
+	assert.EqualError(t, modal.Validate(), "submit: is required with input blocks")
+
+	modal.Submit = plain("Save")
+	modal.Title = plain(strings.Repeat("a", 25))
+	assert.EqualError(t, modal.Validate(), "title.text: cannot be longer than 24 characters")
+
+	modal.Title = plain("Title")
+	modal.Blocks.BlockSet = []Block{input, input}
+	assert.EqualError(t, modal.Validate(), `blocks[1].block_id: "name" is not unique`)
+
+	home := HomeTabViewRequest{Type: VTHomeTab}
+	for i := 0; i < 100; i++ {
+		home.Blocks.BlockSet = append(home.Blocks.BlockSet, NewDividerBlock())
+	}
+	assert.NoError(t, home.Validate())
+	home.Blocks.BlockSet = append(home.Blocks.BlockSet, NewDividerBlock())
+	assert.EqualError(t, home.Validate(), "blocks: cannot have more than 100 blocks")
+}
@@ -2,6 +2,7 @@ package slack
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 )
 
@@ -24,6 +25,32 @@ type Channel struct {
 	IsMember   bool        `json:"is_member"`
 	Locale     string      `json:"locale"`
 	Properties *Properties `json:"properties"`
+
+	// UnknownFields holds any fields of the channel that this library does not
+	// model. They are re-emitted when the channel is marshalled.
+	UnknownFields map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Channel,
+// retaining unrecognized fields in UnknownFields.
+func (c *Channel) UnmarshalJSON(data []byte) error {
+	type alias Channel
+	if err := json.Unmarshal(data, (*alias)(c)); err != nil {
+		return err
+	}
+	unknown, err := unmarshalUnknownFields(data, (*alias)(c))
+	if err != nil {
+		return err
+	}
+	c.UnknownFields = unknown
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for Channel,
+// re-emitting any UnknownFields.
+func (c Channel) MarshalJSON() ([]byte, error) {
+	type alias Channel
+	return marshalWithUnknownFields((*alias)(&c), c.UnknownFields)
 }
 
 func (api *Client) channelRequest(ctx context.Context, path string, values url.Values) (*channelResponseFull, error) {
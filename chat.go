@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/incident-io/slack/slackutilsx"
 )
@@ -306,9 +307,29 @@ func applyMsgOptions(token, channel, apiurl string, options ...MsgOption) (sendC
 		}
 	}
 
+	// Slack recommends a text with the blocks, used in notifications and by screen readers
+	if len(config.blocks.BlockSet) > 0 && config.values.Get("text") == "" && config.notifies() {
+		if text := NewTextRenderer(TextFormatMrkdwn).RenderBlocks(config.blocks.BlockSet...); text != "" {
+			config.values.Set("text", SplitText(text, maxMessageText)[0])
+		}
+	}
+
 	return config, nil
 }
 
+// notifies reports whether the message is sent with a method notifying users with its text, and
+// needs a fallback text for its blocks. Unfurls and responses to response URLs don't.
+func (config sendConfig) notifies() bool {
+	if config.mode == chatResponse {
+		return false
+	}
+	switch sendMode(strings.TrimPrefix(config.endpoint, config.apiurl)) {
+	case chatPostMessage, chatPostEphemeral, chatScheduleMessage, chatUpdate:
+		return true
+	}
+	return false
+}
+
 func buildSender(apiurl string, options ...MsgOption) sendConfig {
 	return sendConfig{
 		apiurl:  apiurl,
@@ -457,7 +478,8 @@ func MsgOptionMeMessage() MsgOption {
 	}
 }
 
-// MsgOptionUpdate updates a message based on the timestamp.
+// MsgOptionUpdate updates a message based on the timestamp. As when posting a message, unless a
+// text is set, the blocks rendered to mrkdwn are set as the text of the updated message.
 func MsgOptionUpdate(timestamp string) MsgOption {
 	return func(config *sendConfig) error {
 		config.endpoint = config.apiurl + string(chatUpdate)
@@ -673,7 +695,10 @@ func MsgOptionAttachments(attachments ...Attachment) MsgOption {
 	}
 }
 
-// MsgOptionBlocks sets blocks for the message
+// MsgOptionBlocks sets blocks for the message. Unless a text is set, the blocks rendered to
+// mrkdwn are set as the text, used in notifications, when the message is posted, posted as an
+// ephemeral message, scheduled or updated. Unfurls and responses to response URLs are sent
+// without a text.
 func MsgOptionBlocks(blocks ...Block) MsgOption {
 	return func(config *sendConfig) error {
 		if blocks == nil {
@@ -0,0 +1,357 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageText is the length of the text of a message above which Slack truncates it
+const maxMessageText = 4000
+
+// SplitText splits text into parts of at most max characters, e.g. to post it in several
+// messages. It is split between paragraphs, else between lines, e.g. the items of a list, else
+// between words. Code blocks are kept intact, or else closed at the end of a part and opened again
+// at the start of the next one. Empty text has no parts.
+func SplitText(text string, max int) []string {
+	if text == "" {
+		return nil
+	}
+	if max <= 0 || utf8.RuneCountInString(text) <= max {
+		return []string{text}
+	}
+
+	s := &textSplitter{max: max}
+	for _, unit := range textUnits(text) {
+		if unit.fence {
+			s.addFence(unit.sep, strings.Split(unit.text, "\n"))
+		} else {
+			s.add(unit.sep, unit.text)
+		}
+	}
+	return s.done()
+}
+
+// textUnit is a paragraph or a code block of a text
+type textUnit struct {
+	sep   string // the line breaks before the unit
+	text  string
+	fence bool
+}
+
+// textUnits cuts text into its paragraphs and code blocks
+func textUnits(text string) []textUnit {
+	var units []textUnit
+	breaks := 0
+	add := func(text string, fence bool) {
+		units = append(units, textUnit{sep: strings.Repeat("\n", breaks), text: text, fence: fence})
+		breaks = 1
+	}
+
+	var paragraph []string
+	endParagraph := func() {
+		if len(paragraph) > 0 {
+			add(strings.Join(paragraph, "\n"), false)
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			endParagraph()
+			breaks++
+			continue
+		}
+		if strings.HasPrefix(line, "```") && strings.Count(line, "```") == 1 {
+			if end := fenceEnd(lines, i); end > 0 {
+				endParagraph()
+				add(strings.Join(lines[i:end+1], "\n"), true)
+				i = end
+				continue
+			}
+		}
+		paragraph = append(paragraph, line)
+	}
+	endParagraph()
+	return units
+}
+
+// fenceEnd returns the index of the line closing the code block opened at lines[start], or -1
+func fenceEnd(lines []string, start int) int {
+	for i := start + 1; i < len(lines); i++ {
+		if strings.Contains(lines[i], "```") {
+			return i
+		}
+	}
+	return -1
+}
+
+// textSplitter packs pieces of text into parts of at most max characters
+type textSplitter struct {
+	max   int
+	parts []string
+	sb    strings.Builder
+	size  int  // of the current part, in characters
+	open  bool // whether a part is started, even with an empty line
+}
+
+// add appends text, preceded by its separator unless it starts a part. Text longer than a part
+// is split between lines, else words, else characters.
+func (s *textSplitter) add(sep, text string) {
+	size := utf8.RuneCountInString(text)
+	switch {
+	case s.open && s.size+utf8.RuneCountInString(sep)+size <= s.max:
+		s.sb.WriteString(sep)
+		s.sb.WriteString(text)
+		s.size += utf8.RuneCountInString(sep) + size
+	case size <= s.max:
+		s.flush()
+		s.sb.WriteString(text)
+		s.size = size
+		s.open = true
+	case strings.Contains(text, "\n"):
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				sep = "\n"
+			}
+			s.add(sep, line)
+		}
+	case strings.Contains(text, " "):
+		for i, word := range strings.Split(text, " ") {
+			if i > 0 {
+				sep = " "
+			}
+			s.add(sep, word)
+		}
+	default:
+		runes := []rune(text)
+		for len(runes) > 0 {
+			n := min(s.max, len(runes))
+			s.add(sep, string(runes[:n]))
+			sep, runes = "", runes[n:]
+		}
+	}
+}
+
+// addFence appends the lines of a code block, split into several code blocks if it is longer
+// than a part
+func (s *textSplitter) addFence(sep string, lines []string) {
+	text := strings.Join(lines, "\n")
+	open, last := lines[0], lines[len(lines)-1]
+	overhead := utf8.RuneCountInString(open) + len("\n\n```")
+	if utf8.RuneCountInString(text) <= s.max || s.max <= overhead {
+		s.add(sep, text)
+		return
+	}
+
+	inner := lines[1 : len(lines)-1 : len(lines)-1]
+	if rest := strings.TrimSuffix(last, "```"); rest != "" {
+		inner = append(inner, rest)
+	}
+	code := &textSplitter{max: s.max - overhead}
+	codeSep := ""
+	for _, line := range inner {
+		code.add(codeSep, line)
+		codeSep = "\n"
+	}
+	for i, chunk := range code.done() {
+		if i > 0 {
+			sep = "\n"
+		}
+		s.add(sep, open+"\n"+chunk+"\n```")
+	}
+}
+
+func (s *textSplitter) flush() {
+	if s.sb.Len() > 0 {
+		s.parts = append(s.parts, s.sb.String())
+	}
+	s.sb.Reset()
+	s.size = 0
+	s.open = false
+}
+
+func (s *textSplitter) done() []string {
+	s.flush()
+	return s.parts
+}
+
+// MessageSplitter splits long content into messages within the limits of Slack: a head message,
+// and its continuations, to post in its thread, e.g. with PostMessageParts.
+type MessageSplitter struct {
+	tableHeaderRows int
+}
+
+// MessageSplitterOption configures a MessageSplitter.
+type MessageSplitterOption func(*MessageSplitter)
+
+// MessageSplitterOptionTableHeader repeats the first rows of the tables split across messages at
+// the top of every part.
+func MessageSplitterOptionTableHeader(rows int) MessageSplitterOption {
+	return func(s *MessageSplitter) {
+		s.tableHeaderRows = rows
+	}
+}
+
+// NewMessageSplitter returns a MessageSplitter
+func NewMessageSplitter(options ...MessageSplitterOption) *MessageSplitter {
+	s := &MessageSplitter{}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// SplitText splits the text of a message into the options of messages of at most 4,000
+// characters. Empty text has no messages.
+func (s *MessageSplitter) SplitText(text string) [][]MsgOption {
+	parts := SplitText(text, maxMessageText)
+	messages := make([][]MsgOption, len(parts))
+	for i, part := range parts {
+		messages[i] = []MsgOption{MsgOptionText(part, false)}
+	}
+	return messages
+}
+
+// SplitBlocks splits the blocks of a message into the options of messages within the limits of
+// Slack. See SplitBlockSets.
+func (s *MessageSplitter) SplitBlocks(blocks ...Block) [][]MsgOption {
+	sets := s.SplitBlockSets(blocks...)
+	messages := make([][]MsgOption, len(sets))
+	for i, set := range sets {
+		messages[i] = []MsgOption{MsgOptionBlocks(set...)}
+	}
+	return messages
+}
+
+// SplitBlockSets splits the blocks of a message into the blocks of messages with at most 50
+// blocks, one table and 12,000 characters of markdown blocks each. The texts of sections longer
+// than 3,000 characters are split into several sections, the markdown blocks longer than 12,000
+// characters into several markdown blocks, and the tables of more than 100 rows into several
+// tables. A header is kept in the message of the block following it.
+func (s *MessageSplitter) SplitBlockSets(blocks ...Block) [][]Block {
+	var sets [][]Block
+	var current []Block
+	markdown, table := 0, false
+	for _, block := range blocks {
+		for _, part := range s.splitBlock(block) {
+			size := 0
+			if b, ok := part.(*MarkdownBlock); ok {
+				size = utf8.RuneCountInString(b.Text)
+			}
+			_, isTable := part.(*TableBlock)
+
+			if len(current) > 0 && (len(current) == maxMessageBlocks || markdown+size > maxMarkdownText || table && isTable) {
+				var next []Block
+				if n := len(current); n > 1 {
+					if header, ok := current[n-1].(*HeaderBlock); ok {
+						current, next = current[:n-1], []Block{header}
+					}
+				}
+				sets = append(sets, current)
+				current, markdown, table = next, 0, false
+			}
+			current = append(current, part)
+			markdown += size
+			table = table || isTable
+		}
+	}
+	if len(current) > 0 {
+		sets = append(sets, current)
+	}
+	return sets
+}
+
+// splitBlock splits a block longer than the limits of Slack into several blocks
+func (s *MessageSplitter) splitBlock(block Block) []Block {
+	switch b := block.(type) {
+	case *SectionBlock:
+		if b.Text == nil || utf8.RuneCountInString(b.Text.Text) <= maxTextLength {
+			return []Block{b}
+		}
+		parts := SplitText(b.Text.Text, maxTextLength)
+		blocks := make([]Block, len(parts))
+		for i, part := range parts {
+			text := *b.Text
+			text.Text = part
+			if i == 0 {
+				// The first section keeps the fields and accessory
+				section := *b
+				section.Text = &text
+				blocks[i] = &section
+				continue
+			}
+			blocks[i] = NewSectionBlock(&text, nil, nil)
+		}
+		return blocks
+	case *MarkdownBlock:
+		if utf8.RuneCountInString(b.Text) <= maxMarkdownText {
+			return []Block{b}
+		}
+		parts := SplitText(b.Text, maxMarkdownText)
+		blocks := make([]Block, len(parts))
+		for i, part := range parts {
+			blockID := ""
+			if i == 0 {
+				blockID = b.BlockID
+			}
+			blocks[i] = NewMarkdownBlock(blockID, part)
+		}
+		return blocks
+	case *TableBlock:
+		header := min(s.tableHeaderRows, len(b.Rows))
+		perTable := maxTableRows - header
+		if len(b.Rows) <= maxTableRows || perTable <= 0 {
+			return []Block{b}
+		}
+		var blocks []Block
+		for start := header; start < len(b.Rows); start += perTable {
+			table := NewTableBlock("")
+			if start == header {
+				table.BlockID = b.BlockID
+			}
+			table.ColumnSettings = b.ColumnSettings
+			table.Rows = append(append(table.Rows, b.Rows[:header]...), b.Rows[start:min(start+perTable, len(b.Rows))]...)
+			blocks = append(blocks, table)
+		}
+		return blocks
+	}
+	return []Block{block}
+}
+
+// PostMessageParts posts the parts of a message, e.g. split by a MessageSplitter: the first part
+// as a message, and the others in its thread. The options are applied to every part. It returns
+// the timestamps of the messages posted, until an error.
+// For more details, see PostMessagePartsContext documentation.
+func (api *Client) PostMessageParts(channelID string, parts [][]MsgOption, options ...MsgOption) ([]string, error) {
+	return api.PostMessagePartsContext(context.Background(), channelID, parts, options...)
+}
+
+// PostMessagePartsContext posts the parts of a message with a custom context. When the options
+// post the first part in a thread, with MsgOptionTS, the others are posted in the same thread.
+func (api *Client) PostMessagePartsContext(ctx context.Context, channelID string, parts [][]MsgOption, options ...MsgOption) ([]string, error) {
+	config, err := applyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return nil, err
+	}
+	threadTS := config.values.Get("thread_ts")
+
+	timestamps := make([]string, 0, len(parts))
+	for i, part := range parts {
+		partOptions := append(append([]MsgOption{}, options...), part...)
+		if i > 0 {
+			partOptions = append(partOptions, MsgOptionTS(threadTS))
+		}
+		_, timestamp, err := api.PostMessageContext(ctx, channelID, partOptions...)
+		if err != nil {
+			return timestamps, err
+		}
+		timestamps = append(timestamps, timestamp)
+		if threadTS == "" {
+			threadTS = timestamp
+		}
+	}
+	return timestamps, nil
+}
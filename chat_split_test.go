@@ -0,0 +1,143 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		max      int
+		expected []string
+	}{
+		{"fits", "short", 10, []string{"short"}},
+		{"paragraphs", "aaaa\n\nbbbb\n\ncccc", 10, []string{"aaaa\n\nbbbb", "cccc"}},
+		{"list items", "- one\n- two\n- three", 12, []string{"- one\n- two", "- three"}},
+		{"words", "one two three four", 9, []string{"one two", "three", "four"}},
+		{"characters", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"multibyte", "ééé ééé", 3, []string{"ééé", "ééé"}},
+		{"code kept intact", "intro\n\n```\nx\ny\n```\n\noutro", 16, []string{"intro", "```\nx\ny\n```", "outro"}},
+		{"code reopened", "```go\nline1\nline2\nline3\n```", 26, []string{"```go\nline1\nline2\n```", "```go\nline3\n```"}},
+		{"blank line at the start of a part", "```\n\nline1\nline2\n```", 19, []string{"```\n\nline1\n```", "```\nline2\n```"}},
+		{"empty", "", 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SplitText(tt.text, tt.max))
+		})
+	}
+}
+
+func TestMessageSplitterLongSection(t *testing.T) {
+	paragraph := strings.Repeat("a", 2000)
+	section := NewSectionBlock(NewTextBlockObject(MarkdownType, paragraph+"\n\n"+paragraph, false, false), nil, nil, SectionBlockOptionBlockID("summary"))
+
+	sets := NewMessageSplitter().SplitBlockSets(section)
+	require.Len(t, sets, 1)
+	require.Len(t, sets[0], 2)
+	first, second := sets[0][0].(*SectionBlock), sets[0][1].(*SectionBlock)
+	assert.Equal(t, "summary", first.BlockID)
+	assert.Equal(t, paragraph, first.Text.Text)
+	assert.Equal(t, "", second.BlockID)
+	assert.Equal(t, MarkdownType, second.Text.Type)
+	assert.Equal(t, paragraph, second.Text.Text)
+}
+
+func TestMessageSplitterBlockCount(t *testing.T) {
+	var blocks []Block
+	for i := 0; i < 49; i++ {
+		blocks = append(blocks, NewDividerBlock())
+	}
+	header := NewHeaderBlock(NewTextBlockObject(PlainTextType, "Timeline", false, false))
+	blocks = append(blocks, header)
+	for i := 0; i < 10; i++ {
+		blocks = append(blocks, NewDividerBlock())
+	}
+
+	sets := NewMessageSplitter().SplitBlockSets(blocks...)
+	require.Len(t, sets, 2)
+	assert.Len(t, sets[0], 49)
+	assert.Len(t, sets[1], 11)
+	assert.Equal(t, header, sets[1][0])
+}
+
+func TestMessageSplitterMarkdown(t *testing.T) {
+	paragraph := strings.Repeat("b", 6500)
+	sets := NewMessageSplitter().SplitBlockSets(NewMarkdownBlock("md", paragraph+"\n\n"+paragraph))
+	assert.Equal(t, [][]Block{
+		{NewMarkdownBlock("md", paragraph)},
+		{NewMarkdownBlock("", paragraph)},
+	}, sets)
+}
+
+func TestMessageSplitterTable(t *testing.T) {
+	cell := func(s string) *RichTextBlock {
+		return NewRichTextBlock("", NewRichTextSection(NewRichTextSectionTextElement(s, nil)))
+	}
+	table := NewTableBlock("incidents").WithColumnSettings(ColumnSetting{Align: ColumnAlignmentRight})
+	table.AddRow(cell("ID"))
+	for i := 1; i < 250; i++ {
+		table.AddRow(cell(fmt.Sprint(i)))
+	}
+
+	sets := NewMessageSplitter(MessageSplitterOptionTableHeader(1)).SplitBlockSets(table)
+	require.Len(t, sets, 3)
+	for i, expected := range []struct {
+		blockID string
+		rows    int
+		first   string
+	}{{"incidents", 100, "1"}, {"", 100, "100"}, {"", 52, "199"}} {
+		require.Len(t, sets[i], 1)
+		part := sets[i][0].(*TableBlock)
+		assert.Equal(t, expected.blockID, part.BlockID)
+		assert.Equal(t, table.ColumnSettings, part.ColumnSettings)
+		require.Len(t, part.Rows, expected.rows)
+		assert.Equal(t, table.Rows[0], part.Rows[0])
+		assert.Equal(t, cell(expected.first), part.Rows[1][0])
+	}
+}
+
+func TestPostMessageParts(t *testing.T) {
+	var threads []string
+	http.DefaultServeMux = new(http.ServeMux)
+	http.HandleFunc("/chat.postMessage", func(rw http.ResponseWriter, r *http.Request) {
+		threads = append(threads, r.FormValue("thread_ts"))
+		rw.Header().Set("Content-Type", "application/json")
+		response, _ := json.Marshal(chatResponseFull{
+			SlackResponse: SlackResponse{Ok: true},
+			Channel:       "C1",
+			Timestamp:     fmt.Sprintf("100.%d", len(threads)),
+		})
+		rw.Write(response)
+	})
+	once.Do(startServer)
+	api := New("testing-token", OptionAPIURL("http://"+serverAddr+"/"))
+
+	parts := NewMessageSplitter().SplitText(strings.Repeat("c", 3000) + "\n\n" + strings.Repeat("d", 3000))
+	timestamps, err := api.PostMessageParts("C1", parts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"100.1", "100.2"}, timestamps)
+	assert.Equal(t, []string{"", "100.1"}, threads)
+
+	threads = nil
+	_, err = api.PostMessageParts("C1", parts, MsgOptionTS("99.1"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"99.1", "99.1"}, threads)
+
+	// Slack rejects messages without text, so empty text is not posted
+	threads = nil
+	parts = NewMessageSplitter().SplitText("")
+	assert.Empty(t, parts)
+	timestamps, err = api.PostMessageParts("C1", parts)
+	require.NoError(t, err)
+	assert.Empty(t, timestamps)
+	assert.Empty(t, threads)
+}
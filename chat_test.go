@@ -292,6 +292,33 @@ func TestPostMessage(t *testing.T) {
 	}
 }
 
+func TestMsgOptionBlocksFallbackText(t *testing.T) {
+	blocks := MsgOptionBlocks(NewSectionBlock(NewTextBlockObject(MarkdownType, "*Hi* <@U1>", false, false), nil, nil))
+
+	tests := map[string]struct {
+		opt      []MsgOption
+		expected string
+	}{
+		"Post":          {[]MsgOption{blocks}, "*Hi* <@U1>"},
+		"TextSet":       {[]MsgOption{blocks, MsgOptionText("Hi", false)}, "Hi"},
+		"PostEphemeral": {[]MsgOption{blocks, MsgOptionPostEphemeral("U1")}, "*Hi* <@U1>"},
+		"Schedule":      {[]MsgOption{blocks, MsgOptionSchedule("1700000000")}, "*Hi* <@U1>"},
+		"Update":        {[]MsgOption{blocks, MsgOptionUpdate("1700000000.000100")}, "*Hi* <@U1>"},
+		"Unfurl":        {[]MsgOption{blocks, MsgOptionUnfurl("1700000000.000100", map[string]Attachment{})}, ""},
+		"ResponseURL":   {[]MsgOption{blocks, MsgOptionResponseURL("https://hooks.slack.com/actions/1", ResponseTypeInChannel)}, ""},
+	}
+
+	for name, test := range tests {
+		_, values, err := UnsafeApplyMsgOptions("token", "C1", "https://slack.com/api/", test.opt...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if text := values.Get("text"); text != test.expected {
+			t.Errorf("%s: expected text %q, got %q", name, test.expected, text)
+		}
+	}
+}
+
 func TestPostMessageWithBlocksWhenMsgOptionResponseURLApplied(t *testing.T) {
 	expectedBlocks := []Block{NewContextBlock("context", NewTextBlockObject(PlainTextType, "hello", false, false))}
 
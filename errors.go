@@ -13,6 +13,8 @@ const (
 	ErrInvalidConfiguration = errorsx.String("invalid configuration")
 	ErrMissingHeaders       = errorsx.String("missing headers")
 	ErrExpiredTimestamp     = errorsx.String("timestamp is too old")
+	ErrInvalidSignature     = errorsx.String("invalid signature")
+	ErrReplayedRequest      = errorsx.String("request already received")
 )
 
 // internal errors
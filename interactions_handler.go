@@ -0,0 +1,262 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// InteractionHandlerFunc handles an interaction received on the interactivity
+// request URL. A non-nil response is JSON encoded as the body of the HTTP
+// response, e.g. a *ViewSubmissionResponse for view_submission or an
+// *OptionsResponse for block_suggestion. A nil response acknowledges the
+// interaction with an empty body.
+type InteractionHandlerFunc func(ctx context.Context, callback *InteractionCallback) (interface{}, error)
+
+// InteractionHandler is an http.Handler for the interactivity request URL of a
+// Slack app. It verifies the request signature, parses the form-encoded
+// payload into an InteractionCallback and routes it to the most specific
+// registered handler:
+//
+//   - block_actions and block_suggestion: by action_id, then by block_id
+//   - view_submission, view_closed, shortcut and message_action: by callback_id
+//   - any interaction on a view: by the external_id of the view
+//   - any interaction: by interaction type
+//
+// Interactions without a matching handler are passed to Default.
+type InteractionHandler struct {
+	secrets *SecretsMiddleware
+	d       Debug
+
+	typeMap       map[InteractionType]InteractionHandlerFunc
+	actionIDMap   map[InteractionType]map[string]InteractionHandlerFunc
+	blockIDMap    map[InteractionType]map[string]InteractionHandlerFunc
+	callbackIDMap map[InteractionType]map[string]InteractionHandlerFunc
+	externalIDMap map[string]InteractionHandlerFunc
+
+	Default InteractionHandlerFunc
+}
+
+// NewInteractionHandler returns an InteractionHandler that verifies requests
+// with the given signing secret.
+func NewInteractionHandler(signingSecret string) *InteractionHandler {
+	return &InteractionHandler{
+		secrets:       NewSecretsMiddleware([]string{signingSecret}),
+		typeMap:       make(map[InteractionType]InteractionHandlerFunc),
+		actionIDMap:   make(map[InteractionType]map[string]InteractionHandlerFunc),
+		blockIDMap:    make(map[InteractionType]map[string]InteractionHandlerFunc),
+		callbackIDMap: make(map[InteractionType]map[string]InteractionHandlerFunc),
+		externalIDMap: make(map[string]InteractionHandlerFunc),
+		Default: func(ctx context.Context, callback *InteractionCallback) (interface{}, error) {
+			return nil, nil
+		},
+	}
+}
+
+// WithDebug sets the Debug used to log rejected requests.
+func (h *InteractionHandler) WithDebug(d Debug) *InteractionHandler {
+	h.d = d
+	return h
+}
+
+// WithSecrets sets the SecretsMiddleware verifying the requests, e.g. to accept
+// several signing secrets while one is rotated, or to reject replayed requests.
+// The requests failing the verification are answered by its error handler.
+func (h *InteractionHandler) WithSecrets(secrets *SecretsMiddleware) *InteractionHandler {
+	h.secrets = secrets
+	return h
+}
+
+// Handle registers a handler for every interaction of the given type.
+func (h *InteractionHandler) Handle(t InteractionType, f InteractionHandlerFunc) {
+	mustRegisterInteraction(string(t), f)
+	if _, exist := h.typeMap[t]; exist {
+		panic("multiple registrations for interaction type " + string(t))
+	}
+	h.typeMap[t] = f
+}
+
+// HandleBlockAction registers a handler for block_actions by action_id.
+func (h *InteractionHandler) HandleBlockAction(actionID string, f InteractionHandlerFunc) {
+	h.register(h.actionIDMap, InteractionTypeBlockActions, actionID, f)
+}
+
+// HandleBlockActionInBlock registers a handler for block_actions by block_id.
+func (h *InteractionHandler) HandleBlockActionInBlock(blockID string, f InteractionHandlerFunc) {
+	h.register(h.blockIDMap, InteractionTypeBlockActions, blockID, f)
+}
+
+// HandleBlockSuggestion registers a handler for block_suggestion by action_id.
+// The handler usually returns an *OptionsResponse or *OptionGroupsResponse.
+func (h *InteractionHandler) HandleBlockSuggestion(actionID string, f InteractionHandlerFunc) {
+	h.register(h.actionIDMap, InteractionTypeBlockSuggestion, actionID, f)
+}
+
+// HandleViewSubmission registers a handler for view_submission by the
+// callback_id of the view. The handler may return a *ViewSubmissionResponse.
+func (h *InteractionHandler) HandleViewSubmission(callbackID string, f InteractionHandlerFunc) {
+	h.register(h.callbackIDMap, InteractionTypeViewSubmission, callbackID, f)
+}
+
+// HandleViewClosed registers a handler for view_closed by the callback_id of
+// the view.
+func (h *InteractionHandler) HandleViewClosed(callbackID string, f InteractionHandlerFunc) {
+	h.register(h.callbackIDMap, InteractionTypeViewClosed, callbackID, f)
+}
+
+// HandleShortcut registers a handler for global shortcuts by callback_id.
+func (h *InteractionHandler) HandleShortcut(callbackID string, f InteractionHandlerFunc) {
+	h.register(h.callbackIDMap, InteractionTypeShortcut, callbackID, f)
+}
+
+// HandleMessageAction registers a handler for message shortcuts by callback_id.
+func (h *InteractionHandler) HandleMessageAction(callbackID string, f InteractionHandlerFunc) {
+	h.register(h.callbackIDMap, InteractionTypeMessageAction, callbackID, f)
+}
+
+// HandleViewExternalID registers a handler for any interaction on a view with
+// the given external_id.
+func (h *InteractionHandler) HandleViewExternalID(externalID string, f InteractionHandlerFunc) {
+	mustRegisterInteraction(externalID, f)
+	if _, exist := h.externalIDMap[externalID]; exist {
+		panic("multiple registrations for external_id " + externalID)
+	}
+	h.externalIDMap[externalID] = f
+}
+
+// HandleDefault registers a handler to use as a last resort.
+func (h *InteractionHandler) HandleDefault(f InteractionHandlerFunc) {
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+	h.Default = f
+}
+
+func (h *InteractionHandler) register(m map[InteractionType]map[string]InteractionHandlerFunc, t InteractionType, id string, f InteractionHandlerFunc) {
+	mustRegisterInteraction(id, f)
+	if m[t] == nil {
+		m[t] = make(map[string]InteractionHandlerFunc)
+	}
+	if _, exist := m[t][id]; exist {
+		panic("multiple registrations for " + string(t) + " " + id)
+	}
+	m[t][id] = f
+}
+
+func mustRegisterInteraction(id string, f InteractionHandlerFunc) {
+	if id == "" {
+		panic("invalid id cannot be empty")
+	}
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.secrets.Verify(r.Header, body); err != nil {
+		h.debugf("slack: rejected interaction request: %v", err)
+		h.secrets.onError(w, r, err)
+		return
+	}
+
+	callback, err := ParseInteractionCallback(body)
+	if err != nil {
+		h.debugf("slack: invalid interaction payload: %v", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.Route(callback)(r.Context(), callback)
+	if err != nil {
+		h.debugf("slack: interaction handler failed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if resp == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		h.debugf("slack: failed to encode interaction response: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// Route returns the handler registered for the callback, or Default if none
+// matches.
+func (h *InteractionHandler) Route(callback *InteractionCallback) InteractionHandlerFunc {
+	actionID, blockID := callback.ActionID, callback.BlockID
+	if len(callback.ActionCallback.BlockActions) > 0 {
+		actionID = callback.ActionCallback.BlockActions[0].ActionID
+		blockID = callback.ActionCallback.BlockActions[0].BlockID
+	}
+
+	if f, ok := h.actionIDMap[callback.Type][actionID]; ok {
+		return f
+	}
+	if f, ok := h.blockIDMap[callback.Type][blockID]; ok {
+		return f
+	}
+
+	callbackID := callback.CallbackID
+	if callback.Type == InteractionTypeViewSubmission || callback.Type == InteractionTypeViewClosed {
+		callbackID = callback.View.CallbackID
+	}
+	if f, ok := h.callbackIDMap[callback.Type][callbackID]; ok {
+		return f
+	}
+
+	if f, ok := h.externalIDMap[callback.View.ExternalID]; ok {
+		return f
+	}
+	if f, ok := h.typeMap[callback.Type]; ok {
+		return f
+	}
+	return h.Default
+}
+
+func (h *InteractionHandler) debugf(format string, v ...interface{}) {
+	if h.d != nil && h.d.Debug() {
+		h.d.Debugf(format, v...)
+	}
+}
+
+// ParseInteractionCallback parses the form-encoded body of an interactivity
+// request into an InteractionCallback.
+func ParseInteractionCallback(body []byte) (*InteractionCallback, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := values.Get("payload")
+	if payload == "" {
+		return nil, ErrParametersMissing
+	}
+
+	var callback InteractionCallback
+	if err := json.Unmarshal([]byte(payload), &callback); err != nil {
+		return nil, err
+	}
+	return &callback, nil
+}
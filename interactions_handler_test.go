@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignedInteractionRequest(t *testing.T, secret, payload string) *http.Request {
+	t.Helper()
+
+	body := url.Values{"payload": {payload}}.Encode()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func namedInteractionHandler(name string, calls *[]string) InteractionHandlerFunc {
+	return func(ctx context.Context, callback *InteractionCallback) (interface{}, error) {
+		*calls = append(*calls, name)
+		return nil, nil
+	}
+}
+
+func TestInteractionHandlerRouting(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{"block action by action_id", `{"type":"block_actions","actions":[{"action_id":"approve","block_id":"b1"}]}`, "action"},
+		{"block action by block_id", `{"type":"block_actions","actions":[{"action_id":"other","block_id":"b1"}]}`, "block"},
+		{"block action on view by external_id", `{"type":"block_actions","actions":[{"action_id":"x","block_id":"y"}],"view":{"external_id":"ext"}}`, "external"},
+		{"block action by type", `{"type":"block_actions","actions":[{"action_id":"x","block_id":"y"}]}`, "type"},
+		{"view submission by callback_id", `{"type":"view_submission","view":{"callback_id":"incident_create"}}`, "submission"},
+		{"view closed by callback_id", `{"type":"view_closed","view":{"callback_id":"incident_create"}}`, "closed"},
+		{"shortcut by callback_id", `{"type":"shortcut","callback_id":"declare"}`, "shortcut"},
+		{"message action by callback_id", `{"type":"message_action","callback_id":"declare"}`, "message_action"},
+		{"block suggestion by action_id", `{"type":"block_suggestion","action_id":"approve"}`, "suggestion"},
+		{"unhandled", `{"type":"dialog_submission"}`, "default"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls []string
+
+			h := NewInteractionHandler("secret")
+			h.HandleBlockAction("approve", namedInteractionHandler("action", &calls))
+			h.HandleBlockActionInBlock("b1", namedInteractionHandler("block", &calls))
+			h.HandleViewExternalID("ext", namedInteractionHandler("external", &calls))
+			h.Handle(InteractionTypeBlockActions, namedInteractionHandler("type", &calls))
+			h.HandleViewSubmission("incident_create", namedInteractionHandler("submission", &calls))
+			h.HandleViewClosed("incident_create", namedInteractionHandler("closed", &calls))
+			h.HandleShortcut("declare", namedInteractionHandler("shortcut", &calls))
+			h.HandleMessageAction("declare", namedInteractionHandler("message_action", &calls))
+			h.HandleBlockSuggestion("approve", namedInteractionHandler("suggestion", &calls))
+			h.HandleDefault(namedInteractionHandler("default", &calls))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, newSignedInteractionRequest(t, "secret", test.payload))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, []string{test.expected}, calls)
+		})
+	}
+}
+
+func TestInteractionHandlerResponse(t *testing.T) {
+	h := NewInteractionHandler("secret")
+	h.HandleViewSubmission("incident_create", func(ctx context.Context, callback *InteractionCallback) (interface{}, error) {
+		return NewErrorsViewSubmissionResponse(map[string]string{"name": "required"}), nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedInteractionRequest(t, "secret", `{"type":"view_submission","view":{"callback_id":"incident_create"}}`))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp ViewSubmissionResponse
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, RAErrors, resp.ResponseAction)
+	assert.Equal(t, "required", resp.Errors["name"])
+}
+
+func TestInteractionHandlerRejectsRequests(t *testing.T) {
+	h := NewInteractionHandler("secret")
+	h.HandleDefault(func(ctx context.Context, callback *InteractionCallback) (interface{}, error) {
+		t.Fatal("handler should not be called")
+		return nil, nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedInteractionRequest(t, "other-secret", `{"type":"shortcut"}`))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedInteractionRequest(t, "secret", `not json`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slack/interactions", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestInteractionHandlerWithSecrets(t *testing.T) {
+	var rejected []error
+	secrets := NewSecretsMiddleware([]string{"new-secret", "old-secret"},
+		SecretsMiddlewareOptionReplayProtection(),
+		SecretsMiddlewareOptionErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			rejected = append(rejected, err)
+			w.WriteHeader(http.StatusForbidden)
+		}),
+	)
+	h := NewInteractionHandler("unused").WithSecrets(secrets)
+
+	req := newSignedInteractionRequest(t, "old-secret", `{"type":"shortcut"}`)
+	replay := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(url.Values{"payload": {`{"type":"shortcut"}`}}.Encode()))
+	replay.Header = req.Header.Clone()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, replay)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, []error{ErrReplayedRequest}, rejected)
+}
+
+func TestInteractionHandlerDuplicateRegistration(t *testing.T) {
+	h := NewInteractionHandler("secret")
+	f := func(ctx context.Context, callback *InteractionCallback) (interface{}, error) { return nil, nil }
+	h.HandleShortcut("declare", f)
+
+	assert.Panics(t, func() { h.HandleShortcut("declare", f) })
+	assert.NotPanics(t, func() { h.HandleMessageAction("declare", f) })
+	assert.Panics(t, func() { h.HandleBlockAction("", f) })
+}
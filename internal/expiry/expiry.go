@@ -0,0 +1,36 @@
+// Package expiry finds the expired keys of a map without scanning all of them.
+package expiry
+
+import "time"
+
+// Queue holds keys in the order they expire, which is the order they are pushed in when they
+// all live for the same duration. Its zero value is ready to use.
+type Queue[K comparable] struct {
+	entries []entry[K]
+}
+
+type entry[K comparable] struct {
+	key K
+	at  time.Time
+}
+
+// Push adds a key expiring at a time later than, or equal to, the keys already pushed.
+func (q *Queue[K]) Push(key K, at time.Time) {
+	q.entries = append(q.entries, entry[K]{key: key, at: at})
+}
+
+// PopExpired removes the keys expired at now and calls f with each of them. A key may have been
+// pushed again since, so f must check that it is still expired before deleting it.
+func (q *Queue[K]) PopExpired(now time.Time, f func(key K)) {
+	i := 0
+	for ; i < len(q.entries) && !now.Before(q.entries[i].at); i++ {
+		f(q.entries[i].key)
+	}
+	// Slicing releases the popped entries once append reallocates the array
+	q.entries = q.entries[i:]
+}
+
+// Len returns the number of keys in the queue.
+func (q *Queue[K]) Len() int {
+	return len(q.entries)
+}
@@ -0,0 +1,30 @@
+package expiry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	var q Queue[string]
+	start := time.Unix(1000, 0)
+	q.Push("a", start.Add(time.Second))
+	q.Push("b", start.Add(2*time.Second))
+	q.Push("c", start.Add(3*time.Second))
+
+	var expired []string
+	collect := func(key string) { expired = append(expired, key) }
+
+	q.PopExpired(start, collect)
+	assert.Empty(t, expired)
+
+	q.PopExpired(start.Add(2*time.Second), collect)
+	assert.Equal(t, []string{"a", "b"}, expired)
+	assert.Equal(t, 1, q.Len())
+
+	q.PopExpired(start.Add(time.Hour), collect)
+	assert.Equal(t, []string{"a", "b", "c"}, expired)
+	assert.Equal(t, 0, q.Len())
+}
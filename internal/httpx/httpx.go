@@ -0,0 +1,9 @@
+// Package httpx holds the HTTP helpers shared by the packages of the module.
+package httpx
+
+import "net/http"
+
+// Client is the interface of the clients accepted by slack.OptionHTTPClient, e.g. *http.Client
+type Client interface {
+	Do(*http.Request) (*http.Response, error)
+}
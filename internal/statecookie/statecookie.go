@@ -0,0 +1,158 @@
+// Package statecookie ties the callbacks of the OAuth flows of Slack to the browsers that
+// started them, with a signed, expiring state that is both passed to Slack and set as a cookie,
+// to protect the callbacks against CSRF.
+package statecookie
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// nonceLength is the length of the random part of the states
+const nonceLength = 16
+
+// AuthorizationError is the error Slack redirects to the callback with, e.g. "access_denied"
+// when the user cancels.
+type AuthorizationError struct {
+	Code string
+}
+
+func (e AuthorizationError) Error() string {
+	return fmt.Sprintf("authorization failed: %s", e.Code)
+}
+
+// Errors are the errors a flow reports its failed callbacks with
+type Errors struct {
+	InvalidState error
+	ExpiredState error
+	MissingCode  error
+}
+
+// States issues and verifies the states of a flow
+type States struct {
+	CookieName string
+	// Secret signs the states. It must be shared by all the instances of the app serving the flow.
+	Secret []byte
+	TTL    time.Duration
+	Errors Errors
+	// Insecure sends the cookie over plain HTTP too, for local development
+	Insecure bool
+}
+
+// NewSecret returns a random secret, for the apps that don't set one
+func NewSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("unable to generate the state secret: %s", err.Error()))
+	}
+	return secret
+}
+
+// New returns a random state expiring after the TTL, signed with the secret:
+// base64(nonce | expiry) "." base64(hmac)
+func (s *States) New(now time.Time) (string, error) {
+	payload := make([]byte, nonceLength+8)
+	if _, err := rand.Read(payload[:nonceLength]); err != nil {
+		return "", err
+	}
+	binary.BigEndian.PutUint64(payload[nonceLength:], uint64(now.Add(s.TTL).Unix()))
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.Sign("state:"+encoded)), nil
+}
+
+// Verify checks the signature and the expiry of a state
+func (s *States) Verify(state string, now time.Time) error {
+	encoded, signature, ok := strings.Cut(state, ".")
+	if !ok {
+		return s.Errors.InvalidState
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.Sign("state:"+encoded)) {
+		return s.Errors.InvalidState
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) != nonceLength+8 {
+		return s.Errors.InvalidState
+	}
+	if expiry := time.Unix(int64(binary.BigEndian.Uint64(payload[nonceLength:])), 0); now.After(expiry) {
+		return s.Errors.ExpiredState
+	}
+	return nil
+}
+
+// Sign returns the HMAC of v with the secret. Values derived from a state must be prefixed
+// differently from "state:", not to be mistaken for its signature.
+func (s *States) Sign(v string) []byte {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(v))
+	return mac.Sum(nil)
+}
+
+// Start returns a new state, and sets it as the cookie of the response
+func (s *States) Start(w http.ResponseWriter, r *http.Request) (string, error) {
+	state, err := s.New(time.Now())
+	if err != nil {
+		return "", err
+	}
+	http.SetCookie(w, s.cookie(state, int(s.TTL.Seconds())))
+	return state, nil
+}
+
+// Callback verifies the state of a request to the redirect URI against the cookie, and returns
+// it with the code to exchange. The cookie is cleared, as the state is single use.
+func (s *States) Callback(w http.ResponseWriter, r *http.Request) (state, code string, err error) {
+	http.SetCookie(w, s.cookie("", -1))
+
+	query := r.URL.Query()
+	cookie, err := r.Cookie(s.CookieName)
+	if err != nil || cookie.Value != query.Get("state") {
+		return "", "", s.Errors.InvalidState
+	}
+	if err := s.Verify(cookie.Value, time.Now()); err != nil {
+		return "", "", err
+	}
+
+	if code := query.Get("error"); code != "" {
+		return "", "", AuthorizationError{Code: code}
+	}
+	code = query.Get("code")
+	if code == "" {
+		return "", "", s.Errors.MissingCode
+	}
+	return cookie.Value, code, nil
+}
+
+// Failure writes the default response of a failed callback: the errors of the flow, caused by
+// the user or the browser, with a 400 status, and a generic message with a 500 status otherwise.
+func (s *States) Failure(w http.ResponseWriter, err error, message string) {
+	var authErr AuthorizationError
+	switch {
+	case errors.Is(err, s.Errors.InvalidState), errors.Is(err, s.Errors.ExpiredState), errors.Is(err, s.Errors.MissingCode), errors.As(err, &authErr):
+		http.Error(w, fmt.Sprintf("%s: %s", message, err.Error()), http.StatusBadRequest)
+	default:
+		http.Error(w, message+", please try again.", http.StatusInternalServerError)
+	}
+}
+
+func (s *States) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.CookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   !s.Insecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -1,5 +1,7 @@
 package slack
 
+import "encoding/json"
+
 // OutgoingMessage is used for the realtime API, and seems incomplete.
 type OutgoingMessage struct {
 	ID int `json:"id"`
@@ -23,6 +25,57 @@ type Message struct {
 	Root *Msg `json:"root,omitempty"`
 }
 
+// messageFields holds the fields Message adds on top of the embedded Msg.
+type messageFields struct {
+	SubMessage      *Msg `json:"message,omitempty"`
+	PreviousMessage *Msg `json:"previous_message,omitempty"`
+	Root            *Msg `json:"root,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Message. It is
+// needed because the methods of the embedded Msg would otherwise be promoted
+// and decode only the Msg part of the object.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type alias Msg
+	v := struct {
+		*alias
+		*messageFields
+	}{
+		alias:         (*alias)(&m.Msg),
+		messageFields: &messageFields{},
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	unknown, err := unmarshalUnknownFields(data, &v)
+	if err != nil {
+		return err
+	}
+
+	m.SubMessage = v.SubMessage
+	m.PreviousMessage = v.PreviousMessage
+	m.Root = v.Root
+	m.UnknownFields = unknown
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for Message.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type alias Msg
+	v := struct {
+		*alias
+		messageFields
+	}{
+		alias: (*alias)(&m.Msg),
+		messageFields: messageFields{
+			SubMessage:      m.SubMessage,
+			PreviousMessage: m.PreviousMessage,
+			Root:            m.Root,
+		},
+	}
+	return marshalWithUnknownFields(&v, m.UnknownFields)
+}
+
 // Msg SubTypes (https://api.slack.com/events/message)
 const (
 	MsgSubTypeBotMessage                = "bot_message"                 // [Events API, RTM] A message was posted by an integration
@@ -141,6 +194,33 @@ type Msg struct {
 	Blocks Blocks `json:"blocks,omitempty"`
 	// permalink
 	Permalink string `json:"permalink,omitempty"`
+
+	// UnknownFields holds any fields of the message that this library does not
+	// model. They are re-emitted when the message is marshalled, so that a
+	// message can be read and posted again without losing data.
+	UnknownFields map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Msg, retaining
+// unrecognized fields in UnknownFields.
+func (m *Msg) UnmarshalJSON(data []byte) error {
+	type alias Msg
+	if err := json.Unmarshal(data, (*alias)(m)); err != nil {
+		return err
+	}
+	unknown, err := unmarshalUnknownFields(data, (*alias)(m))
+	if err != nil {
+		return err
+	}
+	m.UnknownFields = unknown
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for Msg, re-emitting
+// any UnknownFields.
+func (m Msg) MarshalJSON() ([]byte, error) {
+	type alias Msg
+	return marshalWithUnknownFields((*alias)(&m), m.UnknownFields)
 }
 
 const (
@@ -116,6 +116,10 @@ type SlackResponse struct {
 	Error            string                `json:"error"`
 	Errors           []SlackResponseErrors `json:"errors,omitempty"`
 	ResponseMetadata ResponseMetadata      `json:"response_metadata"`
+	// Needed and Provided are the scopes needed by the method and provided by the token, set
+	// with the "missing_scope" error.
+	Needed   string `json:"needed,omitempty"`
+	Provided string `json:"provided,omitempty"`
 }
 
 func (t SlackResponse) Err() error {
@@ -130,7 +134,7 @@ func (t SlackResponse) Err() error {
 		return nil
 	}
 
-	return SlackErrorResponse{Err: t.Error, Errors: t.Errors, ResponseMetadata: t.ResponseMetadata}
+	return SlackErrorResponse{Err: t.Error, Errors: t.Errors, ResponseMetadata: t.ResponseMetadata, Needed: t.Needed, Provided: t.Provided}
 }
 
 // SlackErrorResponse brings along the metadata of errors returned by the Slack
@@ -139,6 +143,9 @@ type SlackErrorResponse struct {
 	Err              string
 	Errors           []SlackResponseErrors
 	ResponseMetadata ResponseMetadata
+	// Needed and Provided are the comma separated scopes of a "missing_scope" error
+	Needed   string
+	Provided string
 }
 
 func (r SlackErrorResponse) Error() string { return r.Err }
@@ -0,0 +1,193 @@
+package mrkdwn
+
+import (
+	"strings"
+	"time"
+)
+
+// Resolver returns the name of a mentioned user, channel or user group, e.g. "alice" for a
+// *UserMention, or "" if it is unknown.
+type Resolver func(mention Node) string
+
+// Builder builds mrkdwn, escaping the text appended. The mrkdwn appended with Mrkdwn, e.g. input
+// of users, can have its mentions stripped, so that posting it notifies no one.
+type Builder struct {
+	sb            strings.Builder
+	quoted        bool // the last node is a quote, whose line must be ended
+	stripMentions bool
+	resolver      Resolver
+}
+
+// BuilderOption configures a Builder.
+type BuilderOption func(*Builder)
+
+// BuilderOptionStripMentions replaces the mentions of the mrkdwn appended with Mrkdwn by their
+// text, e.g. "@alice" or "@here", which notifies no one.
+func BuilderOptionStripMentions() BuilderOption {
+	return func(b *Builder) {
+		b.stripMentions = true
+	}
+}
+
+// BuilderOptionResolver sets the resolver of the names of the stripped mentions, which are
+// otherwise their labels, or IDs.
+func BuilderOptionResolver(resolver Resolver) BuilderOption {
+	return func(b *Builder) {
+		b.resolver = resolver
+	}
+}
+
+// NewBuilder returns an empty Builder
+func NewBuilder(options ...BuilderOption) *Builder {
+	b := &Builder{}
+	for _, opt := range options {
+		opt(b)
+	}
+	return b
+}
+
+// Text appends text, escaped so that it is not parsed as markup, links or mentions
+func (b *Builder) Text(s string) *Builder {
+	return b.Nodes(&Text{Text: s})
+}
+
+// Bold appends bold text
+func (b *Builder) Bold(s string) *Builder {
+	return b.Nodes(&Bold{Children: []Node{&Text{Text: s}}})
+}
+
+// Italic appends italic text
+func (b *Builder) Italic(s string) *Builder {
+	return b.Nodes(&Italic{Children: []Node{&Text{Text: s}}})
+}
+
+// Strike appends strikethrough text
+func (b *Builder) Strike(s string) *Builder {
+	return b.Nodes(&Strike{Children: []Node{&Text{Text: s}}})
+}
+
+// Code appends inline code
+func (b *Builder) Code(s string) *Builder {
+	return b.Nodes(&Code{Text: s})
+}
+
+// Pre appends a preformatted block
+func (b *Builder) Pre(s string) *Builder {
+	return b.Nodes(&Pre{Text: s})
+}
+
+// Quote appends a quote of the text, starting and ending a line
+func (b *Builder) Quote(s string) *Builder {
+	return b.Nodes(&Quote{Children: []Node{&Text{Text: s}}})
+}
+
+// Link appends a link to the URL, with a text or not
+func (b *Builder) Link(url, text string) *Builder {
+	return b.Nodes(&Link{URL: url, Text: text})
+}
+
+// User appends the mention of a user
+func (b *Builder) User(userID string) *Builder {
+	return b.Nodes(&UserMention{ID: userID})
+}
+
+// Channel appends the mention of a channel
+func (b *Builder) Channel(channelID string) *Builder {
+	return b.Nodes(&ChannelMention{ID: channelID})
+}
+
+// Usergroup appends the mention of a user group
+func (b *Builder) Usergroup(usergroupID string) *Builder {
+	return b.Nodes(&UsergroupMention{ID: usergroupID})
+}
+
+// Special appends a special mention: here, channel or everyone
+func (b *Builder) Special(name string) *Builder {
+	return b.Nodes(&SpecialMention{Name: name})
+}
+
+// Date appends a date formatted in the timezone of the reader, e.g. with the format
+// "{date_short} at {time}". The fallback is displayed by the clients unable to format it.
+func (b *Builder) Date(t time.Time, format, fallback string) *Builder {
+	return b.Nodes(&Date{Timestamp: t.Unix(), Format: format, Fallback: fallback})
+}
+
+// Nodes appends nodes, rendered to mrkdwn
+func (b *Builder) Nodes(nodes ...Node) *Builder {
+	if len(nodes) == 0 {
+		return b
+	}
+
+	// A quote must start a line
+	if _, ok := nodes[0].(*Quote); ok && b.sb.Len() > 0 && !strings.HasSuffix(b.sb.String(), "\n") {
+		b.write("\n")
+	}
+	b.write(Render(nodes...))
+	_, b.quoted = nodes[len(nodes)-1].(*Quote)
+	return b
+}
+
+// Mrkdwn appends mrkdwn, e.g. input of users, stripping its mentions if configured to. The
+// mrkdwn is parsed and rendered again, so that unbalanced markup does not leak into the rest.
+func (b *Builder) Mrkdwn(s string) *Builder {
+	nodes := Parse(s)
+	if b.stripMentions {
+		nodes = b.strip(nodes)
+	}
+	return b.Nodes(nodes...)
+}
+
+// Newline appends a line break
+func (b *Builder) Newline() *Builder {
+	b.write("\n")
+	return b
+}
+
+func (b *Builder) write(s string) {
+	if b.quoted && s != "" {
+		if !strings.HasPrefix(s, "\n") {
+			b.sb.WriteString("\n")
+		}
+		b.quoted = false
+	}
+	b.sb.WriteString(s)
+}
+
+// String returns the mrkdwn built
+func (b *Builder) String() string {
+	return b.sb.String()
+}
+
+// strip replaces the mentions of the nodes by text
+func (b *Builder) strip(nodes []Node) []Node {
+	stripped := make([]Node, len(nodes))
+	for i, node := range nodes {
+		switch n := node.(type) {
+		case *Bold:
+			stripped[i] = &Bold{Children: b.strip(n.Children)}
+		case *Italic:
+			stripped[i] = &Italic{Children: b.strip(n.Children)}
+		case *Strike:
+			stripped[i] = &Strike{Children: b.strip(n.Children)}
+		case *Quote:
+			stripped[i] = &Quote{Children: b.strip(n.Children)}
+		case *UserMention, *ChannelMention, *UsergroupMention, *SpecialMention:
+			stripped[i] = &Text{Text: b.mentionText(node)}
+		default:
+			stripped[i] = node
+		}
+	}
+	return stripped
+}
+
+func (b *Builder) mentionText(mention Node) string {
+	if b.resolver != nil {
+		if name := b.resolver(mention); name != "" {
+			if _, ok := mention.(*ChannelMention); ok {
+				return "#" + name
+			}
+			return "@" + name
+		}
+	}
+	return mentionText(mention)
+}
@@ -0,0 +1,66 @@
+package mrkdwn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder().
+		Bold("Incident").Text(": <!channel> db & cache down").Newline().
+		Text("Owner: ").User("U1").Text(", see ").Link("https://example.com/i/1", "the timeline").
+		Quote("Started at").
+		Date(time.Unix(1392734382, 0), "{date_short}", "Feb 18, 2014")
+
+	assert.Equal(t, "*Incident*: &lt;!channel&gt; db &amp; cache down\n"+
+		"Owner: <@U1>, see <https://example.com/i/1|the timeline>\n"+
+		"> Started at\n<!date^1392734382^{date_short}|Feb 18, 2014>", b.String())
+}
+
+func TestBuilderMrkdwn(t *testing.T) {
+	title := "*DB down* <!here> cc <@U1> <#C1|ops> <!subteam^S1|@oncall> `unclosed *bold"
+	// The unclosed markup is escaped, so that it is not closed by the mrkdwn appended next
+	unclosed := "\u200d`\u200dunclosed \u200d*\u200dbold"
+
+	assert.Equal(t, "*DB down* <!here> cc <@U1> <#C1|ops> <!subteam^S1|@oncall> "+unclosed, NewBuilder().Mrkdwn(title).String())
+
+	assert.Equal(t, "*DB down* @here cc @U1 #ops @oncall "+unclosed,
+		NewBuilder(BuilderOptionStripMentions()).Mrkdwn(title).String())
+
+	resolver := func(mention Node) string {
+		switch m := mention.(type) {
+		case *UserMention:
+			if m.ID == "U1" {
+				return "alice"
+			}
+		case *ChannelMention:
+			return "incidents"
+		}
+		return ""
+	}
+	assert.Equal(t, "*DB down* @here cc @alice #incidents @oncall "+unclosed,
+		NewBuilder(BuilderOptionStripMentions(), BuilderOptionResolver(resolver)).Mrkdwn(title).String())
+}
+
+func TestBuilderEscapesMarkup(t *testing.T) {
+	tests := []struct {
+		name     string
+		built    *Builder
+		expected []Node
+	}{
+		{"code", NewBuilder().Code("a`b"), []Node{&Code{Text: "a`b"}}},
+		{"pre", NewBuilder().Pre("x\n```\n*y*"), []Node{&Pre{Text: "x\n```\n*y*"}}},
+		{"text", NewBuilder().Text("*urgent* ~x~ `y` _z_"), []Node{&Text{Text: "*urgent* ~x~ `y` _z_"}}},
+		{"text around styles", NewBuilder().Text("a *").Bold("b*c").Text("* d"), []Node{
+			&Text{Text: "a *"}, &Bold{Children: []Node{&Text{Text: "b*c"}}}, &Text{Text: "* d"},
+		}},
+		{"mrkdwn then text", NewBuilder().Mrkdwn("`a *b").Text(" c* `d"), []Node{&Text{Text: "`a *b c* `d"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Parse(tt.built.String()))
+		})
+	}
+}
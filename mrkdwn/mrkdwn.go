@@ -0,0 +1,411 @@
+// Package mrkdwn parses and builds the mrkdwn of Slack, the markup of the text of messages.
+//
+// For more information: https://api.slack.com/reference/surfaces/formatting
+package mrkdwn
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Node is a node of parsed mrkdwn.
+type Node interface {
+	node()
+}
+
+// Text is plain text, unescaped.
+type Text struct {
+	Text string
+}
+
+// Bold is *bold* text.
+type Bold struct {
+	Children []Node
+}
+
+// Italic is _italic_ text.
+type Italic struct {
+	Children []Node
+}
+
+// Strike is ~strikethrough~ text.
+type Strike struct {
+	Children []Node
+}
+
+// Code is `inline code`.
+type Code struct {
+	Text string
+}
+
+// Pre is a ```preformatted``` block.
+type Pre struct {
+	Text string
+}
+
+// Quote is a block of lines starting with ">", or of the lines after ">>>".
+type Quote struct {
+	Children []Node
+}
+
+// Link is a link, <https://slack.com|with a text> or not.
+type Link struct {
+	URL  string
+	Text string
+}
+
+// UserMention is the mention of a user, <@U024BE7LH>.
+type UserMention struct {
+	ID    string
+	Label string
+}
+
+// ChannelMention is the mention of a channel, <#C024BE7LR|general>.
+type ChannelMention struct {
+	ID   string
+	Name string
+}
+
+// UsergroupMention is the mention of a user group, <!subteam^S024BE7LV|@team>.
+type UsergroupMention struct {
+	ID    string
+	Label string
+}
+
+// SpecialMention is the mention of everyone in a channel or workspace: here, channel or everyone.
+type SpecialMention struct {
+	Name string
+}
+
+// Date is a date formatted in the timezone of the reader, <!date^1392734382^{date_short}|Feb 18, 2014>.
+type Date struct {
+	Timestamp int64
+	Format    string
+	Link      string
+	Fallback  string
+}
+
+func (*Text) node()             {}
+func (*Bold) node()             {}
+func (*Italic) node()           {}
+func (*Strike) node()           {}
+func (*Code) node()             {}
+func (*Pre) node()              {}
+func (*Quote) node()            {}
+func (*Link) node()             {}
+func (*UserMention) node()      {}
+func (*ChannelMention) node()   {}
+func (*UsergroupMention) node() {}
+func (*SpecialMention) node()   {}
+func (*Date) node()             {}
+
+const fence = "```"
+
+// Parse parses mrkdwn. Any text is valid mrkdwn: the markup which is not closed is parsed as text.
+// The markers escaped by Render, between zero width joiners, are parsed as text.
+func Parse(s string) []Node {
+	var nodes []Node
+	for s != "" {
+		start := strings.Index(s, fence)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start+len(fence):], fence)
+		if end < 0 {
+			break
+		}
+		end += start + len(fence)
+
+		nodes = append(nodes, parseLines(s[:start])...)
+		text := s[start+len(fence) : end]
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "\n"), "\n")
+		nodes = append(nodes, &Pre{Text: unescapeBackticks(unescape(text))})
+		s = s[end+len(fence):]
+	}
+	return merge(append(nodes, parseLines(s)...))
+}
+
+// parseLines parses the quotes, and the inline markup of the lines
+func parseLines(s string) []Node {
+	if s == "" {
+		return nil
+	}
+
+	var nodes []Node
+	var text, quote []string
+	flush := func() {
+		if len(text) > 0 {
+			nodes = append(nodes, parseInline(strings.Join(text, "\n"))...)
+			text = nil
+		}
+		if len(quote) > 0 {
+			nodes = append(nodes, &Quote{Children: parseInline(strings.Join(quote, "\n"))})
+			quote = nil
+		}
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if rest, ok := cutQuote(line, ">>>"); ok {
+			if len(quote) == 0 && len(text) > 0 {
+				text = append(text, "")
+			}
+			flush()
+			quote = append([]string{strings.TrimPrefix(rest, " ")}, lines[i+1:]...)
+			break
+		}
+		if rest, ok := cutQuote(line, ">"); ok {
+			if len(quote) == 0 {
+				// The line break before the quote belongs to the text
+				if len(text) > 0 {
+					text = append(text, "")
+				}
+				flush()
+			}
+			quote = append(quote, strings.TrimPrefix(rest, " "))
+			continue
+		}
+		if len(quote) > 0 {
+			flush()
+		}
+		text = append(text, line)
+	}
+	flush()
+	return nodes
+}
+
+// cutQuote cuts the quote marker at the start of a line, escaped or not
+func cutQuote(line, marker string) (string, bool) {
+	if rest, ok := strings.CutPrefix(line, marker); ok {
+		return rest, true
+	}
+	return strings.CutPrefix(line, strings.Repeat("&gt;", len(marker)))
+}
+
+// parseInline parses the inline markup
+func parseInline(s string) []Node {
+	var nodes []Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &Text{Text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case joiner[0]:
+			if marker, ok := escapedMarker(s[i:]); ok {
+				text.WriteByte(marker)
+				i += len(joiner+joiner) + 1
+				continue
+			}
+		case '`':
+			if end := codeEnd(s, i); end > 0 {
+				flush()
+				nodes = append(nodes, &Code{Text: unescapeBackticks(unescape(s[i+1 : end]))})
+				i = end + 1
+				continue
+			}
+		case '<':
+			if end := strings.IndexByte(s[i+1:], '>'); end > 0 {
+				if node := parseControl(s[i+1 : i+1+end]); node != nil {
+					flush()
+					nodes = append(nodes, node)
+					i += end + 2
+					continue
+				}
+			}
+		case '&':
+			if r, n := entity(s[i:]); n > 0 {
+				text.WriteString(r)
+				i += n
+				continue
+			}
+		case '*', '_', '~':
+			if end := closing(s, i); end > 0 {
+				flush()
+				children := parseInline(s[i+1 : end])
+				switch c {
+				case '*':
+					nodes = append(nodes, &Bold{Children: children})
+				case '_':
+					nodes = append(nodes, &Italic{Children: children})
+				default:
+					nodes = append(nodes, &Strike{Children: children})
+				}
+				i = end + 1
+				continue
+			}
+		}
+		text.WriteByte(s[i])
+		i++
+	}
+	flush()
+	return nodes
+}
+
+// closing returns the index of the marker closing the one at start, or -1. A marker opens when
+// it does not follow a word and is followed by a non-space, and it closes when it follows a
+// non-space and is not followed by a word. Runs of markers, e.g. "**", are text, and styles do
+// not span lines.
+func closing(s string, start int) int {
+	marker := s[start]
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(s[:start]); isWord(r) || s[start-1] == marker {
+			return -1
+		}
+	}
+	if start+1 >= len(s) || s[start+1] == marker || isSpace(s[start+1]) {
+		return -1
+	}
+
+	for j := start + 2; j < len(s); j++ {
+		switch s[j] {
+		case '\n':
+			return -1
+		case marker:
+			if escapedAt(s, j) {
+				continue
+			}
+			if j+1 < len(s) && s[j+1] == marker {
+				for j+1 < len(s) && s[j+1] == marker {
+					j++
+				}
+				continue
+			}
+			if isSpace(s[j-1]) {
+				continue
+			}
+			if r, _ := utf8.DecodeRuneInString(s[j+1:]); j+1 < len(s) && isWord(r) {
+				continue
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+// escapedMarker returns the marker escaped at the start of s, between joiners
+func escapedMarker(s string) (byte, bool) {
+	n := len(joiner)
+	if len(s) < 2*n+1 || s[:n] != joiner || s[n+1:2*n+1] != joiner || strings.IndexByte(markers, s[n]) < 0 {
+		return 0, false
+	}
+	return s[n], true
+}
+
+// escapedAt reports whether the marker at i is escaped
+func escapedAt(s string, i int) bool {
+	if i < len(joiner) {
+		return false
+	}
+	_, ok := escapedMarker(s[i-len(joiner):])
+	return ok
+}
+
+// codeEnd returns the index of the backtick ending the code started at start, or -1. The escaped
+// backticks are part of the code.
+func codeEnd(s string, start int) int {
+	for j := start + 1; j < len(s); j++ {
+		if s[j] != '`' || escapedAt(s, j) {
+			continue
+		}
+		if j == start+1 {
+			return -1
+		}
+		return j
+	}
+	return -1
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n'
+}
+
+// parseControl parses the content of <...>
+func parseControl(s string) Node {
+	target, label, _ := strings.Cut(s, "|")
+	target, label = unescape(target), unescape(label)
+	if target == "" {
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(target, "@"):
+		return &UserMention{ID: target[1:], Label: label}
+	case strings.HasPrefix(target, "#"):
+		return &ChannelMention{ID: target[1:], Name: label}
+	case strings.HasPrefix(target, "!subteam^"):
+		return &UsergroupMention{ID: strings.TrimPrefix(target, "!subteam^"), Label: label}
+	case strings.HasPrefix(target, "!date^"):
+		parts := strings.SplitN(strings.TrimPrefix(target, "!date^"), "^", 3)
+		timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) < 2 {
+			return nil
+		}
+		date := &Date{Timestamp: timestamp, Format: parts[1], Fallback: label}
+		if len(parts) == 3 {
+			date.Link = parts[2]
+		}
+		return date
+	case strings.HasPrefix(target, "!"):
+		return &SpecialMention{Name: strings.TrimPrefix(target, "!")}
+	default:
+		return &Link{URL: target, Text: label}
+	}
+}
+
+var entities = map[string]string{"&amp;": "&", "&lt;": "<", "&gt;": ">"}
+
+// entity decodes the entity at the start of s, and returns its length, or 0
+func entity(s string) (string, int) {
+	for e, r := range entities {
+		if strings.HasPrefix(s, e) {
+			return r, len(e)
+		}
+	}
+	return "", 0
+}
+
+var unescaper = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">")
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
+
+// merge merges the consecutive text nodes, and removes the empty ones
+func merge(nodes []Node) []Node {
+	merged := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		text, ok := node.(*Text)
+		if !ok {
+			merged = append(merged, node)
+			continue
+		}
+		if text.Text == "" {
+			continue
+		}
+		if last, ok := lastText(merged); ok {
+			last.Text += text.Text
+			continue
+		}
+		merged = append(merged, &Text{Text: text.Text})
+	}
+	return merged
+}
+
+func lastText(nodes []Node) (*Text, bool) {
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	text, ok := nodes[len(nodes)-1].(*Text)
+	return text, ok
+}
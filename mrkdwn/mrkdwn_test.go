@@ -0,0 +1,102 @@
+package mrkdwn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Node
+	}{
+		{"text", "a &lt;b&gt; &amp; c", []Node{&Text{Text: "a <b> & c"}}},
+		{"styles", "*bold* _italic_ ~strike~", []Node{
+			&Bold{Children: []Node{&Text{Text: "bold"}}},
+			&Text{Text: " "},
+			&Italic{Children: []Node{&Text{Text: "italic"}}},
+			&Text{Text: " "},
+			&Strike{Children: []Node{&Text{Text: "strike"}}},
+		}},
+		{"nested styles", "*bold _and italic_*", []Node{
+			&Bold{Children: []Node{&Text{Text: "bold "}, &Italic{Children: []Node{&Text{Text: "and italic"}}}}},
+		}},
+		{"not styles", "snake_case_name 2*3*4 * a * **b** *c\nd*", []Node{&Text{Text: "snake_case_name 2*3*4 * a * **b** *c\nd*"}}},
+		{"code", "run `a *b* <c>`", []Node{&Text{Text: "run "}, &Code{Text: "a *b* <c>"}}},
+		{"pre", "before\n```\nx := *1*\n```\nafter", []Node{
+			&Text{Text: "before\n"}, &Pre{Text: "x := *1*"}, &Text{Text: "\nafter"},
+		}},
+		{"quote", "intro\n> one\n&gt; *two*\nafter", []Node{
+			&Text{Text: "intro\n"},
+			&Quote{Children: []Node{&Text{Text: "one\n"}, &Bold{Children: []Node{&Text{Text: "two"}}}}},
+			&Text{Text: "after"},
+		}},
+		{"multiline quote", ">>> one\ntwo", []Node{&Quote{Children: []Node{&Text{Text: "one\ntwo"}}}}},
+		{"links", "<https://slack.com> <https://slack.com/a?b=1&amp;c=2|Slack &amp; co> <mailto:a@example.com|mail>", []Node{
+			&Link{URL: "https://slack.com"},
+			&Text{Text: " "},
+			&Link{URL: "https://slack.com/a?b=1&c=2", Text: "Slack & co"},
+			&Text{Text: " "},
+			&Link{URL: "mailto:a@example.com", Text: "mail"},
+		}},
+		{"mentions", "<@U1> <@U2|bob> <#C1|general> <!subteam^S1|@oncall> <!here> <!channel|channel>", []Node{
+			&UserMention{ID: "U1"},
+			&Text{Text: " "},
+			&UserMention{ID: "U2", Label: "bob"},
+			&Text{Text: " "},
+			&ChannelMention{ID: "C1", Name: "general"},
+			&Text{Text: " "},
+			&UsergroupMention{ID: "S1", Label: "@oncall"},
+			&Text{Text: " "},
+			&SpecialMention{Name: "here"},
+			&Text{Text: " "},
+			&SpecialMention{Name: "channel"},
+		}},
+		{"date", "<!date^1392734382^{date_short} at {time}^https://example.com|Feb 18, 2014>", []Node{
+			&Date{Timestamp: 1392734382, Format: "{date_short} at {time}", Link: "https://example.com", Fallback: "Feb 18, 2014"},
+		}},
+		{"unclosed", "<@U1 *a `b", []Node{&Text{Text: "<@U1 *a `b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Parse(tt.input))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, s := range []string{
+		"*bold _and italic_* ~strike~ `code`",
+		"before\n```\nx := 1\n```\nafter",
+		"intro\n> one\n> two\nafter",
+		"<https://slack.com|Slack &amp; co> <@U1> <#C1|general> <!subteam^S1> <!here>",
+		"<!date^1392734382^{date_short}|Feb 18, 2014>",
+		"a &lt;b&gt; &amp; c",
+	} {
+		assert.Equal(t, s, Render(Parse(s)...))
+	}
+}
+
+func TestRenderEscapesMarkup(t *testing.T) {
+	for _, nodes := range [][]Node{
+		{&Code{Text: "a`b"}},
+		{&Code{Text: "```"}},
+		{&Pre{Text: "x\n```\n*y*"}},
+		{&Text{Text: "*urgent* ~x~"}},
+		{&Text{Text: "snake_case_name 2*3 `a` **b** _c_"}},
+		{&Bold{Children: []Node{&Text{Text: "a*b *c*"}}}, &Text{Text: " d"}},
+		{&Italic{Children: []Node{&Text{Text: "_"}}}},
+	} {
+		assert.Equal(t, nodes, Parse(Render(nodes...)))
+	}
+
+	// The markers which can not style are not escaped
+	assert.Equal(t, "snake_case_name 2*3 a * b", Render(&Text{Text: "snake_case_name 2*3 a * b"}))
+}
+
+func TestPlainText(t *testing.T) {
+	nodes := Parse("*Deploy* of <https://example.com|api> by <@U1|alice> in <#C1>\n> done &amp; dusted")
+	assert.Equal(t, "Deploy of api by @alice in #C1\ndone & dusted", PlainText(nodes...))
+}
@@ -0,0 +1,184 @@
+package mrkdwn
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape escapes the control characters of mrkdwn, so that s is not parsed as links or mentions
+func Escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// joiner is the zero width joiner escaping markers: a marker between joiners joins the words
+// around it, so that it neither opens nor closes a style. Parse removes the joiners.
+const joiner = "\u200d"
+
+const markers = "*_~`"
+
+// escapeMarkers escapes the markers of text which could open or close a style, and its backticks
+func escapeMarkers(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '`' || strings.IndexByte(markers, c) >= 0 && mayStyle(s, i) {
+			sb.WriteString(joiner + string(c) + joiner)
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// mayStyle reports whether the marker at i could open or close a style. The text around s is
+// unknown, so a marker at its start or end could.
+func mayStyle(s string, i int) bool {
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i+1:])
+	opens := (i == 0 || !isWord(before)) && (i+1 == len(s) || !isSpace(s[i+1]))
+	closes := (i == 0 || !isSpace(s[i-1])) && (i+1 == len(s) || !isWord(after))
+	return opens || closes
+}
+
+// escapeBackticks escapes the backticks of code, so that they do not end it
+func escapeBackticks(s string) string {
+	return strings.ReplaceAll(s, "`", joiner+"`"+joiner)
+}
+
+func unescapeBackticks(s string) string {
+	return strings.ReplaceAll(s, joiner+"`"+joiner, "`")
+}
+
+// Render renders the nodes to mrkdwn
+func Render(nodes ...Node) string {
+	var sb strings.Builder
+	render(&sb, nodes)
+	return sb.String()
+}
+
+func render(sb *strings.Builder, nodes []Node) {
+	for i, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			sb.WriteString(escapeMarkers(Escape(n.Text)))
+		case *Bold:
+			renderStyle(sb, "*", n.Children)
+		case *Italic:
+			renderStyle(sb, "_", n.Children)
+		case *Strike:
+			renderStyle(sb, "~", n.Children)
+		case *Code:
+			sb.WriteString("`" + escapeBackticks(Escape(n.Text)) + "`")
+		case *Pre:
+			sb.WriteString(fence + "\n" + escapeBackticks(Escape(n.Text)) + "\n" + fence)
+		case *Quote:
+			// A quote starts and ends a line
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("> " + strings.ReplaceAll(Render(n.Children...), "\n", "\n> "))
+			if i+1 < len(nodes) {
+				sb.WriteString("\n")
+			}
+		case *Link:
+			sb.WriteString(control(n.URL, n.Text))
+		case *UserMention:
+			sb.WriteString(control("@"+n.ID, n.Label))
+		case *ChannelMention:
+			sb.WriteString(control("#"+n.ID, n.Name))
+		case *UsergroupMention:
+			sb.WriteString(control("!subteam^"+n.ID, n.Label))
+		case *SpecialMention:
+			sb.WriteString("<!" + n.Name + ">")
+		case *Date:
+			target := "!date^" + strconv.FormatInt(n.Timestamp, 10) + "^" + n.Format
+			if n.Link != "" {
+				target += "^" + n.Link
+			}
+			sb.WriteString(control(target, n.Fallback))
+		}
+	}
+}
+
+func renderStyle(sb *strings.Builder, marker string, children []Node) {
+	sb.WriteString(marker)
+	render(sb, children)
+	sb.WriteString(marker)
+}
+
+func control(target, label string) string {
+	if label == "" {
+		return "<" + Escape(target) + ">"
+	}
+	return "<" + Escape(target) + "|" + Escape(label) + ">"
+}
+
+// PlainText renders the text of the nodes, without markup. Links are rendered as their text, or
+// URL, and mentions as their label, or ID.
+func PlainText(nodes ...Node) string {
+	var sb strings.Builder
+	plainText(&sb, nodes)
+	return sb.String()
+}
+
+func plainText(sb *strings.Builder, nodes []Node) {
+	for i, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			sb.WriteString(n.Text)
+		case *Bold:
+			plainText(sb, n.Children)
+		case *Italic:
+			plainText(sb, n.Children)
+		case *Strike:
+			plainText(sb, n.Children)
+		case *Code:
+			sb.WriteString(n.Text)
+		case *Pre:
+			sb.WriteString(n.Text)
+		case *Quote:
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteString("\n")
+			}
+			plainText(sb, n.Children)
+			if i+1 < len(nodes) {
+				sb.WriteString("\n")
+			}
+		default:
+			sb.WriteString(mentionText(node))
+		}
+	}
+}
+
+// mentionText returns the text of a link, mention or date
+func mentionText(node Node) string {
+	switch n := node.(type) {
+	case *Link:
+		if n.Text != "" {
+			return n.Text
+		}
+		return strings.TrimPrefix(n.URL, "mailto:")
+	case *UserMention:
+		return "@" + firstNonEmpty(strings.TrimPrefix(n.Label, "@"), n.ID)
+	case *ChannelMention:
+		return "#" + firstNonEmpty(n.Name, n.ID)
+	case *UsergroupMention:
+		return "@" + firstNonEmpty(strings.TrimPrefix(n.Label, "@"), n.ID)
+	case *SpecialMention:
+		return "@" + n.Name
+	case *Date:
+		return n.Fallback
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
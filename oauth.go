@@ -198,3 +198,42 @@ func GetOpenIDConnectTokenContext(ctx context.Context, client httpClient, client
 	}
 	return response, response.Err()
 }
+
+// OpenIDConnectUserInfo is the identity of the user of a Sign in with Slack token.
+type OpenIDConnectUserInfo struct {
+	Sub               string `json:"sub"`
+	UserID            string `json:"https://slack.com/user_id"`
+	TeamID            string `json:"https://slack.com/team_id"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	DateEmailVerified int64  `json:"date_email_verified"`
+	Name              string `json:"name"`
+	Picture           string `json:"picture"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	Locale            string `json:"locale"`
+	TeamName          string `json:"https://slack.com/team_name"`
+	TeamDomain        string `json:"https://slack.com/team_domain"`
+	TeamImage230      string `json:"https://slack.com/team_image_230"`
+	SlackResponse
+}
+
+// GetOpenIDConnectUserInfo returns the identity of the user of the token of the client.
+// For more details, see GetOpenIDConnectUserInfoContext documentation.
+func (api *Client) GetOpenIDConnectUserInfo() (*OpenIDConnectUserInfo, error) {
+	return api.GetOpenIDConnectUserInfoContext(context.Background())
+}
+
+// GetOpenIDConnectUserInfoContext returns the identity of the user of the token of the client
+// with a custom context. The token must be a Sign in with Slack token.
+// Slack API docs: https://api.slack.com/methods/openid.connect.userInfo
+func (api *Client) GetOpenIDConnectUserInfoContext(ctx context.Context) (*OpenIDConnectUserInfo, error) {
+	values := url.Values{
+		"token": {api.token},
+	}
+	response := &OpenIDConnectUserInfo{}
+	if err := api.postMethod(ctx, "openid.connect.userInfo", values, response); err != nil {
+		return nil, err
+	}
+	return response, response.Err()
+}
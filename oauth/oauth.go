@@ -0,0 +1,301 @@
+// Package oauth implements the OAuth v2 installation flow of Slack apps. An Installer
+// provides the two HTTP handlers of the flow: the install handler redirects the user to
+// Slack with the requested scopes, and the callback handler exchanges the code Slack
+// redirects back with for tokens, which are persisted in an InstallationStore.
+//
+// Requests are tied to the browser that started them with a signed, expiring state that is
+// both passed to Slack and set as a cookie, to protect the callback against CSRF.
+package oauth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/internal/errorsx"
+	"github.com/incident-io/slack/internal/httpx"
+	"github.com/incident-io/slack/internal/statecookie"
+)
+
+// Errors passed to the failure handler of the callback.
+const (
+	ErrInvalidState         = errorsx.String("invalid oauth state")
+	ErrExpiredState         = errorsx.String("oauth state expired")
+	ErrMissingCode          = errorsx.String("missing oauth code")
+	ErrInstallationNotFound = errorsx.String("installation not found")
+)
+
+const (
+	defaultAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+	defaultCookieName   = "slack-oauth-state"
+	defaultStateTTL     = 10 * time.Minute
+)
+
+// AuthorizationError is the error Slack redirects to the callback with, e.g. "access_denied"
+// when the user cancels the install.
+type AuthorizationError = statecookie.AuthorizationError
+
+// Installation is the installation of an app in a workspace, or in an organization for
+// org-wide installs, as granted by oauth.v2.access.
+type Installation struct {
+	AppID               string `json:"app_id"`
+	EnterpriseID        string `json:"enterprise_id,omitempty"`
+	EnterpriseName      string `json:"enterprise_name,omitempty"`
+	TeamID              string `json:"team_id,omitempty"`
+	TeamName            string `json:"team_name,omitempty"`
+	IsEnterpriseInstall bool   `json:"is_enterprise_install"`
+
+	BotUserID          string    `json:"bot_user_id,omitempty"`
+	BotToken           string    `json:"bot_token,omitempty"`
+	BotScopes          []string  `json:"bot_scopes,omitempty"`
+	BotRefreshToken    string    `json:"bot_refresh_token,omitempty"`
+	BotTokenExpiresAt  time.Time `json:"bot_token_expires_at"`
+	UserID             string    `json:"user_id,omitempty"`
+	UserToken          string    `json:"user_token,omitempty"`
+	UserScopes         []string  `json:"user_scopes,omitempty"`
+	UserRefreshToken   string    `json:"user_refresh_token,omitempty"`
+	UserTokenExpiresAt time.Time `json:"user_token_expires_at"`
+
+	IncomingWebhook slack.OAuthResponseIncomingWebhook `json:"incoming_webhook"`
+	InstalledAt     time.Time                          `json:"installed_at"`
+}
+
+// NewInstallation returns the installation granted by the response of oauth.v2.access at the time.
+func NewInstallation(resp *slack.OAuthV2Response, at time.Time) Installation {
+	return Installation{
+		AppID:               resp.AppID,
+		EnterpriseID:        resp.Enterprise.ID,
+		EnterpriseName:      resp.Enterprise.Name,
+		TeamID:              resp.Team.ID,
+		TeamName:            resp.Team.Name,
+		IsEnterpriseInstall: resp.IsEnterpriseInstall,
+
+		BotUserID:          resp.BotUserID,
+		BotToken:           resp.AccessToken,
+		BotScopes:          splitScopes(resp.Scope),
+		BotRefreshToken:    resp.RefreshToken,
+		BotTokenExpiresAt:  expiresAt(at, resp.ExpiresIn),
+		UserID:             resp.AuthedUser.ID,
+		UserToken:          resp.AuthedUser.AccessToken,
+		UserScopes:         splitScopes(resp.AuthedUser.Scope),
+		UserRefreshToken:   resp.AuthedUser.RefreshToken,
+		UserTokenExpiresAt: expiresAt(at, resp.AuthedUser.ExpiresIn),
+
+		IncomingWebhook: resp.IncomingWebhook,
+		InstalledAt:     at,
+	}
+}
+
+func splitScopes(scope string) []string {
+	if scope == "" {
+		return nil
+	}
+	return strings.Split(scope, ",")
+}
+
+func expiresAt(at time.Time, expiresIn int) time.Time {
+	if expiresIn <= 0 {
+		return time.Time{}
+	}
+	return at.Add(time.Duration(expiresIn) * time.Second)
+}
+
+// Installer implements the installation flow of an app.
+type Installer struct {
+	clientID     string
+	clientSecret string
+	store        InstallationStore
+
+	scopes       []string
+	userScopes   []string
+	redirectURI  string
+	authorizeURL string
+	httpClient   httpx.Client
+	states       statecookie.States
+
+	success func(w http.ResponseWriter, r *http.Request, installation Installation)
+	failure func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Option configures an Installer.
+type Option func(*Installer)
+
+// OptionScopes sets the bot scopes requested on install
+func OptionScopes(scopes ...string) Option {
+	return func(i *Installer) {
+		i.scopes = scopes
+	}
+}
+
+// OptionUserScopes sets the user scopes requested on install
+func OptionUserScopes(scopes ...string) Option {
+	return func(i *Installer) {
+		i.userScopes = scopes
+	}
+}
+
+// OptionRedirectURI sets the redirect URI of the callback, required when the app has several
+func OptionRedirectURI(uri string) Option {
+	return func(i *Installer) {
+		i.redirectURI = uri
+	}
+}
+
+// OptionAuthorizeURL sets the URL users are redirected to by the install handler, e.g. to
+// install from a fake server in tests
+func OptionAuthorizeURL(u string) Option {
+	return func(i *Installer) {
+		i.authorizeURL = u
+	}
+}
+
+// OptionHTTPClient sets the client the code is exchanged with. Defaults to http.DefaultClient.
+func OptionHTTPClient(client httpx.Client) Option {
+	return func(i *Installer) {
+		i.httpClient = client
+	}
+}
+
+// OptionStateSecret sets the key the states are signed with. It must be shared by all the
+// instances of the app serving the handlers. Defaults to a random key.
+func OptionStateSecret(secret []byte) Option {
+	return func(i *Installer) {
+		i.states.Secret = secret
+	}
+}
+
+// OptionStateTTL sets how long users have to complete the install. Defaults to 10 minutes.
+func OptionStateTTL(d time.Duration) Option {
+	return func(i *Installer) {
+		i.states.TTL = d
+	}
+}
+
+// OptionCookieName sets the name of the state cookie. Defaults to "slack-oauth-state".
+func OptionCookieName(name string) Option {
+	return func(i *Installer) {
+		i.states.CookieName = name
+	}
+}
+
+// OptionInsecureCookie sends the state cookie over plain HTTP too, to develop the app locally
+// without TLS. By default, the cookie is Secure.
+func OptionInsecureCookie() Option {
+	return func(i *Installer) {
+		i.states.Insecure = true
+	}
+}
+
+// OptionSuccessHandler sets the handler responding to a completed install. By default, a
+// plain text page is written.
+func OptionSuccessHandler(f func(w http.ResponseWriter, r *http.Request, installation Installation)) Option {
+	return func(i *Installer) {
+		i.success = f
+	}
+}
+
+// OptionFailureHandler sets the handler responding to a failed install, with one of the errors
+// of the package, an AuthorizationError, or the error of the code exchange or of the store. By
+// default, the error is written with a 400 or 500 status.
+func OptionFailureHandler(f func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(i *Installer) {
+		i.failure = f
+	}
+}
+
+// NewInstaller returns an Installer for the app, persisting the installations in the store
+func NewInstaller(clientID, clientSecret string, store InstallationStore, options ...Option) *Installer {
+	i := &Installer{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		store:        store,
+		authorizeURL: defaultAuthorizeURL,
+		httpClient:   http.DefaultClient,
+		states: statecookie.States{
+			CookieName: defaultCookieName,
+			TTL:        defaultStateTTL,
+			Errors:     statecookie.Errors{InvalidState: ErrInvalidState, ExpiredState: ErrExpiredState, MissingCode: ErrMissingCode},
+		},
+		success: defaultSuccess,
+	}
+	i.failure = i.defaultFailure
+	for _, opt := range options {
+		opt(i)
+	}
+
+	if len(i.states.Secret) == 0 {
+		i.states.Secret = statecookie.NewSecret()
+	}
+	return i
+}
+
+// InstallHandler returns the "Add to Slack" handler, redirecting the user to Slack
+func (i *Installer) InstallHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, err := i.states.Start(w, r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, i.AuthorizeURL(state), http.StatusFound)
+	})
+}
+
+// AuthorizeURL returns the URL of Slack to install the app with the state
+func (i *Installer) AuthorizeURL(state string) string {
+	values := url.Values{
+		"client_id": {i.clientID},
+		"state":     {state},
+	}
+	if len(i.scopes) > 0 {
+		values.Set("scope", strings.Join(i.scopes, ","))
+	}
+	if len(i.userScopes) > 0 {
+		values.Set("user_scope", strings.Join(i.userScopes, ","))
+	}
+	if i.redirectURI != "" {
+		values.Set("redirect_uri", i.redirectURI)
+	}
+	return i.authorizeURL + "?" + values.Encode()
+}
+
+// CallbackHandler returns the handler of the redirect URI, completing the install
+func (i *Installer) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		installation, err := i.complete(w, r)
+		if err != nil {
+			i.failure(w, r, err)
+			return
+		}
+		i.success(w, r, installation)
+	})
+}
+
+func (i *Installer) complete(w http.ResponseWriter, r *http.Request) (Installation, error) {
+	_, code, err := i.states.Callback(w, r)
+	if err != nil {
+		return Installation{}, err
+	}
+
+	resp, err := slack.GetOAuthV2ResponseContext(r.Context(), i.httpClient, i.clientID, i.clientSecret, code, i.redirectURI)
+	if err != nil {
+		return Installation{}, err
+	}
+
+	installation := NewInstallation(resp, time.Now())
+	if err := i.store.Save(r.Context(), installation); err != nil {
+		return Installation{}, err
+	}
+	return installation, nil
+}
+
+func defaultSuccess(w http.ResponseWriter, _ *http.Request, _ Installation) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("The app was installed, you can close this page."))
+}
+
+func (i *Installer) defaultFailure(w http.ResponseWriter, _ *http.Request, err error) {
+	i.states.Failure(w, err, "The app was not installed")
+}
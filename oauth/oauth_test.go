@@ -0,0 +1,171 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/incident-io/slack/slacktest"
+)
+
+func newTestInstaller(t *testing.T, options ...Option) (*Installer, *slacktest.OAuthServer, *MemoryStore) {
+	o := slacktest.NewOAuthServer()
+	o.AddApp(slacktest.OAuthApp{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"chat:write", "commands"},
+		UserScopes:   []string{"search:read"},
+	})
+
+	s := slacktest.NewTestServer(o.Bind)
+	go s.Start()
+	t.Cleanup(s.Stop)
+
+	store := NewMemoryStore()
+	options = append([]Option{
+		OptionScopes("chat:write", "commands"),
+		OptionUserScopes("search:read"),
+		OptionHTTPClient(s.HTTPClient()),
+	}, options...)
+	return NewInstaller("client", "secret", store, options...), o, store
+}
+
+// install starts an install, and returns the state cookie
+func install(t *testing.T, installer *Installer) *http.Cookie {
+	rec := httptest.NewRecorder()
+	installer.InstallHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slack/install", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "slack.com", location.Host)
+	assert.Equal(t, "chat:write,commands", location.Query().Get("scope"))
+	assert.Equal(t, "search:read", location.Query().Get("user_scope"))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, location.Query().Get("state"), cookies[0].Value)
+	return cookies[0]
+}
+
+func callback(installer *Installer, query url.Values, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/slack/oauth_redirect?"+query.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	installer.CallbackHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestInstallFlow(t *testing.T) {
+	installer, o, store := newTestInstaller(t)
+
+	cookie := install(t, installer)
+	code := o.Authorize(slacktest.OAuthGrant{ClientID: "client", UserID: "U1"})
+
+	rec := callback(installer, url.Values{"code": {code}, "state": {cookie.Value}}, cookie)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	installation, err := store.Find(context.Background(), "", "T024BE7LD", "")
+	require.NoError(t, err)
+	assert.True(t, o.TokenActive(installation.BotToken))
+	assert.Equal(t, []string{"chat:write", "commands"}, installation.BotScopes)
+	assert.Equal(t, "U1", installation.UserID)
+
+	user, err := store.Find(context.Background(), "", "T024BE7LD", "U1")
+	require.NoError(t, err)
+	assert.True(t, o.TokenActive(user.UserToken))
+}
+
+func TestInsecureCookie(t *testing.T) {
+	for _, insecure := range []bool{false, true} {
+		var options []Option
+		if insecure {
+			options = append(options, OptionInsecureCookie())
+		}
+		installer := NewInstaller("client", "secret", NewMemoryStore(), options...)
+
+		rec := httptest.NewRecorder()
+		installer.InstallHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slack/install", nil))
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, !insecure, cookies[0].Secure)
+	}
+}
+
+func TestInstallCallbackFailures(t *testing.T) {
+	var failure error
+	installer, _, _ := newTestInstaller(t, OptionFailureHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		failure = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	forged, err := NewInstaller("client", "secret", NewMemoryStore()).states.New(time.Now())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    func(cookie *http.Cookie) url.Values
+		cookie   func(cookie *http.Cookie) *http.Cookie
+		expected error
+	}{
+		{
+			name:     "missing cookie",
+			query:    func(c *http.Cookie) url.Values { return url.Values{"code": {"c"}, "state": {c.Value}} },
+			cookie:   func(c *http.Cookie) *http.Cookie { return nil },
+			expected: ErrInvalidState,
+		},
+		{
+			name:     "state mismatch",
+			query:    func(c *http.Cookie) url.Values { return url.Values{"code": {"c"}, "state": {"other"}} },
+			cookie:   func(c *http.Cookie) *http.Cookie { return c },
+			expected: ErrInvalidState,
+		},
+		{
+			name:     "forged state",
+			query:    func(c *http.Cookie) url.Values { return url.Values{"code": {"c"}, "state": {forged}} },
+			cookie:   func(c *http.Cookie) *http.Cookie { return &http.Cookie{Name: defaultCookieName, Value: forged} },
+			expected: ErrInvalidState,
+		},
+		{
+			name:     "access denied",
+			query:    func(c *http.Cookie) url.Values { return url.Values{"error": {"access_denied"}, "state": {c.Value}} },
+			cookie:   func(c *http.Cookie) *http.Cookie { return c },
+			expected: AuthorizationError{Code: "access_denied"},
+		},
+		{
+			name:     "invalid code",
+			query:    func(c *http.Cookie) url.Values { return url.Values{"code": {"unknown"}, "state": {c.Value}} },
+			cookie:   func(c *http.Cookie) *http.Cookie { return c },
+			expected: errors.New("invalid_code"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure = nil
+			cookie := install(t, installer)
+			rec := callback(installer, tt.query(cookie), tt.cookie(cookie))
+			assert.Equal(t, http.StatusTeapot, rec.Code)
+			assert.EqualError(t, failure, tt.expected.Error())
+		})
+	}
+}
+
+func TestStateExpiry(t *testing.T) {
+	installer := NewInstaller("client", "secret", NewMemoryStore(), OptionStateTTL(time.Minute))
+
+	now := time.Now()
+	state, err := installer.states.New(now)
+	require.NoError(t, err)
+	assert.NoError(t, installer.states.Verify(state, now.Add(30*time.Second)))
+	assert.Equal(t, ErrExpiredState, installer.states.Verify(state, now.Add(2*time.Minute)))
+	assert.Equal(t, ErrInvalidState, installer.states.Verify(state+"x", now))
+}
@@ -0,0 +1,312 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/internal/httpx"
+)
+
+const (
+	defaultRotationMargin   = 2 * time.Hour
+	defaultRotationInterval = 5 * time.Minute
+)
+
+// InstallationLister is implemented by the stores that can list the installations of the
+// workspaces and organizations, without the ones of the users. Rotator.Run requires it.
+type InstallationLister interface {
+	List(ctx context.Context) ([]Installation, error)
+}
+
+// RotationEventType is the type of the events of a Rotator.
+type RotationEventType string
+
+const (
+	// RotationEventRotated is sent when the tokens of an installation were refreshed and saved
+	RotationEventRotated = RotationEventType("rotated")
+	// RotationEventRevoked is sent when Slack refuses to refresh the tokens of an installation,
+	// with tokens_revoked or invalid_refresh_token: the app must be installed again.
+	RotationEventRevoked = RotationEventType("revoked")
+	// RotationEventFailed is sent when the tokens could not be refreshed, e.g. because Slack was
+	// unreachable. The refresh is tried again on the next use of the installation.
+	RotationEventFailed = RotationEventType("failed")
+)
+
+// RotationEvent reports the refresh of the tokens of an installation.
+type RotationEvent struct {
+	Type RotationEventType
+	// Installation is the rotated installation, or the one that failed to rotate
+	Installation Installation
+	Err          error
+}
+
+// Rotator refreshes the expiring tokens of the installations of apps with token rotation
+// enabled. The tokens are refreshed a margin before they expire, by Find when the app uses
+// an installation, and in the background by Run.
+type Rotator struct {
+	clientID     string
+	clientSecret string
+	store        InstallationStore
+	httpClient   httpx.Client
+	margin       time.Duration
+	interval     time.Duration
+
+	// Events receives the events of the rotations. They are dropped when it is full.
+	Events chan RotationEvent
+
+	mu sync.Mutex
+	// locks serialize the refreshes of each workspace, keyed without user: the user token of the
+	// installer is saved under both the workspace and the user, so that a refresh token is only
+	// used once
+	locks map[installationKey]*rotationLock
+	// revoked are the refresh tokens Slack refused, not to try them again
+	revoked map[string]struct{}
+}
+
+// rotationLock is held while refreshing the tokens of a workspace or its users
+type rotationLock struct {
+	held chan struct{}
+	// refs counts the holders and waiters, to forget the lock once unused
+	refs int
+}
+
+// RotatorOption configures a Rotator.
+type RotatorOption func(*Rotator)
+
+// RotatorOptionMargin sets how long before they expire the tokens are refreshed. Defaults to 2 hours.
+func RotatorOptionMargin(d time.Duration) RotatorOption {
+	return func(r *Rotator) {
+		r.margin = d
+	}
+}
+
+// RotatorOptionInterval sets how often Run looks for expiring tokens. Defaults to 5 minutes.
+func RotatorOptionInterval(d time.Duration) RotatorOption {
+	return func(r *Rotator) {
+		r.interval = d
+	}
+}
+
+// RotatorOptionHTTPClient sets the client the tokens are refreshed with. Defaults to http.DefaultClient.
+func RotatorOptionHTTPClient(client httpx.Client) RotatorOption {
+	return func(r *Rotator) {
+		r.httpClient = client
+	}
+}
+
+// NewRotator returns a Rotator for the installations of the app in the store
+func NewRotator(clientID, clientSecret string, store InstallationStore, options ...RotatorOption) *Rotator {
+	r := &Rotator{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		store:        store,
+		httpClient:   http.DefaultClient,
+		margin:       defaultRotationMargin,
+		interval:     defaultRotationInterval,
+		Events:       make(chan RotationEvent, 50),
+		locks:        map[installationKey]*rotationLock{},
+		revoked:      map[string]struct{}{},
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// Find returns the installation from the store, as InstallationStore.Find does, after refreshing
+// its tokens if they expire within the margin. The bot token is the one of the workspace.
+func (r *Rotator) Find(ctx context.Context, enterpriseID, teamID, userID string) (Installation, error) {
+	installation, err := r.store.Find(ctx, enterpriseID, teamID, "")
+	if err != nil && (userID == "" || !errors.Is(err, ErrInstallationNotFound)) {
+		return Installation{}, err
+	}
+	if err == nil {
+		if installation, err = r.rotate(ctx, installation.keys()[0]); err != nil {
+			return Installation{}, err
+		}
+	}
+	if userID == "" {
+		return installation, nil
+	}
+
+	user, err := r.store.Find(ctx, enterpriseID, teamID, userID)
+	if err != nil {
+		return Installation{}, err
+	}
+	key := user.keys()[0]
+	key.UserID = userID
+	return r.rotate(ctx, key)
+}
+
+// Run refreshes the expiring tokens of the installations listed by the store every interval,
+// until the context is cancelled. The tokens of the users that are not the installer of a
+// workspace are only refreshed by Find.
+func (r *Rotator) Run(ctx context.Context) error {
+	lister, ok := r.store.(InstallationLister)
+	if !ok {
+		return fmt.Errorf("the installation store %T can't list its installations", r.store)
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		installations, err := lister.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, installation := range installations {
+			// Failures are reported as events, and tried again on the next tick
+			_, _ = r.rotate(ctx, installation.keys()[0])
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// rotate refreshes the tokens of the installation of the key, after the refreshes in flight for
+// the same workspace. As refresh reloads the installation, it then finds the tokens they rotated.
+func (r *Rotator) rotate(ctx context.Context, key installationKey) (Installation, error) {
+	workspace := key
+	workspace.UserID = ""
+
+	r.mu.Lock()
+	lock, ok := r.locks[workspace]
+	if !ok {
+		lock = &rotationLock{held: make(chan struct{}, 1)}
+		r.locks[workspace] = lock
+	}
+	lock.refs++
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(r.locks, workspace)
+		}
+		r.mu.Unlock()
+	}()
+
+	select {
+	case lock.held <- struct{}{}:
+	case <-ctx.Done():
+		return Installation{}, ctx.Err()
+	}
+	defer func() { <-lock.held }()
+
+	return r.refresh(ctx, key)
+}
+
+// refresh reloads the installation, as it may have been rotated since it was read, and refreshes
+// its expiring tokens. The bot token of a user installation is the one of the workspace.
+func (r *Rotator) refresh(ctx context.Context, key installationKey) (Installation, error) {
+	installation, err := r.store.Find(ctx, key.EnterpriseID, key.TeamID, key.UserID)
+	if err != nil {
+		return Installation{}, err
+	}
+
+	now := time.Now()
+	rotated := false
+	// installer is whether the installation is the one of the workspace, which is saved under
+	// both the workspace and its installing user
+	installer := true
+	if key.UserID != "" {
+		workspace, err := r.store.Find(ctx, key.EnterpriseID, key.TeamID, "")
+		installer = err == nil && workspace.UserID == key.UserID
+		if err == nil {
+			installation.BotToken = workspace.BotToken
+			installation.BotRefreshToken = workspace.BotRefreshToken
+			installation.BotTokenExpiresAt = workspace.BotTokenExpiresAt
+		}
+	} else if r.expiring(installation.BotRefreshToken, installation.BotTokenExpiresAt, now) {
+		resp, err := r.refreshToken(ctx, installation, installation.BotRefreshToken)
+		if err != nil {
+			return installation, err
+		}
+		installation.BotToken = resp.AccessToken
+		installation.BotRefreshToken = resp.RefreshToken
+		installation.BotTokenExpiresAt = expiresAt(now, resp.ExpiresIn)
+		rotated = true
+	}
+
+	if r.expiring(installation.UserRefreshToken, installation.UserTokenExpiresAt, now) {
+		resp, err := r.refreshToken(ctx, installation, installation.UserRefreshToken)
+		if err != nil {
+			// The refresh token of the rotated bot token is already used, so it must be saved
+			if rotated {
+				_ = r.save(ctx, installation, installer)
+			}
+			return installation, err
+		}
+		installation.UserToken = resp.AccessToken
+		installation.UserRefreshToken = resp.RefreshToken
+		installation.UserTokenExpiresAt = expiresAt(now, resp.ExpiresIn)
+		rotated = true
+	}
+
+	if !rotated {
+		return installation, nil
+	}
+	return installation, r.save(ctx, installation, installer)
+}
+
+// save saves the rotated installation. The installation of a user who is not the installer of
+// the workspace is saved without the bot token, so that it does not replace the one of the
+// workspace.
+func (r *Rotator) save(ctx context.Context, installation Installation, installer bool) error {
+	saved := installation
+	if !installer {
+		saved.BotToken, saved.BotRefreshToken, saved.BotTokenExpiresAt = "", "", time.Time{}
+	}
+	if err := r.store.Save(ctx, saved); err != nil {
+		r.emit(RotationEvent{Type: RotationEventFailed, Installation: installation, Err: err})
+		return err
+	}
+	r.emit(RotationEvent{Type: RotationEventRotated, Installation: installation})
+	return nil
+}
+
+func (r *Rotator) expiring(refreshToken string, expiresAt time.Time, now time.Time) bool {
+	if refreshToken == "" || expiresAt.IsZero() {
+		return false
+	}
+	r.mu.Lock()
+	_, revoked := r.revoked[refreshToken]
+	r.mu.Unlock()
+	return !revoked && !now.Add(r.margin).Before(expiresAt)
+}
+
+func (r *Rotator) refreshToken(ctx context.Context, installation Installation, refreshToken string) (*slack.OAuthV2Response, error) {
+	resp, err := slack.RefreshOAuthV2TokenContext(ctx, r.httpClient, r.clientID, r.clientSecret, refreshToken)
+	if err == nil {
+		return resp, nil
+	}
+
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) && (slackErr.Err == "tokens_revoked" || slackErr.Err == "invalid_refresh_token") {
+		r.mu.Lock()
+		r.revoked[refreshToken] = struct{}{}
+		r.mu.Unlock()
+		r.emit(RotationEvent{Type: RotationEventRevoked, Installation: installation, Err: err})
+	} else {
+		r.emit(RotationEvent{Type: RotationEventFailed, Installation: installation, Err: err})
+	}
+	return nil, fmt.Errorf("refreshing the tokens of team %s: %w", installation.TeamID, err)
+}
+
+func (r *Rotator) emit(evt RotationEvent) {
+	select {
+	case r.Events <- evt:
+	default:
+	}
+}
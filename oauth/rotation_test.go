@@ -0,0 +1,228 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/internal/httpx"
+	"github.com/incident-io/slack/slacktest"
+)
+
+func newRotationTest(t *testing.T) (*slacktest.OAuthServer, *slacktest.Server, *MemoryStore, Installation) {
+	o := slacktest.NewOAuthServer()
+	o.AddApp(slacktest.OAuthApp{
+		ClientID:      "client",
+		ClientSecret:  "secret",
+		Scopes:        []string{"chat:write"},
+		UserScopes:    []string{"search:read"},
+		TokenRotation: true,
+	})
+
+	s := slacktest.NewTestServer(o.Bind)
+	go s.Start()
+	t.Cleanup(s.Stop)
+
+	code := o.Authorize(slacktest.OAuthGrant{ClientID: "client", UserID: "U1"})
+	resp, err := slack.GetOAuthV2Response(s.HTTPClient(), "client", "secret", code, "")
+	require.NoError(t, err)
+
+	store := NewMemoryStore()
+	installation := NewInstallation(resp, time.Now())
+	require.NoError(t, store.Save(context.Background(), installation))
+	return o, s, store, installation
+}
+
+func TestRotatorFind(t *testing.T) {
+	o, s, store, installation := newRotationTest(t)
+	ctx := context.Background()
+
+	fresh := NewRotator("client", "secret", store, RotatorOptionHTTPClient(s.HTTPClient()))
+	found, err := fresh.Find(ctx, "", installation.TeamID, "")
+	require.NoError(t, err)
+	assert.Equal(t, installation.BotToken, found.BotToken, "tokens should not be refreshed before the margin")
+
+	rotator := NewRotator("client", "secret", store, RotatorOptionHTTPClient(s.HTTPClient()), RotatorOptionMargin(13*time.Hour))
+	found, err = rotator.Find(ctx, "", installation.TeamID, "U1")
+	require.NoError(t, err)
+	assert.NotEqual(t, installation.BotToken, found.BotToken)
+	assert.NotEqual(t, installation.UserToken, found.UserToken)
+	assert.True(t, o.TokenActive(found.BotToken))
+	assert.True(t, o.TokenActive(found.UserToken))
+
+	saved, err := store.Find(ctx, "", installation.TeamID, "")
+	require.NoError(t, err)
+	assert.Equal(t, found.BotRefreshToken, saved.BotRefreshToken, "the rotated tokens should be saved")
+
+	evt := <-rotator.Events
+	assert.Equal(t, RotationEventRotated, evt.Type)
+}
+
+func TestRotatorSingleFlight(t *testing.T) {
+	o, s, store, installation := newRotationTest(t)
+	rotator := NewRotator("client", "secret", store, RotatorOptionHTTPClient(s.HTTPClient()), RotatorOptionMargin(13*time.Hour))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found, err := rotator.Find(context.Background(), "", installation.TeamID, "")
+			if err == nil && !o.TokenActive(found.BotToken) {
+				t.Errorf("expected an active token")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err, "refresh tokens should never be used twice")
+	}
+}
+
+func TestRotatorRevoked(t *testing.T) {
+	_, s, store, installation := newRotationTest(t)
+
+	api := slack.New(installation.BotToken, slack.OptionAPIURL(s.GetAPIURL()))
+	_, err := api.SendAuthRevoke("")
+	require.NoError(t, err)
+
+	rotator := NewRotator("client", "secret", store, RotatorOptionHTTPClient(s.HTTPClient()), RotatorOptionMargin(13*time.Hour))
+	_, err = rotator.Find(context.Background(), "", installation.TeamID, "")
+	assert.Error(t, err)
+
+	evt := <-rotator.Events
+	assert.Equal(t, RotationEventRevoked, evt.Type)
+	assert.EqualError(t, evt.Err, "invalid_refresh_token")
+
+	found, err := rotator.Find(context.Background(), "", installation.TeamID, "")
+	assert.NoError(t, err, "revoked refresh tokens should not be tried again")
+	assert.Equal(t, installation.BotToken, found.BotToken)
+}
+
+func TestRotatorRun(t *testing.T) {
+	_, s, store, installation := newRotationTest(t)
+	rotator := NewRotator("client", "secret", store,
+		RotatorOptionHTTPClient(s.HTTPClient()), RotatorOptionMargin(13*time.Hour), RotatorOptionInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- rotator.Run(ctx) }()
+
+	select {
+	case evt := <-rotator.Events:
+		assert.Equal(t, RotationEventRotated, evt.Type)
+		assert.NotEqual(t, installation.BotToken, evt.Installation.BotToken)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the installation to be rotated")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestRotatorConcurrentFindAndRun(t *testing.T) {
+	_, s, store, installation := newRotationTest(t)
+	// Every use refreshes the tokens, as they expire within the margin
+	rotator := NewRotator("client", "secret", store,
+		RotatorOptionHTTPClient(s.HTTPClient()), RotatorOptionMargin(13*time.Hour), RotatorOptionInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- rotator.Run(ctx) }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := rotator.Find(ctx, "", installation.TeamID, "U1")
+				assert.NoError(t, err, "the user refresh token should never be used twice")
+			}
+		}()
+	}
+	wg.Wait()
+	cancel()
+	assert.NoError(t, <-done)
+
+	close(rotator.Events)
+	for evt := range rotator.Events {
+		assert.Equal(t, RotationEventRotated, evt.Type, "%v", evt.Err)
+	}
+}
+
+// failingRefresh fails the refreshes of a refresh token
+type failingRefresh struct {
+	client       httpx.Client
+	refreshToken string
+}
+
+func (c failingRefresh) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if form, _ := url.ParseQuery(string(body)); form.Get("refresh_token") == c.refreshToken {
+		return nil, errors.New("connection reset")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return c.client.Do(req)
+}
+
+func TestRotatorSavesBotRotationWhenUserRefreshFails(t *testing.T) {
+	o, s, store, installation := newRotationTest(t)
+	client := failingRefresh{client: s.HTTPClient(), refreshToken: installation.UserRefreshToken}
+	rotator := NewRotator("client", "secret", store, RotatorOptionHTTPClient(client), RotatorOptionMargin(13*time.Hour))
+
+	_, err := rotator.Find(context.Background(), "", installation.TeamID, "")
+	assert.Error(t, err)
+
+	saved, err := store.Find(context.Background(), "", installation.TeamID, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, installation.BotRefreshToken, saved.BotRefreshToken, "the rotated bot token should be saved")
+	assert.True(t, o.TokenActive(saved.BotToken))
+	assert.Equal(t, installation.UserRefreshToken, saved.UserRefreshToken)
+}
+
+func TestRotatorFindUserKeepsWorkspace(t *testing.T) {
+	o, s, store, installation := newRotationTest(t)
+	ctx := context.Background()
+
+	// U2 authorizes the app after U1 installed it: the workspace stays the one of U1
+	code := o.Authorize(slacktest.OAuthGrant{ClientID: "client", UserID: "U2"})
+	resp, err := slack.GetOAuthV2Response(s.HTTPClient(), "client", "secret", code, "")
+	require.NoError(t, err)
+	user := NewInstallation(resp, time.Now())
+	user.BotToken, user.BotRefreshToken, user.BotTokenExpiresAt = "", "", time.Time{}
+	require.NoError(t, store.Save(ctx, user))
+
+	rotator := NewRotator("client", "secret", store, RotatorOptionHTTPClient(s.HTTPClient()), RotatorOptionMargin(13*time.Hour))
+	found, err := rotator.Find(ctx, "", installation.TeamID, "U2")
+	require.NoError(t, err)
+	assert.Equal(t, "U2", found.UserID)
+	assert.NotEqual(t, user.UserToken, found.UserToken)
+	assert.NotEmpty(t, found.BotToken, "the bot token should be the one of the workspace")
+
+	workspace, err := store.Find(ctx, "", installation.TeamID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "U1", workspace.UserID, "the workspace should not be replaced by the user")
+	assert.Equal(t, found.BotToken, workspace.BotToken)
+
+	saved, err := store.Find(ctx, "", installation.TeamID, "U2")
+	require.NoError(t, err)
+	assert.Equal(t, found.UserToken, saved.UserToken)
+}
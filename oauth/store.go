@@ -0,0 +1,257 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// InstallationStore persists the installations of an app. Installations are keyed by
+// enterprise, team and user: the installation of a workspace has no user, and an org-wide
+// install has no team.
+type InstallationStore interface {
+	// Save stores the installation of the workspace when it has a bot token, and the one of
+	// the installing user when it has a user token.
+	Save(ctx context.Context, installation Installation) error
+	// Find returns the installation of the workspace when userID is empty, or the one of the
+	// user otherwise. The org-wide install of the enterprise is returned when the team has none.
+	// It returns ErrInstallationNotFound when there is no such installation.
+	Find(ctx context.Context, enterpriseID, teamID, userID string) (Installation, error)
+	// Delete removes the installation of the workspace when userID is empty, or the one of
+	// the user otherwise.
+	Delete(ctx context.Context, enterpriseID, teamID, userID string) error
+}
+
+type installationKey struct {
+	EnterpriseID string
+	TeamID       string
+	UserID       string
+}
+
+// keys returns the keys the installation is saved under. An installation with only a user token,
+// e.g. the one of a user who is not the installer, does not replace the one of the workspace.
+func (i Installation) keys() []installationKey {
+	workspace := installationKey{EnterpriseID: i.EnterpriseID, TeamID: i.TeamID}
+	if i.IsEnterpriseInstall {
+		workspace.TeamID = ""
+	}
+
+	user := i.UserToken != "" && i.UserID != ""
+	var keys []installationKey
+	if i.BotToken != "" || !user {
+		keys = append(keys, workspace)
+	}
+	if user {
+		key := workspace
+		key.UserID = i.UserID
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// lookups returns the keys to try, in order, to find an installation
+func lookups(enterpriseID, teamID, userID string) []installationKey {
+	keys := []installationKey{{EnterpriseID: enterpriseID, TeamID: teamID, UserID: userID}}
+	if enterpriseID != "" && teamID != "" {
+		keys = append(keys, installationKey{EnterpriseID: enterpriseID, UserID: userID})
+	}
+	return keys
+}
+
+// MemoryStore is an InstallationStore in memory, for tests and single process apps.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	installations map[installationKey]Installation
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{installations: map[installationKey]Installation{}}
+}
+
+// Save implements InstallationStore
+func (s *MemoryStore) Save(_ context.Context, installation Installation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range installation.keys() {
+		s.installations[key] = installation
+	}
+	return nil
+}
+
+// Find implements InstallationStore
+func (s *MemoryStore) Find(_ context.Context, enterpriseID, teamID, userID string) (Installation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range lookups(enterpriseID, teamID, userID) {
+		if installation, ok := s.installations[key]; ok {
+			return installation, nil
+		}
+	}
+	return Installation{}, ErrInstallationNotFound
+}
+
+// List implements InstallationLister
+func (s *MemoryStore) List(_ context.Context) ([]Installation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var installations []Installation
+	for key, installation := range s.installations {
+		if key.UserID == "" {
+			installations = append(installations, installation)
+		}
+	}
+	return installations, nil
+}
+
+// Delete implements InstallationStore
+func (s *MemoryStore) Delete(_ context.Context, enterpriseID, teamID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.installations, installationKey{EnterpriseID: enterpriseID, TeamID: teamID, UserID: userID})
+	return nil
+}
+
+// FileStore is an InstallationStore writing one JSON file per installation in a directory.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore returns a FileStore in the directory, which is created if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save implements InstallationStore
+func (s *FileStore) Save(_ context.Context, installation Installation) error {
+	b, err := json.MarshalIndent(installation, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range installation.keys() {
+		if err := s.write(key, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find implements InstallationStore
+func (s *FileStore) Find(_ context.Context, enterpriseID, teamID, userID string) (Installation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range lookups(enterpriseID, teamID, userID) {
+		b, err := os.ReadFile(s.path(key))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Installation{}, err
+		}
+
+		var installation Installation
+		if err := json.Unmarshal(b, &installation); err != nil {
+			return Installation{}, err
+		}
+		return installation, nil
+	}
+	return Installation{}, ErrInstallationNotFound
+}
+
+// List implements InstallationLister
+func (s *FileStore) List(_ context.Context) ([]Installation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, workspaceFiles))
+	if err != nil {
+		return nil, err
+	}
+
+	installations := make([]Installation, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var installation Installation
+		if err := json.Unmarshal(b, &installation); err != nil {
+			return nil, err
+		}
+		installations = append(installations, installation)
+	}
+	return installations, nil
+}
+
+// Delete implements InstallationStore
+func (s *FileStore) Delete(_ context.Context, enterpriseID, teamID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(installationKey{EnterpriseID: enterpriseID, TeamID: teamID, UserID: userID}))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// write replaces the file of the key atomically, so that a crash never leaves a partial installation
+func (s *FileStore) write(key installationKey, b []byte) error {
+	tmp, err := os.CreateTemp(s.dir, ".installation-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+func (s *FileStore) path(key installationKey) string {
+	name := fileID(key.EnterpriseID) + fileSeparator + fileID(key.TeamID) + fileSeparator + fileID(key.UserID)
+	return filepath.Join(s.dir, name+".json")
+}
+
+const (
+	// fileSeparator separates the IDs in file names. It is always escaped in the IDs.
+	fileSeparator = ","
+	// noID stands for an empty ID in file names, e.g. the user of a workspace installation
+	noID = "none"
+	// workspaceFiles matches the files of the installations without a user
+	workspaceFiles = "*" + fileSeparator + noID + ".json"
+)
+
+// fileID returns the ID as written in file names. An ID that is literally noID is written
+// with its first letter percent-encoded, so that it is never mistaken for an empty ID.
+func fileID(id string) string {
+	switch id {
+	case "":
+		return noID
+	case noID:
+		return "%6Eone"
+	}
+	return url.PathEscape(id)
+}
@@ -0,0 +1,98 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]InstallationStore{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			installedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			workspace := Installation{TeamID: "T1", BotToken: "xoxb-1", UserID: "U1", UserToken: "xoxp-1", InstalledAt: installedAt}
+			require.NoError(t, store.Save(ctx, workspace))
+			org := Installation{EnterpriseID: "E1", IsEnterpriseInstall: true, BotToken: "xoxb-org", InstalledAt: installedAt}
+			require.NoError(t, store.Save(ctx, org))
+
+			found, err := store.Find(ctx, "", "T1", "")
+			require.NoError(t, err)
+			assert.Equal(t, workspace, found)
+
+			found, err = store.Find(ctx, "", "T1", "U1")
+			require.NoError(t, err)
+			assert.Equal(t, "xoxp-1", found.UserToken)
+
+			_, err = store.Find(ctx, "", "T1", "U2")
+			assert.Equal(t, ErrInstallationNotFound, err)
+
+			found, err = store.Find(ctx, "E1", "T2", "")
+			require.NoError(t, err, "the org-wide install should apply to the teams of the enterprise")
+			assert.Equal(t, "xoxb-org", found.BotToken)
+
+			require.NoError(t, store.Delete(ctx, "", "T1", ""))
+			_, err = store.Find(ctx, "", "T1", "")
+			assert.Equal(t, ErrInstallationNotFound, err)
+			require.NoError(t, store.Delete(ctx, "", "T1", ""), "deleting twice should not fail")
+		})
+	}
+}
+
+func TestInstallationStoresList(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	stores := map[string]interface {
+		InstallationStore
+		InstallationLister
+	}{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// A user ID literally equal to "none" must not be listed as a workspace installation
+			workspace := Installation{TeamID: "T1", BotToken: "xoxb-1", UserID: "none", UserToken: "xoxp-1"}
+			require.NoError(t, store.Save(ctx, workspace))
+
+			installations, err := store.List(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []Installation{workspace}, installations)
+
+			found, err := store.Find(ctx, "", "T1", "none")
+			require.NoError(t, err)
+			assert.Equal(t, "xoxp-1", found.UserToken)
+
+			// Nor a user ID ending like a workspace installation, and IDs joined differently
+			// are different installations
+			user := Installation{TeamID: "T1", UserID: "U-none", UserToken: "xoxp-2"}
+			require.NoError(t, store.Save(ctx, user))
+			enterprise := Installation{EnterpriseID: "E-T1", BotToken: "xoxb-2"}
+			require.NoError(t, store.Save(ctx, enterprise))
+
+			installations, err = store.List(ctx)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []Installation{workspace, enterprise}, installations)
+
+			found, err = store.Find(ctx, "", "T1", "U-none")
+			require.NoError(t, err)
+			assert.Equal(t, "xoxp-2", found.UserToken)
+			_, err = store.Find(ctx, "E", "T1-", "")
+			assert.Equal(t, ErrInstallationNotFound, err)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package openid
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/incident-io/slack/internal/httpx"
+)
+
+// JWKSURL is the URL of the keys Slack signs the id_tokens with
+const JWKSURL = "https://slack.com/openid/connect/keys"
+
+// minRefreshInterval limits how often the keys are fetched again for an unknown key ID
+const minRefreshInterval = time.Minute
+
+// KeyFetcher returns the public key of a key ID.
+type KeyFetcher interface {
+	Key(ctx context.Context, keyID string) (*rsa.PublicKey, error)
+}
+
+// StaticKeys is a KeyFetcher of fixed keys, e.g. the key of slacktest.OAuthServer in tests.
+type StaticKeys map[string]*rsa.PublicKey
+
+// Key implements KeyFetcher
+func (k StaticKeys) Key(_ context.Context, keyID string) (*rsa.PublicKey, error) {
+	if key, ok := k[keyID]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown id_token key %q", keyID)
+}
+
+// JWKSFetcher is a KeyFetcher of the JSON Web Key Set of Slack. The keys are cached, and
+// fetched again when an unknown key ID is requested, as Slack rotates its keys.
+type JWKSFetcher struct {
+	client httpx.Client
+	url    string
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKSFetcher returns a JWKSFetcher of the keys at JWKSURL, fetched with the client
+func NewJWKSFetcher(client httpx.Client) *JWKSFetcher {
+	return &JWKSFetcher{client: client, url: JWKSURL}
+}
+
+// Key implements KeyFetcher
+func (f *JWKSFetcher) Key(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key, ok := f.keys[keyID]; ok {
+		return key, nil
+	}
+	if time.Since(f.fetchedAt) < minRefreshInterval {
+		return nil, fmt.Errorf("unknown id_token key %q", keyID)
+	}
+
+	keys, err := f.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f.keys, f.fetchedAt = keys, time.Now()
+
+	if key, ok := f.keys[keyID]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown id_token key %q", keyID)
+}
+
+func (f *JWKSFetcher) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the id_token keys: %s", resp.Status)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
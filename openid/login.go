@@ -0,0 +1,226 @@
+package openid
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/internal/errorsx"
+	"github.com/incident-io/slack/internal/httpx"
+	"github.com/incident-io/slack/internal/statecookie"
+)
+
+// Errors passed to the failure handler of the callback.
+const (
+	ErrInvalidState = errorsx.String("invalid sign in state")
+	ErrExpiredState = errorsx.String("sign in state expired")
+	ErrMissingCode  = errorsx.String("missing sign in code")
+)
+
+const (
+	defaultAuthorizeURL = "https://slack.com/openid/connect/authorize"
+	defaultCookieName   = "slack-openid-state"
+	defaultStateTTL     = 10 * time.Minute
+)
+
+// AuthorizationError is the error Slack redirects to the callback with, e.g. "access_denied"
+// when the user cancels the sign in.
+type AuthorizationError = statecookie.AuthorizationError
+
+// Login implements the Sign in with Slack flow. The state of a sign in is set as a cookie,
+// and the nonce of its id_token is derived from it, so that no server side storage is needed.
+type Login struct {
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	teamID       string
+	authorizeURL string
+	httpClient   httpx.Client
+	verifier     *Verifier
+	states       statecookie.States
+
+	success func(w http.ResponseWriter, r *http.Request, claims *Claims, token *slack.OpenIDConnectResponse)
+	failure func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// LoginOption configures a Login.
+type LoginOption func(*Login)
+
+// LoginOptionRedirectURI sets the redirect URI of the callback, required when the app has several
+func LoginOptionRedirectURI(uri string) LoginOption {
+	return func(l *Login) {
+		l.redirectURI = uri
+	}
+}
+
+// LoginOptionTeam sets the workspace users sign in to, skipping the workspace selection
+func LoginOptionTeam(teamID string) LoginOption {
+	return func(l *Login) {
+		l.teamID = teamID
+	}
+}
+
+// LoginOptionAuthorizeURL sets the URL users are redirected to by the login handler
+func LoginOptionAuthorizeURL(u string) LoginOption {
+	return func(l *Login) {
+		l.authorizeURL = u
+	}
+}
+
+// LoginOptionHTTPClient sets the client the code is exchanged with, and the keys of Slack are
+// fetched with unless a Verifier is set. Defaults to http.DefaultClient.
+func LoginOptionHTTPClient(client httpx.Client) LoginOption {
+	return func(l *Login) {
+		l.httpClient = client
+	}
+}
+
+// LoginOptionVerifier sets the Verifier of the id_tokens
+func LoginOptionVerifier(v *Verifier) LoginOption {
+	return func(l *Login) {
+		l.verifier = v
+	}
+}
+
+// LoginOptionStateSecret sets the key the states are signed with. It must be shared by all the
+// instances of the app serving the handlers. Defaults to a random key.
+func LoginOptionStateSecret(secret []byte) LoginOption {
+	return func(l *Login) {
+		l.states.Secret = secret
+	}
+}
+
+// LoginOptionStateTTL sets how long users have to complete the sign in. Defaults to 10 minutes.
+func LoginOptionStateTTL(d time.Duration) LoginOption {
+	return func(l *Login) {
+		l.states.TTL = d
+	}
+}
+
+// LoginOptionInsecureCookie sends the state cookie over plain HTTP too, to develop the app
+// locally without TLS. By default, the cookie is Secure.
+func LoginOptionInsecureCookie() LoginOption {
+	return func(l *Login) {
+		l.states.Insecure = true
+	}
+}
+
+// LoginOptionSuccessHandler sets the handler responding to a completed sign in, typically by
+// starting a session for the user of the claims. By default, a plain text page is written.
+func LoginOptionSuccessHandler(f func(w http.ResponseWriter, r *http.Request, claims *Claims, token *slack.OpenIDConnectResponse)) LoginOption {
+	return func(l *Login) {
+		l.success = f
+	}
+}
+
+// LoginOptionFailureHandler sets the handler responding to a failed sign in. By default, the
+// error is written with a 400 or 500 status.
+func LoginOptionFailureHandler(f func(w http.ResponseWriter, r *http.Request, err error)) LoginOption {
+	return func(l *Login) {
+		l.failure = f
+	}
+}
+
+// NewLogin returns a Login for the app
+func NewLogin(clientID, clientSecret string, options ...LoginOption) *Login {
+	l := &Login{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		authorizeURL: defaultAuthorizeURL,
+		httpClient:   http.DefaultClient,
+		states: statecookie.States{
+			CookieName: defaultCookieName,
+			TTL:        defaultStateTTL,
+			Errors:     statecookie.Errors{InvalidState: ErrInvalidState, ExpiredState: ErrExpiredState, MissingCode: ErrMissingCode},
+		},
+		success: defaultSuccess,
+	}
+	l.failure = l.defaultFailure
+	for _, opt := range options {
+		opt(l)
+	}
+
+	if l.verifier == nil {
+		l.verifier = NewVerifier(clientID, VerifierOptionKeyFetcher(NewJWKSFetcher(l.httpClient)))
+	}
+	if len(l.states.Secret) == 0 {
+		l.states.Secret = statecookie.NewSecret()
+	}
+	return l
+}
+
+// LoginHandler returns the "Sign in with Slack" handler, redirecting the user to Slack
+func (l *Login) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, err := l.states.Start(w, r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, l.AuthorizeURL(state), http.StatusFound)
+	})
+}
+
+// AuthorizeURL returns the URL of Slack to sign in with the state
+func (l *Login) AuthorizeURL(state string) string {
+	values := url.Values{
+		"response_type": {"code"},
+		"scope":         {"openid email profile"},
+		"client_id":     {l.clientID},
+		"state":         {state},
+		"nonce":         {l.nonce(state)},
+	}
+	if l.redirectURI != "" {
+		values.Set("redirect_uri", l.redirectURI)
+	}
+	if l.teamID != "" {
+		values.Set("team", l.teamID)
+	}
+	return l.authorizeURL + "?" + values.Encode()
+}
+
+// CallbackHandler returns the handler of the redirect URI, completing the sign in
+func (l *Login) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, token, err := l.complete(w, r)
+		if err != nil {
+			l.failure(w, r, err)
+			return
+		}
+		l.success(w, r, claims, token)
+	})
+}
+
+func (l *Login) complete(w http.ResponseWriter, r *http.Request) (*Claims, *slack.OpenIDConnectResponse, error) {
+	state, code, err := l.states.Callback(w, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := slack.GetOpenIDConnectTokenContext(r.Context(), l.httpClient, l.clientID, l.clientSecret, code, l.redirectURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := l.verifier.Verify(r.Context(), token.IdToken, l.nonce(state))
+	if err != nil {
+		return nil, nil, err
+	}
+	return claims, token, nil
+}
+
+// nonce returns the nonce of the id_token of the sign in of the state
+func (l *Login) nonce(state string) string {
+	return base64.RawURLEncoding.EncodeToString(l.states.Sign("nonce:" + state))
+}
+
+func defaultSuccess(w http.ResponseWriter, _ *http.Request, claims *Claims, _ *slack.OpenIDConnectResponse) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintf(w, "Signed in as %s.", claims.Name)
+}
+
+func (l *Login) defaultFailure(w http.ResponseWriter, _ *http.Request, err error) {
+	l.states.Failure(w, err, "Sign in failed")
+}
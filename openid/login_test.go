@@ -0,0 +1,78 @@
+package openid
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slacktest"
+)
+
+func TestLoginFlow(t *testing.T) {
+	o := slacktest.NewOAuthServer()
+	o.AddApp(slacktest.OAuthApp{ClientID: "client", ClientSecret: "secret"})
+	s := slacktest.NewTestServer(o.Bind)
+	go s.Start()
+	defer s.Stop()
+
+	var signedIn *Claims
+	var failure error
+	login := NewLogin("client", "secret",
+		LoginOptionHTTPClient(s.HTTPClient()),
+		LoginOptionTeam("T024BE7LD"),
+		LoginOptionSuccessHandler(func(w http.ResponseWriter, r *http.Request, claims *Claims, _ *slack.OpenIDConnectResponse) {
+			signedIn = claims
+		}),
+		LoginOptionFailureHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			failure = err
+			w.WriteHeader(http.StatusForbidden)
+		}),
+	)
+
+	start := func() (*http.Cookie, url.Values) {
+		rec := httptest.NewRecorder()
+		login.LoginHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+		require.Equal(t, http.StatusFound, rec.Code)
+
+		location, err := url.Parse(rec.Header().Get("Location"))
+		require.NoError(t, err)
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		return cookies[0], location.Query()
+	}
+	callback := func(cookie *http.Cookie, query url.Values) int {
+		req := httptest.NewRequest(http.MethodGet, "/login/callback?"+query.Encode(), nil)
+		req.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		login.CallbackHandler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	cookie, authorize := start()
+	assert.Equal(t, "openid email profile", authorize.Get("scope"))
+	assert.Equal(t, "T024BE7LD", authorize.Get("team"))
+	code := o.Authorize(slacktest.OAuthGrant{ClientID: "client", UserID: "U1", Nonce: authorize.Get("nonce"), Name: "Alice"})
+
+	assert.Equal(t, http.StatusOK, callback(cookie, url.Values{"code": {code}, "state": {authorize.Get("state")}}))
+	require.NoError(t, failure)
+	if assert.NotNil(t, signedIn) {
+		assert.Equal(t, "U1", signedIn.UserID)
+		assert.Equal(t, "Alice", signedIn.Name)
+	}
+
+	// An id_token issued for another sign in is rejected
+	cookie, authorize = start()
+	code = o.Authorize(slacktest.OAuthGrant{ClientID: "client", UserID: "U1", Nonce: "other"})
+	assert.Equal(t, http.StatusForbidden, callback(cookie, url.Values{"code": {code}, "state": {authorize.Get("state")}}))
+	assert.True(t, errors.Is(failure, ErrInvalidNonce), "got %v", failure)
+
+	cookie, _ = start()
+	assert.Equal(t, http.StatusForbidden, callback(cookie, url.Values{"code": {"c"}, "state": {"forged"}}))
+	assert.True(t, errors.Is(failure, ErrInvalidState), "got %v", failure)
+}
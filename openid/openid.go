@@ -0,0 +1,195 @@
+// Package openid implements Sign in with Slack, Slack's OpenID Connect provider. A Verifier
+// validates the id_token returned by slack.GetOpenIDConnectToken against the keys Slack
+// publishes, and a Login provides the HTTP handlers of the sign in flow.
+//
+// The identity of the user of a Sign in with Slack token can also be requested with
+// slack.Client.GetOpenIDConnectUserInfo.
+package openid
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/incident-io/slack/internal/errorsx"
+)
+
+// Issuer is the issuer of the id_tokens of Slack
+const Issuer = "https://slack.com"
+
+// Errors returned by Verifier.Verify.
+const (
+	ErrMalformedToken   = errorsx.String("malformed id_token")
+	ErrUnsupportedAlg   = errorsx.String("unsupported id_token signing algorithm")
+	ErrInvalidSignature = errorsx.String("invalid id_token signature")
+	ErrInvalidIssuer    = errorsx.String("invalid id_token issuer")
+	ErrInvalidAudience  = errorsx.String("invalid id_token audience")
+	ErrTokenExpired     = errorsx.String("id_token expired")
+	ErrInvalidNonce     = errorsx.String("invalid id_token nonce")
+)
+
+const defaultLeeway = time.Minute
+
+// Claims are the claims of a Slack id_token.
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	AuthTime  int64    `json:"auth_time"`
+	Nonce     string   `json:"nonce"`
+
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	DateEmailVerified int64  `json:"date_email_verified"`
+	Name              string `json:"name"`
+	Picture           string `json:"picture"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	Locale            string `json:"locale"`
+
+	UserID       string `json:"https://slack.com/user_id"`
+	TeamID       string `json:"https://slack.com/team_id"`
+	TeamName     string `json:"https://slack.com/team_name"`
+	TeamDomain   string `json:"https://slack.com/team_domain"`
+	TeamImage230 string `json:"https://slack.com/team_image_230"`
+}
+
+// Audience is the aud claim, which is either a string or an array of strings.
+type Audience []string
+
+// UnmarshalJSON accepts both forms of the claim
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a Audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier verifies the id_tokens issued to an app.
+type Verifier struct {
+	clientID string
+	keys     KeyFetcher
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// VerifierOption configures a Verifier.
+type VerifierOption func(*Verifier)
+
+// VerifierOptionKeyFetcher sets the source of the signing keys, e.g. StaticKeys in tests.
+// Defaults to the JWKS of Slack, fetched with http.DefaultClient.
+func VerifierOptionKeyFetcher(keys KeyFetcher) VerifierOption {
+	return func(v *Verifier) {
+		v.keys = keys
+	}
+}
+
+// VerifierOptionLeeway sets the clock skew tolerated on the expiry. Defaults to 1 minute.
+func VerifierOptionLeeway(d time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.leeway = d
+	}
+}
+
+// NewVerifier returns a Verifier of the id_tokens issued to the client ID of the app
+func NewVerifier(clientID string, options ...VerifierOption) *Verifier {
+	v := &Verifier{
+		clientID: clientID,
+		leeway:   defaultLeeway,
+		now:      time.Now,
+	}
+	for _, opt := range options {
+		opt(v)
+	}
+	if v.keys == nil {
+		v.keys = NewJWKSFetcher(http.DefaultClient)
+	}
+	return v
+}
+
+// Verify checks the signature, issuer, audience and expiry of the id_token, and its nonce
+// unless it is empty, and returns its claims.
+func (v *Verifier) Verify(ctx context.Context, idToken, nonce string) (*Claims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" {
+		return nil, ErrUnsupportedAlg
+	}
+
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claims.Issuer != Issuer:
+		return nil, ErrInvalidIssuer
+	case !claims.Audience.contains(v.clientID):
+		return nil, ErrInvalidAudience
+	case v.now().Add(-v.leeway).After(time.Unix(claims.ExpiresAt, 0)):
+		return nil, ErrTokenExpired
+	case nonce != "" && claims.Nonce != nonce:
+		return nil, ErrInvalidNonce
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%w: %s", ErrMalformedToken, err.Error())
+	}
+	return nil
+}
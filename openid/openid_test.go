@@ -0,0 +1,125 @@
+package openid
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slacktest"
+)
+
+func signToken(t *testing.T, key *rsa.PrivateKey, header, claims map[string]interface{}) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v := NewVerifier("client", VerifierOptionKeyFetcher(StaticKeys{"k1": &key.PublicKey}))
+	header := map[string]interface{}{"alg": "RS256", "kid": "k1"}
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":                       Issuer,
+			"aud":                       "client",
+			"sub":                       "U1",
+			"exp":                       time.Now().Add(time.Hour).Unix(),
+			"nonce":                     "n-1",
+			"email":                     "alice@example.com",
+			"picture":                   "https://example.com/alice.png",
+			"https://slack.com/team_id": "T1",
+			"https://slack.com/user_id": "U1",
+		}
+	}
+
+	claims, err := v.Verify(context.Background(), signToken(t, key, header, validClaims()), "n-1")
+	require.NoError(t, err)
+	assert.Equal(t, "T1", claims.TeamID)
+	assert.Equal(t, "U1", claims.UserID)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.Equal(t, "https://example.com/alice.png", claims.Picture)
+
+	tests := []struct {
+		name     string
+		token    func() string
+		expected error
+	}{
+		{"malformed", func() string { return "abc" }, ErrMalformedToken},
+		{"alg none", func() string {
+			return signToken(t, key, map[string]interface{}{"alg": "none", "kid": "k1"}, validClaims())
+		}, ErrUnsupportedAlg},
+		{"other key", func() string { return signToken(t, other, header, validClaims()) }, ErrInvalidSignature},
+		{"issuer", func() string {
+			c := validClaims()
+			c["iss"] = "https://example.com"
+			return signToken(t, key, header, c)
+		}, ErrInvalidIssuer},
+		{"audience", func() string {
+			c := validClaims()
+			c["aud"] = []string{"other"}
+			return signToken(t, key, header, c)
+		}, ErrInvalidAudience},
+		{"expired", func() string {
+			c := validClaims()
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+			return signToken(t, key, header, c)
+		}, ErrTokenExpired},
+		{"nonce", func() string {
+			c := validClaims()
+			c["nonce"] = "replayed"
+			return signToken(t, key, header, c)
+		}, ErrInvalidNonce},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token(), "n-1")
+			assert.True(t, errors.Is(err, tt.expected), "got %v", err)
+		})
+	}
+}
+
+func TestJWKSFetcher(t *testing.T) {
+	o := slacktest.NewOAuthServer()
+	o.AddApp(slacktest.OAuthApp{ClientID: "client", ClientSecret: "secret"})
+	s := slacktest.NewTestServer(o.Bind)
+	go s.Start()
+	defer s.Stop()
+
+	code := o.Authorize(slacktest.OAuthGrant{ClientID: "client", UserID: "U1", Email: "alice@example.com"})
+	token, err := slack.GetOpenIDConnectToken(s.HTTPClient(), "client", "secret", code, "")
+	require.NoError(t, err)
+
+	v := NewVerifier("client", VerifierOptionKeyFetcher(NewJWKSFetcher(s.HTTPClient())))
+	claims, err := v.Verify(context.Background(), token.IdToken, "")
+	require.NoError(t, err)
+	assert.Equal(t, "U1", claims.Subject)
+	assert.Equal(t, "alice@example.com", claims.Email)
+
+	info, err := slack.New(token.AccessToken, slack.OptionHTTPClient(s.HTTPClient())).GetOpenIDConnectUserInfo()
+	require.NoError(t, err)
+	assert.Equal(t, claims.UserID, info.UserID)
+}
@@ -34,11 +34,9 @@ func NewGetReactionsParameters() GetReactionsParameters {
 
 type getReactionsResponseFull struct {
 	Type    string
-	Channel string `json:"channel,omitempty"` // channel is at the root level for message types
-	M       struct {
-		*Message // message structure already contains reactions
-	} `json:"message"`
-	F struct {
+	Channel string   `json:"channel,omitempty"` // channel is at the root level for message types
+	M       *Message `json:"message"`           // message structure already contains reactions
+	F       struct {
 		*File
 		Reactions []ItemReaction
 	} `json:"file"`
@@ -56,8 +54,10 @@ func (res getReactionsResponseFull) extractReactedItem() ReactedItem {
 	switch item.Type {
 	case "message":
 		item.Channel = res.Channel
-		item.Message = res.M.Message
-		item.Reactions = res.M.Reactions
+		item.Message = res.M
+		if res.M != nil {
+			item.Reactions = res.M.Reactions
+		}
 	case "file":
 		item.File = res.F.File
 		item.Reactions = res.F.Reactions
@@ -100,10 +100,8 @@ type listReactionsResponseFull struct {
 	Items []struct {
 		Type    string
 		Channel string
-		M       struct {
-			*Message
-		} `json:"message"`
-		F struct {
+		M       *Message `json:"message"`
+		F       struct {
 			*File
 			Reactions []ItemReaction
 		} `json:"file"`
@@ -124,8 +122,10 @@ func (res listReactionsResponseFull) extractReactedItems() []ReactedItem {
 		switch input.Type {
 		case "message":
 			item.Channel = input.Channel
-			item.Message = input.M.Message
-			item.Reactions = input.M.Reactions
+			item.Message = input.M
+			if input.M != nil {
+				item.Reactions = input.M.Reactions
+			}
 		case "file":
 			item.File = input.F.File
 			item.Reactions = input.F.Reactions
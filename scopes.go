@@ -0,0 +1,368 @@
+package slack
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scope lists of the methods acting on any type of conversation, a token needs the one of the
+// type of the conversation.
+var (
+	conversationsHistoryScopes = []string{"channels:history", "groups:history", "im:history", "mpim:history"}
+	conversationsReadScopes    = []string{"channels:read", "groups:read", "im:read", "mpim:read"}
+	conversationsManageScopes  = []string{"channels:manage", "groups:write", "im:write", "mpim:write"}
+	conversationsWriteScopes   = []string{"channels:write", "groups:write", "im:write", "mpim:write"}
+)
+
+// maxGrantedTokens is the number of tokens a scopeTracker keeps the granted scopes of.
+const maxGrantedTokens = 64
+
+// methodScopes is the catalog of the scopes of the wrapped Web API methods: a token needs one of
+// the scopes of a method to call it. The methods called with a client secret, a configuration or
+// workflow token, or any token, have no scopes. The methods missing from the catalog are unknown.
+var methodScopes = map[string][]string{
+	"admin.conversations.convertToPrivate": {"admin.conversations:write"},
+	"admin.conversations.convertToPublic":  {"admin.conversations:write"},
+	"admin.conversations.setTeams":         {"admin.conversations:write"},
+
+	"apps.connections.open":          {"connections:write"},
+	"apps.event.authorizations.list": {"authorizations:read"},
+	"apps.manifest.create":           nil,
+	"apps.manifest.delete":           nil,
+	"apps.manifest.export":           nil,
+	"apps.manifest.update":           nil,
+	"apps.manifest.validate":         nil,
+	"apps.uninstall":                 nil,
+
+	"assistant.threads.setStatus":           {"assistant:write"},
+	"assistant.threads.setSuggestedPrompts": {"assistant:write"},
+	"assistant.threads.setTitle":            {"assistant:write"},
+
+	"auth.revoke":     nil,
+	"auth.teams.list": nil,
+	"auth.test":       nil,
+
+	"bookmarks.add":    {"bookmarks:write"},
+	"bookmarks.edit":   {"bookmarks:write"},
+	"bookmarks.list":   {"bookmarks:read"},
+	"bookmarks.remove": {"bookmarks:write"},
+
+	"bots.info": {"users:read"},
+
+	"calls.add":                 {"calls:write"},
+	"calls.end":                 {"calls:write"},
+	"calls.info":                {"calls:read"},
+	"calls.participants.add":    {"calls:write"},
+	"calls.participants.remove": {"calls:write"},
+	"calls.update":              {"calls:write"},
+
+	"canvases.access.delete":        {"canvases:write"},
+	"canvases.access.set":           {"canvases:write"},
+	"canvases.create":               {"canvases:write"},
+	"canvases.delete":               {"canvases:write"},
+	"canvases.edit":                 {"canvases:write"},
+	"canvases.sections.lookup":      {"canvases:read"},
+	"conversations.canvases.create": {"canvases:write"},
+
+	"chat.delete":                 {"chat:write"},
+	"chat.deleteScheduledMessage": {"chat:write"},
+	"chat.getPermalink":           nil,
+	"chat.meMessage":              {"chat:write"},
+	"chat.postEphemeral":          {"chat:write"},
+	"chat.postMessage":            {"chat:write"},
+	"chat.scheduleMessage":        {"chat:write"},
+	"chat.scheduledMessages.list": nil,
+	"chat.unfurl":                 {"links:write"},
+	"chat.update":                 {"chat:write"},
+
+	"conversations.archive":      conversationsManageScopes,
+	"conversations.close":        conversationsManageScopes,
+	"conversations.create":       {"channels:manage", "groups:write"},
+	"conversations.history":      conversationsHistoryScopes,
+	"conversations.info":         conversationsReadScopes,
+	"conversations.invite":       conversationsManageScopes,
+	"conversations.inviteShared": {"conversations.connect:write"},
+	"conversations.join":         {"channels:join"},
+	"conversations.kick":         {"channels:manage", "groups:write"},
+	"conversations.leave":        conversationsManageScopes,
+	"conversations.list":         conversationsReadScopes,
+	"conversations.mark":         conversationsWriteScopes,
+	"conversations.members":      conversationsReadScopes,
+	"conversations.open":         conversationsManageScopes,
+	"conversations.rename":       {"channels:manage", "groups:write"},
+	"conversations.replies":      conversationsHistoryScopes,
+	"conversations.setPurpose":   conversationsManageScopes,
+	"conversations.setTopic":     conversationsManageScopes,
+	"conversations.unarchive":    {"channels:manage", "groups:write"},
+	"users.conversations":        conversationsReadScopes,
+
+	"dialog.open": nil,
+
+	"dnd.endDnd":    {"dnd:write"},
+	"dnd.endSnooze": {"dnd:write"},
+	"dnd.info":      {"dnd:read"},
+	"dnd.setSnooze": {"dnd:write"},
+	"dnd.teamInfo":  {"dnd:read"},
+
+	"emoji.list": {"emoji:read"},
+
+	"entity.presentDetails": nil,
+
+	"files.comments.delete":        {"files:write"},
+	"files.completeUploadExternal": {"files:write"},
+	"files.delete":                 {"files:write"},
+	"files.getUploadURLExternal":   {"files:write"},
+	"files.info":                   {"files:read"},
+	"files.list":                   {"files:read"},
+	"files.revokePublicURL":        {"files:write"},
+	"files.sharedPublicURL":        {"files:write"},
+
+	"files.remote.add":    {"remote_files:write"},
+	"files.remote.info":   {"remote_files:read"},
+	"files.remote.list":   {"remote_files:read"},
+	"files.remote.remove": {"remote_files:write"},
+	"files.remote.share":  {"remote_files:share"},
+	"files.remote.update": {"remote_files:write"},
+
+	"functions.completeError":   nil,
+	"functions.completeSuccess": nil,
+
+	"migration.exchange": {"tokens.basic"},
+
+	"oauth.access":            nil,
+	"oauth.v2.access":         nil,
+	"openid.connect.token":    nil,
+	"openid.connect.userInfo": {"openid"},
+
+	"pins.add":    {"pins:write"},
+	"pins.list":   {"pins:read"},
+	"pins.remove": {"pins:write"},
+
+	"reactions.add":    {"reactions:write"},
+	"reactions.get":    {"reactions:read"},
+	"reactions.list":   {"reactions:read"},
+	"reactions.remove": {"reactions:write"},
+
+	"reminders.add":    {"reminders:write"},
+	"reminders.delete": {"reminders:write"},
+	"reminders.list":   {"reminders:read"},
+
+	"rtm.connect": nil,
+	"rtm.start":   nil,
+
+	"search.all":      {"search:read"},
+	"search.files":    {"search:read"},
+	"search.messages": {"search:read"},
+
+	"stars.add":    {"stars:write"},
+	"stars.list":   {"stars:read"},
+	"stars.remove": {"stars:write"},
+
+	"team.accessLogs":   {"admin"},
+	"team.billableInfo": {"admin"},
+	"team.info":         {"team:read"},
+	"team.profile.get":  {"users.profile:read"},
+
+	"tooling.tokens.rotate": nil,
+
+	"usergroups.create":       {"usergroups:write"},
+	"usergroups.disable":      {"usergroups:write"},
+	"usergroups.enable":       {"usergroups:write"},
+	"usergroups.list":         {"usergroups:read"},
+	"usergroups.update":       {"usergroups:write"},
+	"usergroups.users.list":   {"usergroups:read"},
+	"usergroups.users.update": {"usergroups:write"},
+
+	"users.deletePhoto":   {"users.profile:write"},
+	"users.getPresence":   {"users:read"},
+	"users.identity":      {"identity.basic"},
+	"users.info":          {"users:read"},
+	"users.list":          {"users:read"},
+	"users.lookupByEmail": {"users:read.email"},
+	"users.prefs.get":     {"users.prefs:read"},
+	"users.prefs.set":     {"users.prefs:write"},
+	"users.profile.get":   {"users.profile:read"},
+	"users.profile.set":   {"users.profile:write"},
+	"users.setActive":     {"users:write"},
+	"users.setPhoto":      {"users.profile:write"},
+	"users.setPresence":   {"users:write"},
+
+	"views.open":    nil,
+	"views.publish": nil,
+	"views.push":    nil,
+	"views.update":  nil,
+}
+
+// RequiredScopes returns the scopes a token needs one of to call the Web API method, and false
+// if the method is not in the catalog.
+func RequiredScopes(method string) ([]string, bool) {
+	scopes, ok := methodScopes[method]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), scopes...), true
+}
+
+// ManifestScopes returns the sorted scopes to request in the manifest of an app calling the
+// methods, e.g. for its OAuthScopes. All the scopes of the methods acting on any type of
+// conversation are returned, the ones of the types the app does not use can be removed. The
+// methods missing from the catalog are ignored.
+func ManifestScopes(methods ...string) []string {
+	set := map[string]struct{}{}
+	for _, method := range methods {
+		for _, scope := range methodScopes[method] {
+			set[scope] = struct{}{}
+		}
+	}
+
+	scopes := make([]string, 0, len(set))
+	for scope := range set {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// MissingScopeError is returned by a client with OptionScopePreflight when the token was not
+// granted any of the scopes of the method called.
+type MissingScopeError struct {
+	Method  string
+	Needed  []string
+	Granted []string
+}
+
+func (e MissingScopeError) Error() string {
+	return fmt.Sprintf("missing_scope: %s needs one of %s, granted %s",
+		e.Method, strings.Join(e.Needed, ","), strings.Join(e.Granted, ","))
+}
+
+// scopeTracker is the httpClient of a Client, capturing the scopes granted to its tokens from the
+// X-OAuth-Scopes header of the responses, and checking them before the calls in preflight mode.
+type scopeTracker struct {
+	client    httpClient
+	preflight bool
+
+	mu sync.RWMutex
+	// granted maps the tokens to their scopes, as a client may call methods with other tokens
+	// than its own, e.g. an app-level token to open Socket Mode connections
+	granted map[string][]string
+	// tokens orders the tokens from the least recently granted, to forget the oldest of them
+	// past maxGrantedTokens, as rotated tokens are never used again
+	tokens []string
+}
+
+func (t *scopeTracker) Do(req *http.Request) (*http.Response, error) {
+	token := requestToken(req)
+	if t.preflight {
+		if err := t.check(path.Base(req.URL.Path), token); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	if values, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		t.setGranted(token, parseScopes(strings.Join(values, ",")))
+	}
+	return resp, nil
+}
+
+// check returns a MissingScopeError if the scopes granted to the token are known, and have none
+// of the scopes of the method
+func (t *scopeTracker) check(method, token string) error {
+	needed := methodScopes[method]
+	if len(needed) == 0 {
+		return nil
+	}
+
+	granted := t.grantedScopes(token)
+	if granted == nil {
+		return nil
+	}
+	for _, scope := range needed {
+		for _, g := range granted {
+			if g == scope {
+				return nil
+			}
+		}
+	}
+	return MissingScopeError{Method: method, Needed: append([]string(nil), needed...), Granted: granted}
+}
+
+func (t *scopeTracker) setGranted(token string, scopes []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.granted == nil {
+		t.granted = map[string][]string{}
+	}
+	if _, ok := t.granted[token]; ok {
+		t.tokens = slices.DeleteFunc(t.tokens, func(s string) bool { return s == token })
+	}
+	t.tokens = append(t.tokens, token)
+	t.granted[token] = scopes
+	for len(t.tokens) > maxGrantedTokens {
+		delete(t.granted, t.tokens[0])
+		t.tokens = t.tokens[1:]
+	}
+}
+
+func (t *scopeTracker) grantedScopes(token string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	granted, ok := t.granted[token]
+	if !ok {
+		return nil
+	}
+	return append([]string{}, granted...)
+}
+
+// requestToken returns the token a request is made with: the bearer token of its Authorization
+// header, else its token parameter, in the query or in a form body
+func requestToken(req *http.Request) string {
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if token := req.URL.Query().Get("token"); token != "" {
+		return token
+	}
+	if req.GetBody == nil || req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		return ""
+	}
+
+	// Read a copy of the body, leaving the one of the request to be sent
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return ""
+	}
+	return values.Get("token")
+}
+
+// parseScopes parses a comma separated list of scopes, as in the X-OAuth-Scopes header and the
+// oauth.v2.access responses
+func parseScopes(s string) []string {
+	scopes := []string{}
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
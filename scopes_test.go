@@ -0,0 +1,161 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredScopes(t *testing.T) {
+	scopes, ok := RequiredScopes("conversations.history")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"channels:history", "groups:history", "im:history", "mpim:history"}, scopes)
+
+	scopes, ok = RequiredScopes("auth.test")
+	assert.True(t, ok)
+	assert.Empty(t, scopes)
+
+	_, ok = RequiredScopes("unknown.method")
+	assert.False(t, ok)
+
+	assert.Equal(t,
+		[]string{"chat:write", "users:read", "users:read.email"},
+		ManifestScopes("chat.postMessage", "chat.update", "users.info", "users.lookupByEmail", "auth.test", "unknown.method"),
+	)
+}
+
+func TestScopePreflight(t *testing.T) {
+	var calls []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth.test", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "auth.test")
+		w.Header().Set("X-OAuth-Scopes", "chat:write, users:read")
+		_, _ = w.Write([]byte(`{"ok":true,"user_id":"U1"}`))
+	})
+	mux.HandleFunc("/emoji.list", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "emoji.list")
+		w.Header().Set("X-OAuth-Scopes", "chat:write,users:read")
+		_, _ = w.Write([]byte(`{"ok":false,"error":"missing_scope","needed":"emoji:read","provided":"chat:write,users:read"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	api := New("xoxb-token", OptionAPIURL(server.URL+"/"))
+	assert.Nil(t, api.GrantedScopes())
+
+	// Without preflight, the error of Slack brings along the needed and provided scopes
+	_, err := api.GetEmoji()
+	var slackErr SlackErrorResponse
+	require.True(t, errors.As(err, &slackErr), "got %v", err)
+	assert.Equal(t, "missing_scope", slackErr.Err)
+	assert.Equal(t, "emoji:read", slackErr.Needed)
+	assert.Equal(t, "chat:write,users:read", slackErr.Provided)
+	assert.Equal(t, []string{"chat:write", "users:read"}, api.GrantedScopes())
+
+	api = New("xoxb-token", OptionAPIURL(server.URL+"/"), OptionScopePreflight(true))
+	calls = nil
+
+	// The granted scopes are not known before the first response
+	_, err = api.AuthTest()
+	require.NoError(t, err)
+	_, err = api.GetEmoji()
+	var scopeErr MissingScopeError
+	require.True(t, errors.As(err, &scopeErr), "got %v", err)
+	assert.Equal(t, MissingScopeError{Method: "emoji.list", Needed: []string{"emoji:read"}, Granted: []string{"chat:write", "users:read"}}, scopeErr)
+	assert.Equal(t, []string{"auth.test"}, calls)
+
+	// Unless they are set
+	api = New("xoxb-token", OptionAPIURL(server.URL+"/"), OptionScopePreflight(true), OptionGrantedScopes("chat:write"))
+	calls = nil
+	_, err = api.GetEmoji()
+	assert.True(t, errors.As(err, &scopeErr), "got %v", err)
+	assert.Empty(t, calls)
+}
+
+func TestScopesPerToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth.test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "chat:write")
+		_, _ = w.Write([]byte(`{"ok":true,"user_id":"U1"}`))
+	})
+	mux.HandleFunc("/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xapp-token", r.Header.Get("Authorization"))
+		w.Header().Set("X-OAuth-Scopes", "connections:write")
+		_, _ = w.Write([]byte(`{"ok":true,"url":"wss://example.com/"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	api := New("xoxb-token", OptionAPIURL(server.URL+"/"), OptionAppLevelToken("xapp-token"), OptionScopePreflight(true))
+
+	_, err := api.AuthTest()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chat:write"}, api.GrantedScopes())
+
+	// The app-level token is checked against its own scopes, and doesn't replace the ones of the bot
+	_, _, err = api.StartSocketModeContext(context.Background())
+	require.NoError(t, err)
+	_, _, err = api.StartSocketModeContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chat:write"}, api.GrantedScopes())
+
+	_, err = api.GetEmoji()
+	var scopeErr MissingScopeError
+	assert.True(t, errors.As(err, &scopeErr), "got %v", err)
+	assert.Equal(t, []string{"chat:write"}, scopeErr.Granted)
+}
+
+func TestMethodScopesCatalog(t *testing.T) {
+	// literals looking like methods which are not
+	notMethods := map[string]bool{
+		"chat.responseURL":     true,
+		"preview.png":          true,
+		"workflow.trigger.url": true,
+	}
+	method := regexp.MustCompile(`"([a-z]+(?:\.[a-zA-Z]+)+)"`)
+
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || file == "scopes.go" {
+			continue
+		}
+		src, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, match := range method.FindAllSubmatch(src, -1) {
+			name := string(match[1])
+			if notMethods[name] {
+				continue
+			}
+			_, ok := methodScopes[name]
+			assert.True(t, ok, "%s calls %s, missing from the scope catalog", file, name)
+		}
+	}
+}
+
+func TestScopesForgetOldTokens(t *testing.T) {
+	tracker := &scopeTracker{}
+	tracker.setGranted("xoxb-kept", []string{"chat:write"})
+	for i := 0; i < maxGrantedTokens; i++ {
+		tracker.setGranted(fmt.Sprintf("xoxb-%d", i), []string{"emoji:read"})
+		if i%2 == 0 {
+			tracker.setGranted("xoxb-kept", []string{"chat:write"})
+		}
+	}
+
+	assert.Len(t, tracker.granted, maxGrantedTokens)
+	assert.Len(t, tracker.tokens, maxGrantedTokens)
+	assert.Equal(t, []string{"chat:write"}, tracker.grantedScopes("xoxb-kept"))
+	assert.Nil(t, tracker.grantedScopes("xoxb-0"))
+	assert.Equal(t, []string{"emoji:read"}, tracker.grantedScopes(fmt.Sprintf("xoxb-%d", maxGrantedTokens-1)))
+}
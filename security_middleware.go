@@ -0,0 +1,168 @@
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/incident-io/slack/internal/expiry"
+)
+
+const defaultSignatureWindow = 5 * time.Minute
+
+// SecretsMiddleware verifies the signature of the requests from Slack with any of a list of
+// signing secrets, so that the signing secret of an app can be rotated without downtime: add the
+// new secret, regenerate it in the app settings, then remove the old one.
+type SecretsMiddleware struct {
+	secrets []string
+	window  time.Duration
+	replays *replayCache
+	onError func(w http.ResponseWriter, r *http.Request, err error)
+	now     func() time.Time
+}
+
+// SecretsMiddlewareOption configures a SecretsMiddleware.
+type SecretsMiddlewareOption func(*SecretsMiddleware)
+
+// SecretsMiddlewareOptionWindow sets how far the timestamp of a request can be from now. Defaults
+// to 5 minutes, as recommended by Slack.
+func SecretsMiddlewareOptionWindow(d time.Duration) SecretsMiddlewareOption {
+	return func(m *SecretsMiddleware) {
+		m.window = d
+	}
+}
+
+// SecretsMiddlewareOptionReplayProtection rejects the requests whose signature was already seen
+// within the window with ErrReplayedRequest. The signatures are remembered in memory, so replays
+// across the instances of an app are not detected.
+func SecretsMiddlewareOptionReplayProtection() SecretsMiddlewareOption {
+	return func(m *SecretsMiddleware) {
+		m.replays = &replayCache{seen: map[[sha256.Size]byte]time.Time{}}
+	}
+}
+
+// SecretsMiddlewareOptionErrorHandler sets the handler responding to the requests that fail the
+// verification, e.g. to count the errors by type. By default, they are answered with a 401.
+func SecretsMiddlewareOptionErrorHandler(f func(w http.ResponseWriter, r *http.Request, err error)) SecretsMiddlewareOption {
+	return func(m *SecretsMiddleware) {
+		m.onError = f
+	}
+}
+
+// NewSecretsMiddleware returns a SecretsMiddleware accepting the requests signed with any of the secrets
+func NewSecretsMiddleware(secrets []string, options ...SecretsMiddlewareOption) *SecretsMiddleware {
+	m := &SecretsMiddleware{
+		secrets: secrets,
+		window:  defaultSignatureWindow,
+		onError: func(w http.ResponseWriter, _ *http.Request, _ error) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		},
+		now: time.Now,
+	}
+	for _, opt := range options {
+		opt(m)
+	}
+	return m
+}
+
+// Handler verifies the requests before serving them with next. The body is read to be verified,
+// and restored for next.
+func (m *SecretsMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := m.Verify(r.Header, body); err != nil {
+			m.onError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Verify verifies the signature of a request. It returns ErrMissingHeaders, ErrExpiredTimestamp,
+// ErrInvalidSignature, which is also returned for a malformed timestamp, or ErrReplayedRequest.
+func (m *SecretsMiddleware) Verify(header http.Header, body []byte) error {
+	signature := header.Get(hSignature)
+	stimestamp := header.Get(hTimestamp)
+	if signature == "" || stimestamp == "" {
+		return ErrMissingHeaders
+	}
+
+	timestamp, err := strconv.ParseInt(stimestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	now := m.now()
+	if absDuration(now.Sub(time.Unix(timestamp, 0))) > m.window {
+		return ErrExpiredTimestamp
+	}
+
+	// The replays are keyed by the decoded signature, as the hex of a signature can be written in
+	// several ways
+	hexSignature, ok := strings.CutPrefix(signature, "v0=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	bsignature, err := hex.DecodeString(hexSignature)
+	if err != nil || len(bsignature) != sha256.Size {
+		return ErrInvalidSignature
+	}
+	if !m.signedWithAny(stimestamp, body, bsignature) {
+		return ErrInvalidSignature
+	}
+
+	if m.replays != nil && !m.replays.add([sha256.Size]byte(bsignature), now, now.Add(m.window)) {
+		return ErrReplayedRequest
+	}
+	return nil
+}
+
+func (m *SecretsMiddleware) signedWithAny(timestamp string, body, signature []byte) bool {
+	valid := false
+	for _, secret := range m.secrets {
+		hash := hmac.New(sha256.New, []byte(secret))
+		_, _ = hash.Write([]byte("v0:" + timestamp + ":"))
+		_, _ = hash.Write(body)
+		// hmac.Equal is constant time, and every secret is tried not to leak which one matched
+		if hmac.Equal(hash.Sum(nil), signature) {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// replayCache remembers the signatures until they expire
+type replayCache struct {
+	mu   sync.Mutex
+	seen map[[sha256.Size]byte]time.Time
+	// expiries holds the signatures in the order they expire, as they all live for the window
+	expiries expiry.Queue[[sha256.Size]byte]
+}
+
+// add reports false if the signature was already seen
+func (c *replayCache) add(key [sha256.Size]byte, now, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expiries.PopExpired(now, func(k [sha256.Size]byte) {
+		delete(c.seen, k)
+	})
+	if _, ok := c.seen[key]; ok {
+		return false
+	}
+	c.seen[key] = expiresAt
+	c.expiries.Push(key, expiresAt)
+	return true
+}
@@ -0,0 +1,119 @@
+package slack
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSecretsMiddleware(secrets []string, options ...SecretsMiddlewareOption) *SecretsMiddleware {
+	m := NewSecretsMiddleware(secrets, options...)
+	m.now = func() time.Time { return time.Unix(1531431954, 0).Add(time.Minute) }
+	return m
+}
+
+func TestSecretsMiddlewareVerify(t *testing.T) {
+	expired := newHeader(true)
+	expired.Set("X-Slack-Request-Timestamp", "1531431000")
+	malformed := newHeader(true)
+	malformed.Set("X-Slack-Request-Timestamp", "yesterday")
+	unversioned := newHeader(true)
+	unversioned.Set("X-Slack-Signature", strings.TrimPrefix(unversioned.Get("X-Slack-Signature"), "v0="))
+
+	tests := []struct {
+		name     string
+		secrets  []string
+		header   http.Header
+		body     string
+		expected error
+	}{
+		{"valid", []string{validSigningSecret}, newHeader(true), validBody, nil},
+		{"rotated secret", []string{invalidSigningSecret, validSigningSecret}, newHeader(true), validBody, nil},
+		{"invalid secret", []string{invalidSigningSecret}, newHeader(true), validBody, ErrInvalidSignature},
+		{"invalid body", []string{validSigningSecret}, newHeader(true), invalidBody, ErrInvalidSignature},
+		{"missing headers", []string{validSigningSecret}, newHeader(false), validBody, ErrMissingHeaders},
+		{"expired", []string{validSigningSecret}, expired, validBody, ErrExpiredTimestamp},
+		{"malformed timestamp", []string{validSigningSecret}, malformed, validBody, ErrInvalidSignature},
+		{"missing version", []string{validSigningSecret}, unversioned, validBody, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestSecretsMiddleware(tt.secrets).Verify(tt.header, []byte(tt.body))
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.expected), "got %v", err)
+		})
+	}
+}
+
+func TestSecretsMiddlewareReplayProtection(t *testing.T) {
+	m := newTestSecretsMiddleware([]string{validSigningSecret}, SecretsMiddlewareOptionReplayProtection())
+
+	assert.NoError(t, m.Verify(newHeader(true), []byte(validBody)))
+	err := m.Verify(newHeader(true), []byte(validBody))
+	assert.True(t, errors.Is(err, ErrReplayedRequest), "got %v", err)
+
+	// The same signature written differently is a replay too
+	upper := newHeader(true)
+	upper.Set("X-Slack-Signature", "v0="+strings.ToUpper(strings.TrimPrefix(upper.Get("X-Slack-Signature"), "v0=")))
+	err = m.Verify(upper, []byte(validBody))
+	assert.True(t, errors.Is(err, ErrReplayedRequest), "got %v", err)
+
+	// A request with an invalid signature is not remembered
+	other := newTestSecretsMiddleware([]string{validSigningSecret}, SecretsMiddlewareOptionReplayProtection())
+	assert.Error(t, other.Verify(newHeader(true), []byte(invalidBody)))
+	assert.NoError(t, other.Verify(newHeader(true), []byte(validBody)))
+}
+
+func TestReplayCacheExpires(t *testing.T) {
+	c := &replayCache{seen: map[[sha256.Size]byte]time.Time{}}
+	now := time.Unix(1531431954, 0)
+
+	a, b := [sha256.Size]byte{'a'}, [sha256.Size]byte{'b'}
+
+	assert.True(t, c.add(a, now, now.Add(time.Minute)))
+	assert.True(t, c.add(b, now.Add(30*time.Second), now.Add(90*time.Second)))
+	assert.False(t, c.add(a, now.Add(59*time.Second), now.Add(2*time.Minute)))
+
+	assert.True(t, c.add(a, now.Add(time.Minute+time.Second), now.Add(2*time.Minute)), "a should have expired")
+	assert.Len(t, c.seen, 2)
+	assert.Equal(t, 2, c.expiries.Len())
+}
+
+func TestSecretsMiddlewareHandler(t *testing.T) {
+	var failure error
+	m := newTestSecretsMiddleware([]string{validSigningSecret},
+		SecretsMiddlewareOptionErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			failure = err
+			w.WriteHeader(http.StatusForbidden)
+		}),
+	)
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, validBody, string(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(validBody))
+	req.Header = newHeader(true)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, failure)
+
+	req = httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(invalidBody))
+	req.Header = newHeader(true)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.True(t, errors.Is(failure, ErrInvalidSignature), "got %v", failure)
+}
@@ -65,6 +65,7 @@ type Client struct {
 	debug              bool
 	log                ilogger
 	httpclient         httpClient
+	scopes             *scopeTracker
 }
 
 // Option defines an option for a Client
@@ -111,6 +112,24 @@ func OptionConfigRefreshToken(token string) func(*Client) {
 	return func(c *Client) { c.configRefreshToken = token }
 }
 
+// OptionScopePreflight makes the client fail fast with a MissingScopeError, without calling
+// Slack, when the token was not granted any of the scopes of a method in the RequiredScopes
+// catalog. The granted scopes are known once a first response was received, or set with
+// OptionGrantedScopes.
+func OptionScopePreflight(b bool) func(*Client) {
+	return func(c *Client) {
+		c.scopes.preflight = b
+	}
+}
+
+// OptionGrantedScopes sets the scopes granted to the token, e.g. the ones of its installation,
+// until a response tells them.
+func OptionGrantedScopes(scopes ...string) func(*Client) {
+	return func(c *Client) {
+		c.scopes.setGranted(c.token, append([]string{}, scopes...))
+	}
+}
+
 // New builds a slack client from the provided token and options.
 func New(token string, options ...Option) *Client {
 	s := &Client{
@@ -118,12 +137,16 @@ func New(token string, options ...Option) *Client {
 		endpoint:   APIURL,
 		httpclient: &http.Client{},
 		log:        log.New(os.Stderr, "slack-go/slack", log.LstdFlags|log.Lshortfile),
+		scopes:     &scopeTracker{},
 	}
 
 	for _, opt := range options {
 		opt(s)
 	}
 
+	s.scopes.client = s.httpclient
+	s.httpclient = s.scopes
+
 	return s
 }
 
@@ -144,6 +167,15 @@ func (api *Client) AuthTestContext(ctx context.Context) (response *AuthTestRespo
 	return &responseFull.AuthTestResponse, responseFull.Err()
 }
 
+// GrantedScopes returns the scopes granted to the token, as of the last response of Slack, or
+// nil if they are not known yet.
+func (api *Client) GrantedScopes() []string {
+	if api.scopes == nil {
+		return nil
+	}
+	return api.scopes.grantedScopes(api.token)
+}
+
 // Debugf print a formatted debug line.
 func (api *Client) Debugf(format string, v ...any) {
 	if api.debug {
@@ -0,0 +1,240 @@
+package slacktest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	slack "github.com/incident-io/slack"
+)
+
+const defaultSigningSecret = "slacktest-signing-secret"
+
+// Delivery is the synchronous response of the app under test to a request delivered by the Server.
+type Delivery struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Duration is the time the app took to respond, e.g. to assert on the 3 seconds deadline of Slack
+	Duration time.Duration
+}
+
+// JSON decodes the body of the response into v
+func (d Delivery) JSON(v interface{}) error {
+	return json.Unmarshal(d.Body, v)
+}
+
+type deliveryConfig struct {
+	timestamp   time.Time
+	retryNum    int
+	retryReason string
+	secret      string
+	header      http.Header
+	httpClient  *http.Client
+	skipSigning bool
+}
+
+// DeliveryOption customizes a request delivered by the Server.
+type DeliveryOption func(*deliveryConfig)
+
+// DeliveryOptionRetry sets the X-Slack-Retry-Num and X-Slack-Retry-Reason headers, as Slack does
+// when redelivering an event, e.g. DeliveryOptionRetry(1, "http_timeout").
+func DeliveryOptionRetry(num int, reason string) DeliveryOption {
+	return func(c *deliveryConfig) {
+		c.retryNum = num
+		c.retryReason = reason
+	}
+}
+
+// DeliveryOptionTimestamp signs the request with the timestamp instead of the current time, e.g.
+// to test that stale requests are rejected.
+func DeliveryOptionTimestamp(t time.Time) DeliveryOption {
+	return func(c *deliveryConfig) {
+		c.timestamp = t
+	}
+}
+
+// DeliveryOptionSigningSecret signs the request with the secret instead of the SigningSecret of the Server.
+func DeliveryOptionSigningSecret(secret string) DeliveryOption {
+	return func(c *deliveryConfig) {
+		c.secret = secret
+	}
+}
+
+// DeliveryOptionUnsigned sends the request without the signature headers.
+func DeliveryOptionUnsigned() DeliveryOption {
+	return func(c *deliveryConfig) {
+		c.skipSigning = true
+	}
+}
+
+// DeliveryOptionHeader adds a header to the request.
+func DeliveryOptionHeader(key, value string) DeliveryOption {
+	return func(c *deliveryConfig) {
+		c.header.Add(key, value)
+	}
+}
+
+// DeliveryOptionHTTPClient sends the request with the client, e.g. to set a timeout.
+func DeliveryOptionHTTPClient(client *http.Client) DeliveryOption {
+	return func(c *deliveryConfig) {
+		c.httpClient = client
+	}
+}
+
+// SetSigningSecret sets the secret the delivered requests are signed with
+func (sts *Server) SetSigningSecret(secret string) {
+	sts.mu.Lock()
+	defer sts.mu.Unlock()
+	sts.signingSecret = secret
+}
+
+// SigningSecret returns the secret the delivered requests are signed with, to verify them in the
+// app under test
+func (sts *Server) SigningSecret() string {
+	sts.mu.Lock()
+	defer sts.mu.Unlock()
+	return sts.signingSecret
+}
+
+// DeliverEvent posts the event, e.g. a slackevents.AppMentionEvent, wrapped in an event_callback
+// to the Events API request URL of the app under test.
+func (sts *Server) DeliverEvent(requestURL string, event interface{}, opts ...DeliveryOption) (Delivery, error) {
+	return sts.DeliverEventsAPIPayload(requestURL, eventCallback(event), opts...)
+}
+
+// DeliverURLVerification posts the url_verification challenge sent by Slack when the Events API
+// request URL is configured.
+func (sts *Server) DeliverURLVerification(requestURL, challenge string, opts ...DeliveryOption) (Delivery, error) {
+	return sts.DeliverEventsAPIPayload(requestURL, map[string]interface{}{
+		"token":     "",
+		"type":      "url_verification",
+		"challenge": challenge,
+	}, opts...)
+}
+
+// DeliverEventsAPIPayload posts the payload as is to the Events API request URL of the app under test.
+func (sts *Server) DeliverEventsAPIPayload(requestURL string, payload interface{}, opts ...DeliveryOption) (Delivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Delivery{}, err
+	}
+	return sts.deliver(requestURL, "application/json", body, opts)
+}
+
+// DeliverSlashCommand posts the command form to the request URL of the slash command.
+func (sts *Server) DeliverSlashCommand(requestURL string, cmd slack.SlashCommand, opts ...DeliveryOption) (Delivery, error) {
+	form := url.Values{
+		"token":                 {cmd.Token},
+		"team_id":               {cmd.TeamID},
+		"team_domain":           {cmd.TeamDomain},
+		"enterprise_id":         {cmd.EnterpriseID},
+		"enterprise_name":       {cmd.EnterpriseName},
+		"is_enterprise_install": {strconv.FormatBool(cmd.IsEnterpriseInstall)},
+		"channel_id":            {cmd.ChannelID},
+		"channel_name":          {cmd.ChannelName},
+		"user_id":               {cmd.UserID},
+		"user_name":             {cmd.UserName},
+		"command":               {cmd.Command},
+		"text":                  {cmd.Text},
+		"response_url":          {cmd.ResponseURL},
+		"trigger_id":            {cmd.TriggerID},
+		"api_app_id":            {cmd.APIAppID},
+	}
+	return sts.deliver(requestURL, "application/x-www-form-urlencoded", []byte(form.Encode()), opts)
+}
+
+// DeliverInteraction posts the callback, in the payload field of a form, to the interactivity request URL.
+func (sts *Server) DeliverInteraction(requestURL string, callback slack.InteractionCallback, opts ...DeliveryOption) (Delivery, error) {
+	payload, err := json.Marshal(&callback)
+	if err != nil {
+		return Delivery{}, err
+	}
+	form := url.Values{"payload": {string(payload)}}
+	return sts.deliver(requestURL, "application/x-www-form-urlencoded", []byte(form.Encode()), opts)
+}
+
+func (sts *Server) deliver(requestURL, contentType string, body []byte, opts []DeliveryOption) (Delivery, error) {
+	config := deliveryConfig{
+		timestamp:  time.Now(),
+		secret:     sts.SigningSecret(),
+		header:     http.Header{},
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return Delivery{}, err
+	}
+	for k, v := range config.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "Slackbot 1.0 (+https://api.slack.com/robots)")
+	if !config.skipSigning {
+		timestamp := strconv.FormatInt(config.timestamp.Unix(), 10)
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", sign(config.secret, timestamp, body))
+	}
+	if config.retryNum > 0 {
+		req.Header.Set("X-Slack-Retry-Num", strconv.Itoa(config.retryNum))
+		req.Header.Set("X-Slack-Retry-Reason", config.retryReason)
+	}
+
+	start := time.Now()
+	resp, err := config.httpClient.Do(req)
+	if err != nil {
+		return Delivery{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	return Delivery{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// sign computes the X-Slack-Signature of a request, as verified by slack.NewSecretsVerifier
+func sign(secret, timestamp string, body []byte) string {
+	hash := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(hash, "v0:%s:", timestamp)
+	_, _ = hash.Write(body)
+	return "v0=" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// nextEventID numbers the events, so that their IDs are unique even within a second
+var nextEventID uint64
+
+// eventCallback wraps the event as Slack does in Events API requests and events_api envelopes
+func eventCallback(event interface{}) map[string]interface{} {
+	now := time.Now().Unix()
+
+	return map[string]interface{}{
+		"token":      "",
+		"team_id":    defaultTeamID,
+		"api_app_id": defaultAppID,
+		"event":      event,
+		"type":       "event_callback",
+		"event_id":   fmt.Sprintf("Ev%d", atomic.AddUint64(&nextEventID, 1)),
+		"event_time": now,
+	}
+}
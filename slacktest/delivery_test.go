@@ -0,0 +1,104 @@
+package slacktest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	slack "github.com/incident-io/slack"
+	"github.com/incident-io/slack/app"
+	"github.com/incident-io/slack/slackevents"
+)
+
+func newDeliveryTestApp(t *testing.T, s *Server) (*app.App, string) {
+	a := app.New()
+	mux := http.NewServeMux()
+	mux.Handle("/events", a.EventsHandler(s.SigningSecret()))
+	mux.Handle("/commands", a.SlashCommandsHandler(s.SigningSecret()))
+	mux.Handle("/interactions", a.InteractionsHandler(s.SigningSecret()))
+
+	target := httptest.NewServer(mux)
+	t.Cleanup(target.Close)
+	return a, target.URL
+}
+
+func TestDeliverEvent(t *testing.T) {
+	s := NewTestServer()
+	s.SetSigningSecret("test-secret")
+	a, target := newDeliveryTestApp(t, s)
+
+	var received *app.Request
+	a.HandleEvent(slackevents.AppMention, func(ctx context.Context, req *app.Request) (interface{}, error) {
+		received = req
+		return nil, nil
+	})
+
+	d, err := s.DeliverURLVerification(target+"/events", "challenge-accepted")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, d.StatusCode)
+	assert.Equal(t, "challenge-accepted", string(d.Body))
+
+	d, err = s.DeliverEvent(target+"/events", &slackevents.AppMentionEvent{Type: "app_mention", Text: "<@U1> hi"},
+		DeliveryOptionRetry(2, "http_timeout"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, d.StatusCode)
+	if assert.NotNil(t, received) {
+		assert.Equal(t, 2, received.RetryAttempt)
+		assert.Equal(t, "http_timeout", received.RetryReason)
+		mention := received.EventsAPIEvent.InnerEvent.Data.(*slackevents.AppMentionEvent)
+		assert.Equal(t, "<@U1> hi", mention.Text)
+	}
+}
+
+func TestEventCallbackIDsAreUnique(t *testing.T) {
+	first, second := eventCallback(nil), eventCallback(nil)
+	assert.NotEqual(t, first["event_id"], second["event_id"])
+}
+
+func TestDeliverSignatureFailures(t *testing.T) {
+	s := NewTestServer()
+	_, target := newDeliveryTestApp(t, s)
+
+	for name, opt := range map[string]DeliveryOption{
+		"unsigned":        DeliveryOptionUnsigned(),
+		"wrong secret":    DeliveryOptionSigningSecret("wrong"),
+		"stale timestamp": DeliveryOptionTimestamp(time.Now().Add(-10 * time.Minute)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, err := s.DeliverEvent(target+"/events", &slackevents.AppMentionEvent{Type: "app_mention"}, opt)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusUnauthorized, d.StatusCode)
+		})
+	}
+}
+
+func TestDeliverSlashCommandAndInteraction(t *testing.T) {
+	s := NewTestServer()
+	a, target := newDeliveryTestApp(t, s)
+
+	a.HandleSlashCommand("/incident", func(ctx context.Context, req *app.Request) (interface{}, error) {
+		return &slack.Msg{Text: "declared " + req.SlashCommand.Text + " in " + req.SlashCommand.ChannelID}, nil
+	})
+	a.HandleShortcut("declare", func(ctx context.Context, req *app.Request) (interface{}, error) {
+		return &slack.Msg{Text: "shortcut from " + req.Interaction.User.ID}, nil
+	})
+
+	d, err := s.DeliverSlashCommand(target+"/commands", slack.SlashCommand{Command: "/incident", Text: "outage", ChannelID: "C1"})
+	require.NoError(t, err)
+	var msg slack.Msg
+	require.NoError(t, d.JSON(&msg))
+	assert.Equal(t, "declared outage in C1", msg.Text)
+	assert.True(t, d.Duration > 0)
+
+	callback := slack.InteractionCallback{Type: slack.InteractionTypeShortcut, CallbackID: "declare"}
+	callback.User.ID = "U1"
+	d, err = s.DeliverInteraction(target+"/interactions", callback)
+	require.NoError(t, err)
+	require.NoError(t, d.JSON(&msg))
+	assert.Equal(t, "shortcut from U1", msg.Text)
+}
@@ -0,0 +1,206 @@
+package slacktest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	websocket "github.com/gorilla/websocket"
+)
+
+// FaultKind is the kind of failure injected by a Fault.
+type FaultKind int
+
+const (
+	// FaultLatency only delays the request, which is then served normally. It is the zero value, so
+	// that a Fault with only a Delay doesn't fail the request.
+	FaultLatency FaultKind = iota
+	// FaultRateLimited responds with HTTP 429, a Retry-After header and the "ratelimited" error.
+	FaultRateLimited
+	// FaultServerError responds with an HTTP 5xx status and an HTML body, as a load balancer would.
+	FaultServerError
+	// FaultAPIError responds with "ok": false and the error code of the fault.
+	FaultAPIError
+	// FaultMalformedJSON responds with HTTP 200 and a truncated JSON body.
+	FaultMalformedJSON
+	// FaultDropConnection closes the connection without responding.
+	FaultDropConnection
+)
+
+// Fault describes failures to inject into the requests to a Server.
+type Fault struct {
+	// Method is the API method to fail, e.g. "chat.postMessage", or a path.Match pattern such
+	// as "conversations.*" or "*".
+	Method string
+	// Kind is the failure to inject. Defaults to FaultLatency.
+	Kind FaultKind
+
+	// RetryAfter is the Retry-After of FaultRateLimited. Defaults to 1s.
+	RetryAfter time.Duration
+	// StatusCode is the status of FaultServerError. Defaults to 500.
+	StatusCode int
+	// Error is the error code of FaultAPIError. Defaults to "internal_error".
+	Error string
+	// Delay delays the response, before the fault is applied.
+	Delay time.Duration
+
+	// Times is the number of requests the fault applies to, after which it is removed.
+	// Zero applies it to every matching request.
+	Times int
+	// Probability is the probability with which the fault applies to a matching request.
+	// Zero applies it to every matching request.
+	Probability float64
+}
+
+type faultInjector struct {
+	sync.Mutex
+	faults []*Fault
+	rand   *rand.Rand
+	// websockets are the open RTM connections, closed by CloseWebsockets
+	websockets map[*websocket.Conn]struct{}
+}
+
+func newFaultInjector() *faultInjector {
+	return &faultInjector{
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		websockets: map[*websocket.Conn]struct{}{},
+	}
+}
+
+// InjectFault adds a fault to the requests to the server. The faults are tried in the order
+// they were injected, and the first one that applies to a request is used.
+//
+// For example, to fail the first 2 calls to chat.postMessage as rate limited:
+//
+//	s.InjectFault(slacktest.Fault{Method: "chat.postMessage", Kind: slacktest.FaultRateLimited, Times: 2})
+func (sts *Server) InjectFault(f Fault) {
+	sts.faults.Lock()
+	defer sts.faults.Unlock()
+	sts.faults.faults = append(sts.faults.faults, &f)
+}
+
+// ClearFaults removes all the injected faults
+func (sts *Server) ClearFaults() {
+	sts.faults.Lock()
+	defer sts.faults.Unlock()
+	sts.faults.faults = nil
+}
+
+// SetFaultSeed seeds the random source of Fault.Probability, for reproducible tests
+func (sts *Server) SetFaultSeed(seed int64) {
+	sts.faults.Lock()
+	defer sts.faults.Unlock()
+	sts.faults.rand = rand.New(rand.NewSource(seed))
+}
+
+// CloseWebsockets closes the RTM and Socket Mode websockets of all the connected clients mid-stream
+func (sts *Server) CloseWebsockets() {
+	sts.faults.Lock()
+	for c := range sts.faults.websockets {
+		_ = c.Close()
+	}
+	sts.faults.Unlock()
+
+	sts.CloseSocketModeConnections()
+}
+
+func (fi *faultInjector) trackWebsocket(c *websocket.Conn) func() {
+	fi.Lock()
+	fi.websockets[c] = struct{}{}
+	fi.Unlock()
+
+	return func() {
+		fi.Lock()
+		delete(fi.websockets, c)
+		fi.Unlock()
+	}
+}
+
+// match returns the fault to apply to a request to the method, if any
+func (fi *faultInjector) match(method string) *Fault {
+	fi.Lock()
+	defer fi.Unlock()
+
+	for i, f := range fi.faults {
+		if ok, _ := path.Match(f.Method, method); !ok {
+			continue
+		}
+		if f.Probability > 0 && fi.rand.Float64() >= f.Probability {
+			continue
+		}
+
+		applied := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				fi.faults = append(fi.faults[:i], fi.faults[i+1:]...)
+			}
+		}
+		return &applied
+	}
+	return nil
+}
+
+// faultHandler applies the injected faults before serving the requests with next
+func (fi *faultInjector) faultHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := fi.match(strings.TrimPrefix(r.URL.Path, "/"))
+		if f == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		switch f.Kind {
+		case FaultRateLimited:
+			retryAfter := f.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = time.Second
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error":"ratelimited"}`))
+		case FaultServerError:
+			code := f.StatusCode
+			if code == 0 {
+				code = http.StatusInternalServerError
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(code)
+			_, _ = fmt.Fprintf(w, "<html><body><h1>%d %s</h1></body></html>", code, http.StatusText(code))
+		case FaultAPIError:
+			code := f.Error
+			if code == "" {
+				code = "internal_error"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `{"ok":false,"error":%q}`, code)
+		case FaultMalformedJSON:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok":tru`))
+		case FaultDropConnection:
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					_ = conn.Close()
+					return
+				}
+			}
+			panic(http.ErrAbortHandler)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package slacktest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	slack "github.com/incident-io/slack"
+	"github.com/incident-io/slack/socketmode"
+)
+
+func newFaultTestServer(t *testing.T) (*Server, *slack.Client) {
+	s := NewTestServer()
+	go s.Start()
+	t.Cleanup(s.Stop)
+
+	return s, slack.New("ABCDEFG", slack.OptionAPIURL(s.GetAPIURL()))
+}
+
+func TestFaultRateLimitedTimes(t *testing.T) {
+	s, client := newFaultTestServer(t)
+	s.InjectFault(Fault{Method: "chat.postMessage", Kind: FaultRateLimited, RetryAfter: 2 * time.Second, Times: 2})
+
+	for i := 0; i < 2; i++ {
+		_, _, err := client.PostMessage("C1", slack.MsgOptionText("hello", false))
+		var rateLimited *slack.RateLimitedError
+		if assert.True(t, errors.As(err, &rateLimited), "expected a rate limit error, got %v", err) {
+			assert.Equal(t, 2*time.Second, rateLimited.RetryAfter)
+		}
+	}
+
+	_, _, err := client.PostMessage("C1", slack.MsgOptionText("hello", false))
+	assert.NoError(t, err, "the fault should only apply twice")
+}
+
+func TestFaultKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault Fault
+		check func(t *testing.T, err error)
+	}{
+		{
+			name:  "server error",
+			fault: Fault{Kind: FaultServerError, StatusCode: http.StatusBadGateway},
+			check: func(t *testing.T, err error) {
+				var statusErr slack.StatusCodeError
+				if assert.True(t, errors.As(err, &statusErr), "got %v", err) {
+					assert.Equal(t, http.StatusBadGateway, statusErr.Code)
+				}
+			},
+		},
+		{
+			name:  "api error",
+			fault: Fault{Kind: FaultAPIError, Error: "invalid_auth"},
+			check: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "invalid_auth")
+			},
+		},
+		{
+			name:  "malformed json",
+			fault: Fault{Kind: FaultMalformedJSON},
+			check: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:  "dropped connection",
+			fault: Fault{Kind: FaultDropConnection},
+			check: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, client := newFaultTestServer(t)
+			tt.fault.Method = "auth.*"
+			s.InjectFault(tt.fault)
+
+			_, err := client.AuthTest()
+			tt.check(t, err)
+
+			s.ClearFaults()
+			_, err = client.AuthTest()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFaultLatency(t *testing.T) {
+	s, client := newFaultTestServer(t)
+	s.InjectFault(Fault{Method: "auth.test", Kind: FaultLatency, Delay: 100 * time.Millisecond})
+
+	start := time.Now()
+	_, err := client.AuthTest()
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond, "the response should be delayed")
+}
+
+func TestFaultDelayOnly(t *testing.T) {
+	s, client := newFaultTestServer(t)
+	s.InjectFault(Fault{Method: "auth.test", Delay: 50 * time.Millisecond})
+
+	start := time.Now()
+	_, err := client.AuthTest()
+	assert.NoError(t, err, "a fault without a kind should only delay the request")
+	assert.True(t, time.Since(start) >= 50*time.Millisecond, "the response should be delayed")
+}
+
+func TestFaultProbability(t *testing.T) {
+	s, client := newFaultTestServer(t)
+	s.SetFaultSeed(42)
+	s.InjectFault(Fault{Method: "auth.test", Kind: FaultAPIError, Probability: 0.5})
+
+	failed := 0
+	for i := 0; i < 100; i++ {
+		if _, err := client.AuthTest(); err != nil {
+			failed++
+		}
+	}
+	assert.True(t, failed > 20 && failed < 80, "expected about half the calls to fail, got %d", failed)
+}
+
+func TestCloseWebsockets(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	client := newSocketModeTestClient(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunContext(ctx)
+
+	connected := 0
+	timeout := time.After(2 * time.Second)
+	for connected < 2 {
+		select {
+		case evt := <-client.Events:
+			if evt.Type == socketmode.EventTypeConnected {
+				connected++
+				if connected == 1 {
+					s.CloseWebsockets()
+				}
+			}
+		case <-timeout:
+			t.Fatal("expected the client to reconnect")
+		}
+	}
+}
@@ -3,6 +3,7 @@ package slacktest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -279,6 +280,8 @@ func rtmStartHandler(w http.ResponseWriter, r *http.Request) {
 
 func (sts *Server) wsHandler(w http.ResponseWriter, r *http.Request) {
 	Websocket(func(c *websocket.Conn) {
+		defer sts.faults.trackWebsocket(c)()
+
 		serverAddr := r.Context().Value(ServerBotHubNameContextKey).(string)
 		go handlePendingMessages(c, serverAddr)
 		for {
@@ -289,7 +292,10 @@ func (sts *Server) wsHandler(w http.ResponseWriter, r *http.Request) {
 			)
 
 			if mtype, m, err = RTMRespEventType(c); err != nil {
-				if websocket.IsUnexpectedCloseError(err) {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+					// The connection is closed or broken, e.g. by CloseWebsockets
 					return
 				}
 
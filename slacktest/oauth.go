@@ -0,0 +1,469 @@
+package slacktest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTokenExpiry = 12 * time.Hour
+	openIDIssuer       = "https://slack.com"
+)
+
+// OAuthApp is an app registered with an OAuthServer.
+type OAuthApp struct {
+	ClientID     string
+	ClientSecret string
+	// AppID defaults to the app ID of the Socket Mode server
+	AppID string
+	// RedirectURIs are the allowed redirect URIs. Any redirect URI is allowed when empty.
+	RedirectURIs []string
+	// Scopes are the bot scopes granted on install
+	Scopes []string
+	// UserScopes are the user scopes granted on install. No user token is issued when empty.
+	UserScopes []string
+	// TokenRotation issues expiring access tokens, with refresh tokens
+	TokenRotation bool
+}
+
+// OAuthGrant is the approval of an OAuth or Sign in with Slack request by a user, exchanged for
+// tokens with the code returned by OAuthServer.Authorize.
+type OAuthGrant struct {
+	ClientID    string
+	RedirectURI string
+	// TeamID defaults to the team of the test server
+	TeamID string
+	// UserID defaults to the non bot user of the test server
+	UserID string
+	// Nonce is returned in the id_token of Sign in with Slack
+	Nonce string
+	Email string
+	Name  string
+}
+
+// OAuthServer is an in-memory registry of OAuth apps and the tokens they were issued. Bind it to
+// a test server with NewTestServer(o.Bind) to implement oauth.v2.access, openid.connect.token,
+// openid.connect.userInfo and auth.revoke. The package-level functions of slack, such as
+// slack.GetOAuthV2Response, reach the test server with the client of Server.HTTPClient.
+type OAuthServer struct {
+	mu sync.Mutex
+
+	apps          map[string]OAuthApp
+	codes         map[string]OAuthGrant
+	tokens        map[string]*oauthToken
+	refreshTokens map[string]*oauthToken
+	expiry        time.Duration
+	nextID        int
+
+	key   *rsa.PrivateKey
+	keyID string
+}
+
+type oauthToken struct {
+	token        string
+	refreshToken string
+	clientID     string
+	grant        OAuthGrant
+	scopes       []string
+	expiresAt    time.Time
+	revoked      bool
+}
+
+// NewOAuthServer returns an OAuthServer without any app, signing the id_tokens with a new test key.
+func NewOAuthServer() *OAuthServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("unable to generate the id_token key: %s", err.Error()))
+	}
+
+	return &OAuthServer{
+		apps:          map[string]OAuthApp{},
+		codes:         map[string]OAuthGrant{},
+		tokens:        map[string]*oauthToken{},
+		refreshTokens: map[string]*oauthToken{},
+		expiry:        defaultTokenExpiry,
+		key:           key,
+		keyID:         "slacktest-key",
+	}
+}
+
+// AddApp registers the app
+func (o *OAuthServer) AddApp(app OAuthApp) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if app.AppID == "" {
+		app.AppID = defaultAppID
+	}
+	o.apps[app.ClientID] = app
+}
+
+// SetTokenExpiry sets the lifetime of the access tokens of the apps with token rotation, and of the
+// id_tokens. Defaults to 12 hours.
+func (o *OAuthServer) SetTokenExpiry(d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.expiry = d
+}
+
+// ExpireTokens expires all the access tokens issued so far, e.g. to test the refresh of tokens
+func (o *OAuthServer) ExpireTokens() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	expired := time.Now().Add(-time.Second)
+	for _, t := range o.tokens {
+		t.expiresAt = expired
+	}
+}
+
+// Authorize returns a code to exchange for tokens, as Slack does when the user approves the install
+// of an app or signs in with Slack.
+func (o *OAuthServer) Authorize(grant OAuthGrant) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if grant.TeamID == "" {
+		grant.TeamID = defaultTeamID
+	}
+	if grant.UserID == "" {
+		grant.UserID = defaultNonBotUserID
+	}
+
+	code := o.newID("code")
+	o.codes[code] = grant
+	return code
+}
+
+// TokenActive reports whether the access token was issued, and is neither expired nor revoked
+func (o *OAuthServer) TokenActive(token string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	t, ok := o.tokens[token]
+	return ok && t.active()
+}
+
+// PublicKey returns the key the id_tokens are signed with, also served as a JWKS at
+// /openid/connect/keys.
+func (o *OAuthServer) PublicKey() (keyID string, key *rsa.PublicKey) {
+	return o.keyID, &o.key.PublicKey
+}
+
+// Bind implements the OAuth methods on the test server
+func (o *OAuthServer) Bind(c Customize) {
+	c.Handle("/oauth.v2.access", o.handler(o.oauthV2Access))
+	c.Handle("/openid.connect.token", o.handler(o.openIDConnectToken))
+	c.Handle("/openid.connect.userInfo", o.handler(o.openIDConnectUserInfo))
+	c.Handle("/auth.revoke", o.handler(o.authRevoke))
+	c.Handle("/openid/connect/keys", o.jwksHandler)
+}
+
+// oauthMethod implements a Web API method, as workspaceMethod does
+type oauthMethod func(r *http.Request) (map[string]interface{}, workspaceError)
+
+func (o *OAuthServer) handler(method oauthMethod) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, fmt.Sprintf("Unable to decode form: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		o.mu.Lock()
+		response, errCode := method(r)
+		o.mu.Unlock()
+
+		if errCode != "" {
+			response = map[string]interface{}{"error": string(errCode)}
+		}
+		if response == nil {
+			response = map[string]interface{}{}
+		}
+		response["ok"] = errCode == ""
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (o *OAuthServer) jwksHandler(w http.ResponseWriter, _ *http.Request) {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": o.keyID,
+			"n":   encode(o.key.PublicKey.N.Bytes()),
+			"e":   encode(big.NewInt(int64(o.key.PublicKey.E)).Bytes()),
+		}},
+	})
+}
+
+// client authenticates the app with the form or HTTP basic auth, as Slack accepts both
+func (o *OAuthServer) client(r *http.Request) (OAuthApp, workspaceError) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+	}
+
+	app, ok := o.apps[clientID]
+	if !ok {
+		return OAuthApp{}, "invalid_client_id"
+	}
+	if app.ClientSecret != clientSecret {
+		return OAuthApp{}, "bad_client_secret"
+	}
+	return app, ""
+}
+
+// redeem consumes the code of the request, which can only be used once
+func (o *OAuthServer) redeem(r *http.Request, app OAuthApp) (OAuthGrant, workspaceError) {
+	code := r.Form.Get("code")
+	grant, ok := o.codes[code]
+	if !ok || grant.ClientID != app.ClientID {
+		return OAuthGrant{}, "invalid_code"
+	}
+	delete(o.codes, code)
+
+	redirectURI := r.Form.Get("redirect_uri")
+	if grant.RedirectURI != "" && redirectURI != grant.RedirectURI {
+		return OAuthGrant{}, "bad_redirect_uri"
+	}
+	if len(app.RedirectURIs) > 0 && redirectURI != "" && !contains(app.RedirectURIs, redirectURI) {
+		return OAuthGrant{}, "bad_redirect_uri"
+	}
+	return grant, ""
+}
+
+func (o *OAuthServer) oauthV2Access(r *http.Request) (map[string]interface{}, workspaceError) {
+	app, errCode := o.client(r)
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	switch r.Form.Get("grant_type") {
+	case "", "authorization_code":
+		grant, errCode := o.redeem(r, app)
+		if errCode != "" {
+			return nil, errCode
+		}
+
+		response := map[string]interface{}{
+			"app_id":                app.AppID,
+			"bot_user_id":           defaultBotID,
+			"team":                  map[string]string{"id": grant.TeamID, "name": defaultTeamName},
+			"enterprise":            nil,
+			"is_enterprise_install": false,
+			"authed_user":           map[string]interface{}{"id": grant.UserID},
+		}
+		if len(app.Scopes) > 0 {
+			bot := o.issue(app, grant, "xoxb", app.Scopes)
+			for k, v := range bot.fields() {
+				response[k] = v
+			}
+		}
+		if len(app.UserScopes) > 0 {
+			user := o.issue(app, grant, "xoxp", app.UserScopes)
+			authedUser := user.fields()
+			authedUser["id"] = grant.UserID
+			response["authed_user"] = authedUser
+		}
+		return response, ""
+	case "refresh_token":
+		previous, ok := o.refreshTokens[r.Form.Get("refresh_token")]
+		if !ok || previous.clientID != app.ClientID || previous.revoked {
+			return nil, "invalid_refresh_token"
+		}
+		// The refresh token is rotated too, the previous access token stays valid until it expires
+		delete(o.refreshTokens, previous.refreshToken)
+
+		prefix := strings.SplitN(strings.TrimPrefix(previous.token, "xoxe."), "-", 2)[0]
+		refreshed := o.issue(app, previous.grant, prefix, previous.scopes)
+		response := refreshed.fields()
+		response["app_id"] = app.AppID
+		response["team"] = map[string]string{"id": previous.grant.TeamID, "name": defaultTeamName}
+		if prefix == "xoxb" {
+			response["bot_user_id"] = defaultBotID
+		} else {
+			response["user_id"] = previous.grant.UserID
+		}
+		return response, ""
+	default:
+		return nil, "invalid_grant_type"
+	}
+}
+
+func (o *OAuthServer) openIDConnectToken(r *http.Request) (map[string]interface{}, workspaceError) {
+	app, errCode := o.client(r)
+	if errCode != "" {
+		return nil, errCode
+	}
+	if r.Form.Get("grant_type") == "refresh_token" {
+		return nil, "invalid_grant_type"
+	}
+
+	grant, errCode := o.redeem(r, app)
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	token := o.issue(app, grant, "xoxp", []string{"openid", "email", "profile"})
+	idToken, err := o.idToken(app, grant)
+	if err != nil {
+		return nil, "internal_error"
+	}
+
+	response := token.fields()
+	response["token_type"] = "Bearer"
+	response["id_token"] = idToken
+	return response, ""
+}
+
+func (o *OAuthServer) openIDConnectUserInfo(r *http.Request) (map[string]interface{}, workspaceError) {
+	t, errCode := o.authenticate(r)
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	claims := o.claims(t.grant)
+	delete(claims, "iss")
+	return claims, ""
+}
+
+func (o *OAuthServer) authRevoke(r *http.Request) (map[string]interface{}, workspaceError) {
+	t, errCode := o.authenticate(r)
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	if r.Form.Get("test") == "true" || r.Form.Get("test") == "1" {
+		return map[string]interface{}{"revoked": false}, ""
+	}
+	t.revoked = true
+	return map[string]interface{}{"revoked": true}, ""
+}
+
+// authenticate returns the active token of the request
+func (o *OAuthServer) authenticate(r *http.Request) (*oauthToken, workspaceError) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.Form.Get("token")
+	}
+	if token == "" {
+		return nil, "not_authed"
+	}
+
+	t, ok := o.tokens[token]
+	switch {
+	case !ok:
+		return nil, "invalid_auth"
+	case t.revoked:
+		return nil, "token_revoked"
+	case !t.active():
+		return nil, "token_expired"
+	}
+	return t, ""
+}
+
+// issue returns a new access token, with a refresh token if the app rotates its tokens
+func (o *OAuthServer) issue(app OAuthApp, grant OAuthGrant, prefix string, scopes []string) *oauthToken {
+	t := &oauthToken{
+		token:    fmt.Sprintf("%s-%s", prefix, o.newID(grant.TeamID)),
+		clientID: app.ClientID,
+		grant:    grant,
+		scopes:   scopes,
+	}
+	if app.TokenRotation {
+		t.token = "xoxe." + t.token
+		t.refreshToken = "xoxe-1-" + o.newID("refresh")
+		t.expiresAt = time.Now().Add(o.expiry)
+		o.refreshTokens[t.refreshToken] = t
+	}
+	o.tokens[t.token] = t
+	return t
+}
+
+func (o *OAuthServer) newID(prefix string) string {
+	o.nextID++
+	return fmt.Sprintf("%s-%08d", prefix, o.nextID)
+}
+
+func (t *oauthToken) active() bool {
+	return !t.revoked && (t.expiresAt.IsZero() || time.Now().Before(t.expiresAt))
+}
+
+// fields returns the fields of the token in an oauth.v2.access response
+func (t *oauthToken) fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"access_token": t.token,
+		"token_type":   "bot",
+		"scope":        strings.Join(t.scopes, ","),
+	}
+	if strings.Contains(t.token, "xoxp-") {
+		fields["token_type"] = "user"
+	}
+	if t.refreshToken != "" {
+		fields["refresh_token"] = t.refreshToken
+		fields["expires_in"] = int(time.Until(t.expiresAt).Seconds())
+	}
+	return fields
+}
+
+// claims returns the OpenID Connect claims of the user of the grant
+func (o *OAuthServer) claims(grant OAuthGrant) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                           openIDIssuer,
+		"sub":                           grant.UserID,
+		"https://slack.com/user_id":     grant.UserID,
+		"https://slack.com/team_id":     grant.TeamID,
+		"https://slack.com/team_name":   defaultTeamName,
+		"https://slack.com/team_domain": defaultTeamDomain,
+		"email":                         grant.Email,
+		"email_verified":                grant.Email != "",
+		"name":                          grant.Name,
+	}
+}
+
+// idToken returns the id_token of the grant, signed with RS256
+func (o *OAuthServer) idToken(app OAuthApp, grant OAuthGrant) (string, error) {
+	now := time.Now()
+	claims := o.claims(grant)
+	claims["aud"] = app.ClientID
+	claims["iat"] = now.Unix()
+	claims["auth_time"] = now.Unix()
+	claims["exp"] = now.Add(o.expiry).Unix()
+	claims["jti"] = o.newID("jti")
+	if grant.Nonce != "" {
+		claims["nonce"] = grant.Nonce
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": o.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, o.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,115 @@
+package slacktest
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	slack "github.com/incident-io/slack"
+)
+
+func newOAuthTestServer(t *testing.T, app OAuthApp) (*OAuthServer, *Server) {
+	o := NewOAuthServer()
+	o.AddApp(app)
+
+	s := NewTestServer(o.Bind)
+	go s.Start()
+	t.Cleanup(s.Stop)
+
+	return o, s
+}
+
+func TestOAuthV2CodeExchangeAndRefresh(t *testing.T) {
+	o, s := newOAuthTestServer(t, OAuthApp{
+		ClientID:      "client",
+		ClientSecret:  "secret",
+		RedirectURIs:  []string{"https://example.com/oauth"},
+		Scopes:        []string{"chat:write", "channels:read"},
+		UserScopes:    []string{"search:read"},
+		TokenRotation: true,
+	})
+
+	code := o.Authorize(OAuthGrant{ClientID: "client", UserID: "U1"})
+	_, err := slack.GetOAuthV2Response(s.HTTPClient(), "client", "wrong", code, "")
+	assert.EqualError(t, err, "bad_client_secret")
+
+	resp, err := slack.GetOAuthV2Response(s.HTTPClient(), "client", "secret", code, "https://example.com/oauth")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.AccessToken, "xoxe.xoxb-"))
+	assert.Equal(t, "chat:write,channels:read", resp.Scope)
+	assert.Equal(t, defaultTeamID, resp.Team.ID)
+	assert.Equal(t, "U1", resp.AuthedUser.ID)
+	assert.True(t, strings.HasPrefix(resp.AuthedUser.AccessToken, "xoxe.xoxp-"))
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.InDelta(t, defaultTokenExpiry.Seconds(), resp.ExpiresIn, 5)
+
+	_, err = slack.GetOAuthV2Response(s.HTTPClient(), "client", "secret", code, "")
+	assert.EqualError(t, err, "invalid_code", "codes should only be redeemed once")
+
+	o.ExpireTokens()
+	assert.False(t, o.TokenActive(resp.AccessToken))
+
+	refreshed, err := slack.RefreshOAuthV2Token(s.HTTPClient(), "client", "secret", resp.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, resp.AccessToken, refreshed.AccessToken)
+	assert.NotEqual(t, resp.RefreshToken, refreshed.RefreshToken)
+	assert.True(t, o.TokenActive(refreshed.AccessToken))
+
+	_, err = slack.RefreshOAuthV2Token(s.HTTPClient(), "client", "secret", resp.RefreshToken)
+	assert.EqualError(t, err, "invalid_refresh_token", "refresh tokens should be rotated")
+
+	api := slack.New(refreshed.AccessToken, slack.OptionAPIURL(s.GetAPIURL()))
+	revoked, err := api.SendAuthRevoke("")
+	require.NoError(t, err)
+	assert.True(t, revoked.Revoked)
+	assert.False(t, o.TokenActive(refreshed.AccessToken))
+}
+
+func TestOpenIDConnect(t *testing.T) {
+	o, s := newOAuthTestServer(t, OAuthApp{ClientID: "client", ClientSecret: "secret"})
+
+	code := o.Authorize(OAuthGrant{ClientID: "client", UserID: "U1", Nonce: "n-1", Email: "alice@example.com", Name: "Alice"})
+	resp, err := slack.GetOpenIDConnectToken(s.HTTPClient(), "client", "secret", code, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", resp.TokenType)
+
+	parts := strings.Split(resp.IdToken, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	_, key := o.PublicKey()
+	assert.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	assert.Equal(t, "https://slack.com", claims["iss"])
+	assert.Equal(t, "client", claims["aud"])
+	assert.Equal(t, "U1", claims["sub"])
+	assert.Equal(t, "n-1", claims["nonce"])
+	assert.Equal(t, "alice@example.com", claims["email"])
+
+	req, err := http.NewRequest(http.MethodPost, s.GetAPIURL()+"openid.connect.userInfo", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+resp.AccessToken)
+	userInfo, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer userInfo.Body.Close()
+
+	var info map[string]interface{}
+	require.NoError(t, json.NewDecoder(userInfo.Body).Decode(&info))
+	assert.Equal(t, true, info["ok"])
+	assert.Equal(t, "Alice", info["name"])
+	assert.Equal(t, defaultTeamID, info["https://slack.com/team_id"])
+}
@@ -0,0 +1,231 @@
+package slacktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	slack "github.com/incident-io/slack"
+)
+
+// Call is a request received by a Server.
+type Call struct {
+	// Method is the API method, e.g. "chat.postMessage"
+	Method string
+	// Params are the decoded form, query or JSON parameters. The JSON values that are not
+	// strings are kept encoded.
+	Params url.Values
+	// Token is the bearer token of the request, or its token parameter
+	Token  string
+	Header http.Header
+	Body   []byte
+	Time   time.Time
+}
+
+// Param returns the value of the parameter, or "" if it was not set
+func (c Call) Param(key string) string {
+	return c.Params.Get(key)
+}
+
+// Blocks decodes the blocks parameter, e.g. of chat.postMessage
+func (c Call) Blocks() (slack.Blocks, error) {
+	var blocks slack.Blocks
+	if raw := c.Param("blocks"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &blocks); err != nil {
+			return blocks, err
+		}
+	}
+	return blocks, nil
+}
+
+// Attachments decodes the attachments parameter, e.g. of chat.postMessage
+func (c Call) Attachments() ([]slack.Attachment, error) {
+	var attachments []slack.Attachment
+	if raw := c.Param("attachments"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &attachments); err != nil {
+			return nil, err
+		}
+	}
+	return attachments, nil
+}
+
+// CallMatcher selects calls in RequireCalled and RequireNotCalled
+type CallMatcher func(Call) bool
+
+// WithParam matches the calls whose parameter has the value
+func WithParam(key, value string) CallMatcher {
+	return func(c Call) bool {
+		return c.Param(key) == value
+	}
+}
+
+// WithToken matches the calls made with the token
+func WithToken(token string) CallMatcher {
+	return func(c Call) bool {
+		return c.Token == token
+	}
+}
+
+// WithBlocks matches the calls whose blocks match
+func WithBlocks(match func(slack.Blocks) bool) CallMatcher {
+	return func(c Call) bool {
+		blocks, err := c.Blocks()
+		return err == nil && match(blocks)
+	}
+}
+
+type callRecorder struct {
+	sync.RWMutex
+	calls []Call
+}
+
+// recordHandler records the requests before serving them with next
+func (cr *callRecorder) recordHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error reading body: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		call := Call{
+			Method: strings.TrimPrefix(r.URL.Path, "/"),
+			Params: decodeParams(r, body),
+			Header: r.Header.Clone(),
+			Body:   body,
+			Time:   time.Now(),
+		}
+		call.Token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if call.Token == "" {
+			call.Token = call.Param("token")
+		}
+
+		cr.Lock()
+		cr.calls = append(cr.calls, call)
+		cr.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func decodeParams(r *http.Request, body []byte) url.Values {
+	params := url.Values{}
+	for k, v := range r.URL.Query() {
+		params[k] = append(params[k], v...)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(body, &fields) == nil {
+			for k, raw := range fields {
+				var s string
+				if json.Unmarshal(raw, &s) == nil {
+					params.Add(k, s)
+				} else {
+					params.Add(k, string(raw))
+				}
+			}
+		}
+		return params
+	}
+
+	if form, err := url.ParseQuery(string(body)); err == nil {
+		for k, v := range form {
+			params[k] = append(params[k], v...)
+		}
+	}
+	return params
+}
+
+// Calls returns the calls to the API method, or to the methods matching a path.Match pattern
+// such as "conversations.*", in the order they were received
+func (sts *Server) Calls(method string) []Call {
+	sts.calls.RLock()
+	defer sts.calls.RUnlock()
+
+	var calls []Call
+	for _, c := range sts.calls.calls {
+		if ok, _ := path.Match(method, c.Method); ok {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// FindCalls returns the calls to the API method that match all the matchers
+func (sts *Server) FindCalls(method string, matchers ...CallMatcher) []Call {
+	var calls []Call
+	for _, c := range sts.Calls(method) {
+		if matchAll(c, matchers) {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// ResetCalls forgets the calls received so far
+func (sts *Server) ResetCalls() {
+	sts.calls.Lock()
+	defer sts.calls.Unlock()
+	sts.calls.calls = nil
+}
+
+// RequireCalled fails the test now if the API method was not called with all the matchers,
+// and returns the first matching call otherwise
+func (sts *Server) RequireCalled(t testing.TB, method string, matchers ...CallMatcher) Call {
+	t.Helper()
+
+	calls := sts.FindCalls(method, matchers...)
+	if len(calls) == 0 {
+		t.Fatalf("expected a matching call to %s, got:\n%s", method, describeCalls(sts.Calls(method)))
+		return Call{}
+	}
+	return calls[0]
+}
+
+// RequireNotCalled fails the test now if the API method was called with all the matchers
+func (sts *Server) RequireNotCalled(t testing.TB, method string, matchers ...CallMatcher) {
+	t.Helper()
+
+	if calls := sts.FindCalls(method, matchers...); len(calls) > 0 {
+		t.Fatalf("expected no matching call to %s, got:\n%s", method, describeCalls(calls))
+	}
+}
+
+func matchAll(c Call, matchers []CallMatcher) bool {
+	for _, match := range matchers {
+		if !match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func describeCalls(calls []Call) string {
+	if len(calls) == 0 {
+		return "\tno calls"
+	}
+
+	var b strings.Builder
+	for _, c := range calls {
+		params := url.Values{}
+		for k, v := range c.Params {
+			if k != "token" {
+				params[k] = v
+			}
+		}
+		fmt.Fprintf(&b, "\t%s %s\n", c.Method, params.Encode())
+	}
+	return b.String()
+}
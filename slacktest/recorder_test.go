@@ -0,0 +1,77 @@
+package slacktest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	slack "github.com/incident-io/slack"
+)
+
+// fatalRecorder records the failures of RequireCalled instead of failing the test
+type fatalRecorder struct {
+	testing.TB
+	failure string
+}
+
+func (f *fatalRecorder) Helper() {}
+
+func (f *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	f.failure = fmt.Sprintf(format, args...)
+}
+
+func TestRecorderCalls(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	client := slack.New("xoxb-recorded", slack.OptionAPIURL(s.GetAPIURL()))
+	_, _, err := client.PostMessage("C1", slack.MsgOptionBlocks(
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "*Incident declared*", false, false), nil, nil),
+	))
+	assert.NoError(t, err)
+	_, err = client.InviteUsersToConversation("C1", "U1", "U2")
+	assert.NoError(t, err)
+	_, err = client.CreateConversation(slack.CreateConversationParams{ChannelName: "inc-1"})
+	assert.NoError(t, err)
+
+	call := s.RequireCalled(t, "chat.postMessage", WithParam("channel", "C1"), WithToken("xoxb-recorded"))
+	assert.Equal(t, "chat.postMessage", call.Method)
+	assert.False(t, call.Time.IsZero())
+
+	blocks, err := call.Blocks()
+	assert.NoError(t, err)
+	if assert.Len(t, blocks.BlockSet, 1) {
+		section := blocks.BlockSet[0].(*slack.SectionBlock)
+		assert.Equal(t, "*Incident declared*", section.Text.Text)
+	}
+
+	s.RequireCalled(t, "chat.postMessage", WithBlocks(func(blocks slack.Blocks) bool {
+		return len(blocks.BlockSet) == 1
+	}))
+	s.RequireCalled(t, "conversations.invite", WithParam("users", "U1,U2"))
+	s.RequireNotCalled(t, "chat.postMessage", WithParam("channel", "C2"))
+
+	assert.Len(t, s.Calls("conversations.*"), 2)
+	assert.Len(t, s.Calls("chat.update"), 0)
+
+	s.ResetCalls()
+	assert.Empty(t, s.Calls("*"))
+}
+
+func TestRecorderRequireCalledFailure(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	client := slack.New("ABCDEFG", slack.OptionAPIURL(s.GetAPIURL()))
+	_, _, err := client.PostMessage("C1", slack.MsgOptionText("hello", false))
+	assert.NoError(t, err)
+
+	ft := &fatalRecorder{TB: t}
+	s.RequireCalled(ft, "chat.postMessage", WithParam("channel", "C2"))
+	assert.Contains(t, ft.failure, "expected a matching call to chat.postMessage")
+	assert.Contains(t, ft.failure, "channel=C1")
+	assert.NotContains(t, ft.failure, "ABCDEFG", "tokens should not be printed")
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"time"
 
 	"github.com/incident-io/slack"
@@ -39,6 +40,9 @@ func NewTestServer(custom ...Binder) *Server {
 		mux:                  http.NewServeMux(),
 		seenInboundMessages:  &messageCollection{},
 		seenOutboundMessages: &messageCollection{},
+		socketMode:           newSocketModeServer(),
+		faults:               newFaultInjector(),
+		calls:                &callRecorder{},
 	}
 
 	for _, c := range custom {
@@ -61,14 +65,18 @@ func NewTestServer(custom ...Binder) *Server {
 	s.Handle("/bots.info", botsInfoHandler)
 	s.Handle("/auth.test", authTestHandler)
 	s.Handle("/reactions.add", reactionAddHandler)
+	s.Handle("/apps.connections.open", s.appsConnectionsOpenHandler)
+	s.Handle("/socketmode", s.socketModeHandler)
 
-	httpserver := httptest.NewUnstartedServer(s.mux)
+	// Faulted requests are recorded too, e.g. to assert on retries
+	httpserver := httptest.NewUnstartedServer(s.calls.recordHandler(s.faults.faultHandler(s.mux)))
 	addr := httpserver.Listener.Addr().String()
 
 	s.ServerAddr = addr
 	s.server = httpserver
 	s.BotName = defaultBotName
 	s.BotID = defaultBotID
+	s.signingSecret = defaultSigningSecret
 	s.SeenFeed = serverChans.seen
 	s.channels = channels
 	s.groups = groups
@@ -161,6 +169,30 @@ func (sts *Server) GetAPIURL() string {
 	return "http://" + sts.ServerAddr + "/"
 }
 
+// HTTPClient returns a client that sends the requests to slack.com to the test server, for the
+// functions that don't use the API URL of a slack.Client, such as slack.GetOAuthV2Response
+func (sts *Server) HTTPClient() *http.Client {
+	return &http.Client{Transport: apiTransport{addr: sts.ServerAddr}}
+}
+
+// apiTransport rewrites the requests to https://slack.com to the test server
+type apiTransport struct {
+	addr string
+}
+
+func (t apiTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host != "slack.com" {
+		return http.DefaultTransport.RoundTrip(r)
+	}
+
+	r = r.Clone(r.Context())
+	r.URL.Scheme = "http"
+	r.URL.Host = t.addr
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, "/api")
+	r.Host = t.addr
+	return http.DefaultTransport.RoundTrip(r)
+}
+
 // GetWSURL returns the websocket url
 func (sts *Server) GetWSURL() string {
 	return "ws://" + sts.ServerAddr + "/ws"
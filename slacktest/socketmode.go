@@ -0,0 +1,331 @@
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	websocket "github.com/gorilla/websocket"
+
+	slack "github.com/incident-io/slack"
+)
+
+const (
+	defaultAppID                  = "A0123456789"
+	defaultSocketModePingInterval = time.Second
+	socketModeHost                = "slacktest"
+)
+
+// SocketModeEnvelope is a Socket Mode request sent to the connected clients.
+type SocketModeEnvelope struct {
+	Type string `json:"type"`
+	// EnvelopeID is generated when empty. Reuse the ID of an envelope to redeliver it.
+	EnvelopeID             string      `json:"envelope_id"`
+	Payload                interface{} `json:"payload"`
+	AcceptsResponsePayload bool        `json:"accepts_response_payload"`
+	RetryAttempt           int         `json:"retry_attempt"`
+	RetryReason            string      `json:"retry_reason"`
+}
+
+// SocketModeAck is an acknowledgement received from a Socket Mode client.
+type SocketModeAck struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+type socketModeServer struct {
+	sync.Mutex
+	conns        map[*socketModeConn]struct{}
+	pingInterval time.Duration
+	acks         []SocketModeAck
+	// acked is closed and replaced whenever an ack is received
+	acked chan struct{}
+	// envelopes are sent to the first connection ready to write them
+	envelopes chan []byte
+	nextID    uint64
+}
+
+type socketModeConn struct {
+	conn *websocket.Conn
+	// messages are sent to this connection only
+	messages chan []byte
+	// pings is signalled when the ping interval changes
+	pings chan struct{}
+	done  chan struct{}
+}
+
+func newSocketModeServer() *socketModeServer {
+	return &socketModeServer{
+		conns:        map[*socketModeConn]struct{}{},
+		pingInterval: defaultSocketModePingInterval,
+		acked:        make(chan struct{}),
+		envelopes:    make(chan []byte, 100),
+	}
+}
+
+// GetSocketModeURL returns the websocket url returned by apps.connections.open
+func (sts *Server) GetSocketModeURL() string {
+	return "ws://" + sts.ServerAddr + "/socketmode"
+}
+
+// handle apps.connections.open
+func (sts *Server) appsConnectionsOpenHandler(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(&struct {
+		Ok  bool   `json:"ok"`
+		URL string `json:"url"`
+	}{
+		Ok:  true,
+		URL: sts.GetSocketModeURL(),
+	})
+}
+
+func (sts *Server) socketModeHandler(w http.ResponseWriter, r *http.Request) {
+	Websocket(func(c *websocket.Conn) {
+		sm := sts.socketMode
+		conn := &socketModeConn{
+			conn:     c,
+			messages: make(chan []byte, 10),
+			pings:    make(chan struct{}, 1),
+			done:     make(chan struct{}),
+		}
+
+		sm.Lock()
+		sm.conns[conn] = struct{}{}
+		numConnections := len(sm.conns)
+		sm.Unlock()
+
+		defer func() {
+			sm.Lock()
+			delete(sm.conns, conn)
+			sm.Unlock()
+			close(conn.done)
+		}()
+
+		hello, _ := json.Marshal(map[string]interface{}{
+			"type":            "hello",
+			"num_connections": numConnections,
+			"debug_info": map[string]interface{}{
+				"host":                        socketModeHost,
+				"approximate_connection_time": 18060,
+			},
+			"connection_info": map[string]string{"app_id": defaultAppID},
+		})
+		conn.messages <- hello
+
+		go sm.writeLoop(conn)
+
+		for {
+			var ack SocketModeAck
+			if err := c.ReadJSON(&ack); err != nil {
+				return
+			}
+			sm.Lock()
+			sm.acks = append(sm.acks, ack)
+			close(sm.acked)
+			sm.acked = make(chan struct{})
+			sm.Unlock()
+		}
+	})(w, r)
+}
+
+// writeLoop is the single writer of the connection.
+func (sm *socketModeServer) writeLoop(conn *socketModeConn) {
+	for {
+		sm.Lock()
+		interval := sm.pingInterval
+		sm.Unlock()
+
+		var (
+			ticker *time.Ticker
+			ticks  <-chan time.Time
+		)
+		if interval > 0 {
+			ticker = time.NewTicker(interval)
+			ticks = ticker.C
+		}
+
+		more := sm.writeUntilPingsChange(conn, ticks)
+		if ticker != nil {
+			ticker.Stop()
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+func (sm *socketModeServer) writeUntilPingsChange(conn *socketModeConn, ticks <-chan time.Time) bool {
+	for {
+		// Connection specific messages first, so that hello comes before any envelope
+		select {
+		case m := <-conn.messages:
+			conn.write(m)
+			continue
+		default:
+		}
+
+		select {
+		case <-conn.done:
+			return false
+		case <-conn.pings:
+			return true
+		case <-ticks:
+			if err := conn.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				log.Printf("error writing ping to socket mode websocket: %s", err.Error())
+			}
+		case m := <-conn.messages:
+			conn.write(m)
+		case m := <-sm.envelopes:
+			if !conn.write(m) {
+				// Leave it to another connection
+				go func() { sm.envelopes <- m }()
+			}
+		}
+	}
+}
+
+func (conn *socketModeConn) write(m []byte) bool {
+	if err := conn.conn.WriteMessage(websocket.TextMessage, m); err != nil {
+		log.Printf("error writing message to socket mode websocket: %s", err.Error())
+		return false
+	}
+	return true
+}
+
+// SendSocketModeEnvelope sends an envelope to one of the connected Socket Mode clients, or to
+// the first one to connect, and returns its envelope ID.
+func (sts *Server) SendSocketModeEnvelope(env SocketModeEnvelope) string {
+	if env.EnvelopeID == "" {
+		env.EnvelopeID = fmt.Sprintf("envelope-%d", atomic.AddUint64(&sts.socketMode.nextID, 1))
+	}
+
+	j, err := json.Marshal(env)
+	if err != nil {
+		log.Printf("Unable to marshal socket mode envelope: %s", err.Error())
+		return ""
+	}
+	sts.socketMode.envelopes <- j
+
+	return env.EnvelopeID
+}
+
+// SendEventsAPIEvent sends the event, e.g. a slackevents.AppMentionEvent, wrapped in an
+// event_callback in an events_api envelope, and returns the envelope ID.
+func (sts *Server) SendEventsAPIEvent(event interface{}) string {
+	return sts.SendSocketModeEnvelope(SocketModeEnvelope{
+		Type:    "events_api",
+		Payload: eventCallback(event),
+	})
+}
+
+// SendSlashCommand sends the command in a slash_commands envelope, and returns the envelope ID.
+func (sts *Server) SendSlashCommand(cmd slack.SlashCommand) string {
+	return sts.SendSocketModeEnvelope(SocketModeEnvelope{
+		Type:                   "slash_commands",
+		Payload:                cmd,
+		AcceptsResponsePayload: true,
+	})
+}
+
+// SendInteraction sends the callback in an interactive envelope, and returns the envelope ID.
+func (sts *Server) SendInteraction(callback slack.InteractionCallback) string {
+	return sts.SendSocketModeEnvelope(SocketModeEnvelope{
+		Type:                   "interactive",
+		Payload:                &callback,
+		AcceptsResponsePayload: true,
+	})
+}
+
+// SendSocketModeDisconnect sends a disconnect request to all the connected Socket Mode clients.
+// Slack sends the reasons "warning" and "refresh_requested" before closing a connection, which can be
+// simulated with CloseSocketModeConnections.
+func (sts *Server) SendSocketModeDisconnect(reason string) {
+	j, _ := json.Marshal(map[string]interface{}{
+		"type":       "disconnect",
+		"reason":     reason,
+		"debug_info": map[string]string{"host": socketModeHost},
+	})
+
+	for _, conn := range sts.socketModeConns() {
+		select {
+		case conn.messages <- j:
+		case <-conn.done:
+		}
+	}
+}
+
+// CloseSocketModeConnections closes the connections of all the connected Socket Mode clients.
+func (sts *Server) CloseSocketModeConnections() {
+	for _, conn := range sts.socketModeConns() {
+		_ = conn.conn.Close()
+	}
+}
+
+// SetSocketModePingInterval changes the interval at which the connected Socket Mode clients are pinged.
+// Zero stops the pings, to simulate a dead connection.
+func (sts *Server) SetSocketModePingInterval(d time.Duration) {
+	sts.socketMode.Lock()
+	sts.socketMode.pingInterval = d
+	sts.socketMode.Unlock()
+
+	for _, conn := range sts.socketModeConns() {
+		select {
+		case conn.pings <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// GetSocketModeConnectionCount returns the number of connected Socket Mode clients.
+func (sts *Server) GetSocketModeConnectionCount() int {
+	sts.socketMode.Lock()
+	defer sts.socketMode.Unlock()
+	return len(sts.socketMode.conns)
+}
+
+// GetSocketModeAcks returns all the acknowledgements received from Socket Mode clients
+func (sts *Server) GetSocketModeAcks() []SocketModeAck {
+	sts.socketMode.Lock()
+	defer sts.socketMode.Unlock()
+	return append([]SocketModeAck(nil), sts.socketMode.acks...)
+}
+
+// WaitForSocketModeAck waits for the acknowledgement of the envelope, and reports false if it
+// was not received within the timeout.
+func (sts *Server) WaitForSocketModeAck(envelopeID string, timeout time.Duration) (SocketModeAck, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		sts.socketMode.Lock()
+		for _, ack := range sts.socketMode.acks {
+			if ack.EnvelopeID == envelopeID {
+				sts.socketMode.Unlock()
+				return ack, true
+			}
+		}
+		acked := sts.socketMode.acked
+		sts.socketMode.Unlock()
+
+		select {
+		case <-acked:
+		case <-timer.C:
+			return SocketModeAck{}, false
+		}
+	}
+}
+
+func (sts *Server) socketModeConns() []*socketModeConn {
+	sts.socketMode.Lock()
+	defer sts.socketMode.Unlock()
+
+	conns := make([]*socketModeConn, 0, len(sts.socketMode.conns))
+	for conn := range sts.socketMode.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}
@@ -0,0 +1,133 @@
+package slacktest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	slack "github.com/incident-io/slack"
+	"github.com/incident-io/slack/socketmode"
+)
+
+func newSocketModeTestClient(s *Server, options ...socketmode.Option) *socketmode.Client {
+	api := slack.New("xoxb-test", slack.OptionAPIURL(s.GetAPIURL()), slack.OptionAppLevelToken("xapp-test"))
+	return socketmode.New(api, options...)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if condition() {
+			return
+		}
+	}
+	t.Fatal("timed out")
+}
+
+func TestSocketModeSlashCommand(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	handler := socketmode.NewSocketmodeHandler(newSocketModeTestClient(s))
+	handler.HandleSlashCommand("/incident", func(evt *socketmode.Event, c *socketmode.Client) {
+		cmd := evt.Data.(slack.SlashCommand)
+		c.Ack(*evt.Request, map[string]string{"text": "declared " + cmd.Text})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go handler.RunEventLoopContext(ctx)
+
+	envelopeID := s.SendSlashCommand(slack.SlashCommand{Command: "/incident", Text: "outage"})
+
+	ack, ok := s.WaitForSocketModeAck(envelopeID, 2*time.Second)
+	assert.True(t, ok, "should have received an ack")
+	assert.JSONEq(t, `{"text":"declared outage"}`, string(ack.Payload))
+	assert.Len(t, s.GetSocketModeAcks(), 1)
+}
+
+func TestSocketModeEventsAPIAndInteraction(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	client := newSocketModeTestClient(s)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunContext(ctx)
+
+	// Sent before the client connects
+	eventsID := s.SendEventsAPIEvent(map[string]string{"type": "app_mention", "channel": "C1", "text": "hi"})
+	interactionID := s.SendInteraction(slack.InteractionCallback{Type: slack.InteractionTypeShortcut, CallbackID: "declare"})
+
+	var got []socketmode.EventType
+	for len(got) < 2 {
+		select {
+		case evt := <-client.Events:
+			switch evt.Type {
+			case socketmode.EventTypeHello:
+				assert.Equal(t, defaultAppID, evt.Request.ConnectionInfo.AppID)
+			case socketmode.EventTypeEventsAPI:
+				assert.Equal(t, eventsID, evt.Request.EnvelopeID)
+				got = append(got, evt.Type)
+			case socketmode.EventTypeInteractive:
+				assert.Equal(t, interactionID, evt.Request.EnvelopeID)
+				assert.Equal(t, "declare", evt.Data.(slack.InteractionCallback).CallbackID)
+				got = append(got, evt.Type)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for envelopes")
+		}
+	}
+	assert.Equal(t, []socketmode.EventType{socketmode.EventTypeEventsAPI, socketmode.EventTypeInteractive}, got)
+}
+
+func TestSocketModeDisconnectWarning(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	client := newSocketModeTestClient(s, socketmode.OptionConnections(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunContext(ctx)
+	go func() {
+		for range client.Events {
+		}
+	}()
+
+	waitFor(t, func() bool { return s.GetSocketModeConnectionCount() == 1 })
+
+	// The pool opens a replacement while the warned connection is still open
+	s.SendSocketModeDisconnect("warning")
+	waitFor(t, func() bool { return s.GetSocketModeConnectionCount() == 2 })
+}
+
+func TestSocketModeMissingPings(t *testing.T) {
+	s := NewTestServer()
+	go s.Start()
+	defer s.Stop()
+
+	s.SetSocketModePingInterval(0)
+
+	client := newSocketModeTestClient(s, socketmode.OptionPingInterval(100*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunContext(ctx)
+
+	connected := 0
+	timeout := time.After(2 * time.Second)
+	for connected < 2 {
+		select {
+		case evt := <-client.Events:
+			if evt.Type == socketmode.EventTypeConnected {
+				connected++
+			}
+		case <-timeout:
+			t.Fatal("expected the client to reconnect after the ping timeout")
+		}
+	}
+}
@@ -61,18 +61,24 @@ type serverGroups struct {
 
 // Server represents a Slack Test server
 type Server struct {
-	registered           map[string]struct{}
-	server               *httptest.Server
-	mux                  *http.ServeMux
-	Logger               *log.Logger
-	BotName              string
-	BotID                string
-	ServerAddr           string
-	SeenFeed             chan (string)
+	registered map[string]struct{}
+	server     *httptest.Server
+	mux        *http.ServeMux
+	Logger     *log.Logger
+	BotName    string
+	BotID      string
+	ServerAddr string
+	SeenFeed   chan (string)
+	// mu guards signingSecret, which signs the requests delivered to the app under test
+	mu                   sync.Mutex
+	signingSecret        string
 	channels             *serverChannels
 	groups               *serverGroups
 	seenInboundMessages  *messageCollection
 	seenOutboundMessages *messageCollection
+	socketMode           *socketModeServer
+	faults               *faultInjector
+	calls                *callRecorder
 }
 
 type fullInfoSlackResponse struct {
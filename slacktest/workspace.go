@@ -0,0 +1,839 @@
+package slacktest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	slack "github.com/incident-io/slack"
+)
+
+// Workspace is a stateful, in-memory Slack workspace. Seed it with users, channels and
+// usergroups, then bind it to a test server with NewTestServer(ws.Bind): the Web API
+// methods it implements read and write its state, so that tests can assert on the
+// resulting workspace.
+type Workspace struct {
+	mu sync.RWMutex
+
+	users      []slack.User
+	channels   []*workspaceChannel
+	usergroups []slack.UserGroup
+
+	// author posts the messages and creates the channels, as the owner of the token
+	author string
+	nextID int
+	lastTs int64
+}
+
+type workspaceChannel struct {
+	slack.Channel
+	// messages include the thread replies, in the order they were posted
+	messages  []slack.Message
+	bookmarks []slack.Bookmark
+}
+
+// NewWorkspace returns an empty workspace, whose messages and channels are created by the test bot.
+func NewWorkspace() *Workspace {
+	return &Workspace{author: defaultBotID}
+}
+
+// AddUser adds a user to the workspace
+func (ws *Workspace) AddUser(user slack.User) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.users = append(ws.users, user)
+}
+
+// AddChannel adds a channel to the workspace, and returns its ID, generated if empty
+func (ws *Workspace) AddChannel(channel slack.Channel) string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if channel.ID == "" {
+		channel.ID = ws.newID("C")
+	}
+	if !channel.IsPrivate {
+		channel.IsChannel = true
+	}
+	ws.channels = append(ws.channels, &workspaceChannel{Channel: channel})
+	return channel.ID
+}
+
+// AddUsergroup adds a usergroup to the workspace
+func (ws *Workspace) AddUsergroup(group slack.UserGroup) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	group.UserCount = len(group.Users)
+	ws.usergroups = append(ws.usergroups, group)
+}
+
+// Channel returns the channel with the ID
+func (ws *Workspace) Channel(id string) (slack.Channel, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	c := ws.channel(id)
+	if c == nil {
+		return slack.Channel{}, false
+	}
+	return c.info(), true
+}
+
+// ChannelByName returns the channel with the name
+func (ws *Workspace) ChannelByName(name string) (slack.Channel, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	for _, c := range ws.channels {
+		if c.Name == name {
+			return c.info(), true
+		}
+	}
+	return slack.Channel{}, false
+}
+
+// Messages returns all the messages of the channel, thread replies included, in the order they were posted
+func (ws *Workspace) Messages(channelID string) []slack.Message {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	c := ws.channel(channelID)
+	if c == nil {
+		return nil
+	}
+	return append([]slack.Message(nil), c.messages...)
+}
+
+// Message returns the message of the channel with the timestamp
+func (ws *Workspace) Message(channelID, ts string) (slack.Message, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	c := ws.channel(channelID)
+	if c == nil {
+		return slack.Message{}, false
+	}
+	if i := c.message(ts); i >= 0 {
+		return c.messages[i], true
+	}
+	return slack.Message{}, false
+}
+
+// Bookmarks returns the bookmarks of the channel
+func (ws *Workspace) Bookmarks(channelID string) []slack.Bookmark {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	c := ws.channel(channelID)
+	if c == nil {
+		return nil
+	}
+	return append([]slack.Bookmark(nil), c.bookmarks...)
+}
+
+// Bind registers the handlers of the Web API methods implemented by the workspace.
+// Use it as a Binder of NewTestServer.
+func (ws *Workspace) Bind(c Customize) {
+	c.Handle("/chat.postMessage", ws.handler(ws.postMessage))
+	c.Handle("/chat.update", ws.handler(ws.updateMessage))
+	c.Handle("/chat.delete", ws.handler(ws.deleteMessage))
+	c.Handle("/conversations.history", ws.readHandler(ws.history))
+	c.Handle("/conversations.replies", ws.readHandler(ws.replies))
+	c.Handle("/conversations.info", ws.readHandler(ws.conversationInfo))
+	c.Handle("/conversations.members", ws.readHandler(ws.conversationMembers))
+	c.Handle("/conversations.create", ws.handler(ws.createConversation))
+	c.Handle("/conversations.invite", ws.handler(ws.inviteToConversation))
+	c.Handle("/conversations.kick", ws.handler(ws.kickFromConversation))
+	c.Handle("/conversations.archive", ws.handler(ws.archiveConversation))
+	c.Handle("/reactions.add", ws.handler(ws.addReaction))
+	c.Handle("/pins.add", ws.handler(ws.addPin))
+	c.Handle("/bookmarks.add", ws.handler(ws.addBookmark))
+	c.Handle("/bookmarks.edit", ws.handler(ws.editBookmark))
+	c.Handle("/bookmarks.remove", ws.handler(ws.removeBookmark))
+	c.Handle("/bookmarks.list", ws.readHandler(ws.listBookmarks))
+	c.Handle("/users.info", ws.readHandler(ws.userInfo))
+	c.Handle("/users.list", ws.readHandler(ws.listUsers))
+	c.Handle("/usergroups.list", ws.readHandler(ws.listUsergroups))
+}
+
+// workspaceError is a Slack API error code, returned with "ok": false
+type workspaceError string
+
+// workspaceMethod implements a Web API method. It returns the fields of the response besides "ok".
+type workspaceMethod func(form url.Values) (map[string]interface{}, workspaceError)
+
+// handler serves a method that writes the workspace
+func (ws *Workspace) handler(method workspaceMethod) http.HandlerFunc {
+	return ws.serve(method, func() func() {
+		ws.mu.Lock()
+		return ws.mu.Unlock
+	})
+}
+
+// readHandler serves a method that only reads the workspace
+func (ws *Workspace) readHandler(method workspaceMethod) http.HandlerFunc {
+	return ws.serve(method, func() func() {
+		ws.mu.RLock()
+		return ws.mu.RUnlock
+	})
+}
+
+func (ws *Workspace) serve(method workspaceMethod, lock func() func()) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, fmt.Sprintf("Unable to decode form: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		unlock := lock()
+		response, errCode := method(r.Form)
+		unlock()
+
+		if errCode != "" {
+			response = map[string]interface{}{"error": string(errCode)}
+		}
+		if response == nil {
+			response = map[string]interface{}{}
+		}
+		response["ok"] = errCode == ""
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (ws *Workspace) newID(prefix string) string {
+	ws.nextID++
+	return fmt.Sprintf("%s%08d", prefix, ws.nextID)
+}
+
+// newTs returns a unique, increasing message timestamp
+func (ws *Workspace) newTs() string {
+	now := time.Now().UnixNano() / int64(time.Microsecond)
+	if now <= ws.lastTs {
+		now = ws.lastTs + 1
+	}
+	ws.lastTs = now
+	return fmt.Sprintf("%d.%06d", now/1e6, now%1e6)
+}
+
+func (ws *Workspace) channel(id string) *workspaceChannel {
+	for _, c := range ws.channels {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (ws *Workspace) user(id string) (slack.User, bool) {
+	for _, u := range ws.users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return slack.User{}, false
+}
+
+// info returns the channel as returned by the API
+func (c *workspaceChannel) info() slack.Channel {
+	ch := c.Channel
+	ch.Members = append([]string(nil), c.Members...)
+	ch.NumMembers = len(c.Members)
+	return ch
+}
+
+func (c *workspaceChannel) message(ts string) int {
+	for i, m := range c.messages {
+		if m.Timestamp == ts {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateThread sets the replies of the thread parent at index p, and removes its tombstone
+// once it has no replies left
+func (c *workspaceChannel) updateThread(p int) {
+	parent := &c.messages[p]
+	parent.ReplyCount = 0
+	parent.ReplyUsers = nil
+	parent.LatestReply = ""
+	for _, m := range c.messages {
+		if m.ThreadTimestamp != parent.Timestamp || m.Timestamp == parent.Timestamp {
+			continue
+		}
+		parent.ReplyCount++
+		parent.LatestReply = m.Timestamp
+		if !contains(parent.ReplyUsers, m.User) {
+			parent.ReplyUsers = append(parent.ReplyUsers, m.User)
+		}
+	}
+
+	if parent.ReplyCount == 0 && parent.SubType == "tombstone" {
+		c.messages = append(c.messages[:p], c.messages[p+1:]...)
+	}
+}
+
+// tombstone returns the message left in place of a deleted thread parent
+func tombstone(parent slack.Message) slack.Message {
+	m := slack.Message{}
+	m.Type = slack.TYPE_MESSAGE
+	m.SubType = "tombstone"
+	m.Hidden = true
+	m.Text = "This message was deleted."
+	m.Channel = parent.Channel
+	m.User = "USLACKBOT"
+	m.Timestamp = parent.Timestamp
+	m.ThreadTimestamp = parent.ThreadTimestamp
+	m.ReplyCount = parent.ReplyCount
+	m.ReplyUsers = parent.ReplyUsers
+	m.LatestReply = parent.LatestReply
+	return m
+}
+
+func (c *workspaceChannel) isMember(user string) bool {
+	for _, m := range c.Members {
+		if m == user {
+			return true
+		}
+	}
+	return false
+}
+
+// inChannel returns the channel, or the error to respond with if it doesn't exist
+func (ws *Workspace) inChannel(id string) (*workspaceChannel, workspaceError) {
+	c := ws.channel(id)
+	if c == nil {
+		return nil, "channel_not_found"
+	}
+	return c, ""
+}
+
+// inWritableChannel returns the channel, or the error to respond with if it can't be written to
+func (ws *Workspace) inWritableChannel(id string) (*workspaceChannel, workspaceError) {
+	c, errCode := ws.inChannel(id)
+	if errCode == "" && c.IsArchived {
+		return nil, "is_archived"
+	}
+	return c, errCode
+}
+
+// applyContent sets the text, blocks and attachments of the form on the message
+func applyContent(m *slack.Message, form url.Values) workspaceError {
+	m.Text = form.Get("text")
+	if blocks := form.Get("blocks"); blocks != "" {
+		if err := json.Unmarshal([]byte(blocks), &m.Blocks); err != nil {
+			return "invalid_blocks"
+		}
+	}
+	if attachments := form.Get("attachments"); attachments != "" {
+		if err := json.Unmarshal([]byte(attachments), &m.Attachments); err != nil {
+			return "invalid_attachments"
+		}
+	}
+	if m.Text == "" && len(m.Blocks.BlockSet) == 0 && len(m.Attachments) == 0 {
+		return "no_text"
+	}
+	return ""
+}
+
+// handle chat.postMessage
+func (ws *Workspace) postMessage(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	m := slack.Message{}
+	m.Type = slack.TYPE_MESSAGE
+	m.Channel = c.ID
+	m.User = ws.author
+	if errCode := applyContent(&m, form); errCode != "" {
+		return nil, errCode
+	}
+	m.Timestamp = ws.newTs()
+
+	if threadTs := form.Get("thread_ts"); threadTs != "" {
+		i := c.message(threadTs)
+		if i < 0 {
+			return nil, "thread_not_found"
+		}
+
+		parent := &c.messages[i]
+		parent.ThreadTimestamp = parent.Timestamp
+		parent.ReplyCount++
+		parent.LatestReply = m.Timestamp
+		if !contains(parent.ReplyUsers, m.User) {
+			parent.ReplyUsers = append(parent.ReplyUsers, m.User)
+		}
+
+		m.ThreadTimestamp = parent.Timestamp
+		if form.Get("reply_broadcast") == "true" {
+			m.SubType = "thread_broadcast"
+		}
+	}
+
+	c.messages = append(c.messages, m)
+
+	return map[string]interface{}{"channel": c.ID, "ts": m.Timestamp, "message": m}, ""
+}
+
+// handle chat.update
+func (ws *Workspace) updateMessage(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	i := c.message(form.Get("ts"))
+	if i < 0 {
+		return nil, "message_not_found"
+	}
+
+	m := c.messages[i]
+	if errCode := applyContent(&m, form); errCode != "" {
+		return nil, errCode
+	}
+	m.Edited = &slack.Edited{User: ws.author, Timestamp: ws.newTs()}
+	c.messages[i] = m
+
+	return map[string]interface{}{"channel": c.ID, "ts": m.Timestamp, "text": m.Text}, ""
+}
+
+// handle chat.delete
+func (ws *Workspace) deleteMessage(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	ts := form.Get("ts")
+	i := c.message(ts)
+	if i < 0 {
+		return nil, "message_not_found"
+	}
+
+	deleted := c.messages[i]
+	if deleted.ReplyCount > 0 {
+		// Like Slack, keep a tombstone of a thread parent for its replies
+		c.messages[i] = tombstone(deleted)
+		return map[string]interface{}{"channel": c.ID, "ts": ts}, ""
+	}
+	c.messages = append(c.messages[:i], c.messages[i+1:]...)
+
+	// Keep the parent of a deleted reply consistent
+	if deleted.ThreadTimestamp != "" && deleted.ThreadTimestamp != ts {
+		if p := c.message(deleted.ThreadTimestamp); p >= 0 {
+			c.updateThread(p)
+		}
+	}
+
+	return map[string]interface{}{"channel": c.ID, "ts": ts}, ""
+}
+
+// handle conversations.history
+func (ws *Workspace) history(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	inclusive := form.Get("inclusive") == "1" || form.Get("inclusive") == "true"
+	oldest, latest := form.Get("oldest"), form.Get("latest")
+
+	// Newest first, without the thread replies that were not broadcast
+	var messages []slack.Message
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		m := c.messages[i]
+		if m.ThreadTimestamp != "" && m.ThreadTimestamp != m.Timestamp && m.SubType != "thread_broadcast" {
+			continue
+		}
+		if !inRange(m.Timestamp, oldest, latest, inclusive) {
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	return paginate(messages, form)
+}
+
+// handle conversations.replies
+func (ws *Workspace) replies(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	ts := form.Get("ts")
+	if c.message(ts) < 0 {
+		return nil, "thread_not_found"
+	}
+
+	inclusive := form.Get("inclusive") == "1" || form.Get("inclusive") == "true"
+	oldest, latest := form.Get("oldest"), form.Get("latest")
+
+	// The parent first, then the replies oldest first
+	var messages []slack.Message
+	for _, m := range c.messages {
+		if m.Timestamp != ts && m.ThreadTimestamp != ts {
+			continue
+		}
+		if m.Timestamp != ts && !inRange(m.Timestamp, oldest, latest, inclusive) {
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	return paginate(messages, form)
+}
+
+// handle conversations.info
+func (ws *Workspace) conversationInfo(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	return map[string]interface{}{"channel": c.info()}, ""
+}
+
+// handle conversations.members
+func (ws *Workspace) conversationMembers(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	return map[string]interface{}{"members": c.info().Members}, ""
+}
+
+var channelNameRegexp = regexp.MustCompile(`^[a-z0-9_-]{1,80}$`)
+
+// handle conversations.create
+func (ws *Workspace) createConversation(form url.Values) (map[string]interface{}, workspaceError) {
+	name := form.Get("name")
+	if !channelNameRegexp.MatchString(name) {
+		return nil, "invalid_name_specified"
+	}
+	for _, c := range ws.channels {
+		if c.Name == name {
+			return nil, "name_taken"
+		}
+	}
+
+	channel := slack.Channel{}
+	channel.Name = name
+	channel.NameNormalized = name
+	channel.IsPrivate = form.Get("is_private") == "true"
+	channel.IsChannel = !channel.IsPrivate
+	channel.IsMember = true
+	channel.Created = slack.JSONTime(time.Now().Unix())
+	channel.Creator = ws.author
+	channel.Members = []string{ws.author}
+	channel.ID = ws.newID("C")
+
+	c := &workspaceChannel{Channel: channel}
+	ws.channels = append(ws.channels, c)
+
+	return map[string]interface{}{"channel": c.info()}, ""
+}
+
+// handle conversations.invite
+func (ws *Workspace) inviteToConversation(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	users := strings.Split(form.Get("users"), ",")
+	for _, user := range users {
+		if _, ok := ws.user(user); !ok {
+			return nil, "user_not_found"
+		}
+	}
+	for _, user := range users {
+		if !c.isMember(user) {
+			c.Members = append(c.Members, user)
+		}
+	}
+
+	return map[string]interface{}{"channel": c.info()}, ""
+}
+
+// handle conversations.kick
+func (ws *Workspace) kickFromConversation(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	user := form.Get("user")
+	for i, member := range c.Members {
+		if member == user {
+			c.Members = append(c.Members[:i], c.Members[i+1:]...)
+			return nil, ""
+		}
+	}
+	return nil, "not_in_channel"
+}
+
+// handle conversations.archive
+func (ws *Workspace) archiveConversation(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	if c.IsArchived {
+		return nil, "already_archived"
+	}
+	c.IsArchived = true
+	return nil, ""
+}
+
+// handle reactions.add
+func (ws *Workspace) addReaction(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	i := c.message(form.Get("timestamp"))
+	if i < 0 {
+		return nil, "message_not_found"
+	}
+
+	m := &c.messages[i]
+	name := form.Get("name")
+	for r := range m.Reactions {
+		reaction := &m.Reactions[r]
+		if reaction.Name != name {
+			continue
+		}
+		if contains(reaction.Users, ws.author) {
+			return nil, "already_reacted"
+		}
+		reaction.Users = append(reaction.Users, ws.author)
+		reaction.Count++
+		return nil, ""
+	}
+	m.Reactions = append(m.Reactions, slack.ItemReaction{Name: name, Count: 1, Users: []string{ws.author}})
+
+	return nil, ""
+}
+
+// handle pins.add
+func (ws *Workspace) addPin(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	i := c.message(form.Get("timestamp"))
+	if i < 0 {
+		return nil, "message_not_found"
+	}
+
+	m := &c.messages[i]
+	if contains(m.PinnedTo, c.ID) {
+		return nil, "already_pinned"
+	}
+	m.PinnedTo = append(m.PinnedTo, c.ID)
+
+	return nil, ""
+}
+
+// handle bookmarks.add
+func (ws *Workspace) addBookmark(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel_id"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	if form.Get("title") == "" || form.Get("type") == "" {
+		return nil, "invalid_arguments"
+	}
+
+	now := slack.JSONTime(time.Now().Unix())
+	bookmark := slack.Bookmark{
+		ID:                  ws.newID("Bk"),
+		ChannelID:           c.ID,
+		Title:               form.Get("title"),
+		Type:                form.Get("type"),
+		Link:                form.Get("link"),
+		Emoji:               form.Get("emoji"),
+		EntityID:            form.Get("entity_id"),
+		Created:             now,
+		Updated:             now,
+		LastUpdatedByUserID: ws.author,
+		LastUpdatedByTeamID: defaultTeamID,
+	}
+	c.bookmarks = append(c.bookmarks, bookmark)
+
+	return map[string]interface{}{"bookmark": bookmark}, ""
+}
+
+// handle bookmarks.edit
+func (ws *Workspace) editBookmark(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel_id"))
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	for i := range c.bookmarks {
+		bookmark := &c.bookmarks[i]
+		if bookmark.ID != form.Get("bookmark_id") {
+			continue
+		}
+		if v, ok := form["title"]; ok {
+			bookmark.Title = v[0]
+		}
+		if v, ok := form["emoji"]; ok {
+			bookmark.Emoji = v[0]
+		}
+		if link := form.Get("link"); link != "" {
+			bookmark.Link = link
+		}
+		bookmark.Updated = slack.JSONTime(time.Now().Unix())
+		bookmark.LastUpdatedByUserID = ws.author
+
+		return map[string]interface{}{"bookmark": *bookmark}, ""
+	}
+	return nil, "bookmark_not_found"
+}
+
+// handle bookmarks.remove
+func (ws *Workspace) removeBookmark(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inWritableChannel(form.Get("channel_id"))
+	if errCode != "" {
+		return nil, errCode
+	}
+
+	for i, bookmark := range c.bookmarks {
+		if bookmark.ID == form.Get("bookmark_id") {
+			c.bookmarks = append(c.bookmarks[:i], c.bookmarks[i+1:]...)
+			return nil, ""
+		}
+	}
+	return nil, "bookmark_not_found"
+}
+
+// handle bookmarks.list
+func (ws *Workspace) listBookmarks(form url.Values) (map[string]interface{}, workspaceError) {
+	c, errCode := ws.inChannel(form.Get("channel_id"))
+	if errCode != "" {
+		return nil, errCode
+	}
+	return map[string]interface{}{"bookmarks": append([]slack.Bookmark{}, c.bookmarks...)}, ""
+}
+
+// handle users.info
+func (ws *Workspace) userInfo(form url.Values) (map[string]interface{}, workspaceError) {
+	user, ok := ws.user(form.Get("user"))
+	if !ok {
+		return nil, "user_not_found"
+	}
+	return map[string]interface{}{"user": user}, ""
+}
+
+// handle users.list
+func (ws *Workspace) listUsers(form url.Values) (map[string]interface{}, workspaceError) {
+	return map[string]interface{}{"members": append([]slack.User{}, ws.users...)}, ""
+}
+
+// handle usergroups.list
+func (ws *Workspace) listUsergroups(form url.Values) (map[string]interface{}, workspaceError) {
+	includeUsers := form.Get("include_users") == "true"
+
+	groups := make([]slack.UserGroup, 0, len(ws.usergroups))
+	for _, g := range ws.usergroups {
+		if !includeUsers {
+			g.Users = nil
+		}
+		groups = append(groups, g)
+	}
+	return map[string]interface{}{"usergroups": groups}, ""
+}
+
+// inRange reports whether the timestamp is between oldest and latest, either being optional
+func inRange(ts, oldest, latest string, inclusive bool) bool {
+	t := tsMicros(ts)
+	if oldest != "" {
+		o := tsMicros(oldest)
+		if t < o || (t == o && !inclusive) {
+			return false
+		}
+	}
+	if latest != "" {
+		l := tsMicros(latest)
+		if t > l || (t == l && !inclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// tsMicros converts a message timestamp to microseconds, without the rounding errors of a float
+func tsMicros(ts string) int64 {
+	secs, micros, _ := strings.Cut(ts, ".")
+	s, _ := strconv.ParseInt(secs, 10, 64)
+	micros = (micros + "000000")[:6]
+	m, _ := strconv.ParseInt(micros, 10, 64)
+	return s*1e6 + m
+}
+
+// paginate responds with the page of the messages starting at the cursor parameter, of the
+// size of the limit parameter, 100 by default
+func paginate(messages []slack.Message, form url.Values) (map[string]interface{}, workspaceError) {
+	offset := 0
+	if cursor := form.Get("cursor"); cursor != "" {
+		var ok bool
+		if offset, ok = decodeCursor(cursor); !ok || offset > len(messages) {
+			return nil, "invalid_cursor"
+		}
+	}
+	n, err := strconv.Atoi(form.Get("limit"))
+	if err != nil || n <= 0 {
+		n = 100
+	}
+
+	page := append([]slack.Message{}, messages[offset:]...)
+	hasMore := len(page) > n
+	nextCursor := ""
+	if hasMore {
+		page = page[:n]
+		nextCursor = encodeCursor(offset + n)
+	}
+	return map[string]interface{}{
+		"messages":          page,
+		"has_more":          hasMore,
+		"response_metadata": map[string]interface{}{"next_cursor": nextCursor},
+	}, ""
+}
+
+// encodeCursor returns the opaque cursor of the page starting at the offset
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("offset:%d", offset)))
+}
+
+func decodeCursor(cursor string) (int, bool) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	value, ok := strings.CutPrefix(string(b), "offset:")
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.Atoi(value)
+	return offset, err == nil && offset >= 0
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
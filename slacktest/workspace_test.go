@@ -0,0 +1,218 @@
+package slacktest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	slack "github.com/incident-io/slack"
+)
+
+func newWorkspaceTestServer(t *testing.T) (*Workspace, *slack.Client, string) {
+	ws := NewWorkspace()
+	ws.AddUser(slack.User{ID: "U1", Name: "alice"})
+	ws.AddUser(slack.User{ID: "U2", Name: "bob"})
+	ws.AddUsergroup(slack.UserGroup{ID: "S1", Handle: "oncall", Users: []string{"U1"}})
+
+	general := slack.Channel{}
+	general.Name = "general"
+	general.Members = []string{defaultBotID, "U1"}
+	channelID := ws.AddChannel(general)
+
+	s := NewTestServer(ws.Bind)
+	go s.Start()
+	t.Cleanup(s.Stop)
+
+	return ws, slack.New("ABCDEFG", slack.OptionAPIURL(s.GetAPIURL())), channelID
+}
+
+func TestWorkspaceMessages(t *testing.T) {
+	ws, client, channelID := newWorkspaceTestServer(t)
+
+	_, parentTs, err := client.PostMessage(channelID, slack.MsgOptionText("incident declared", false))
+	require.NoError(t, err)
+	_, replyTs, err := client.PostMessage(channelID, slack.MsgOptionText("investigating", false), slack.MsgOptionTS(parentTs))
+	require.NoError(t, err)
+	_, otherTs, err := client.PostMessage(channelID, slack.MsgOptionText("unrelated", false))
+	require.NoError(t, err)
+
+	_, _, _, err = client.UpdateMessage(channelID, parentTs, slack.MsgOptionText("incident resolved", false))
+	require.NoError(t, err)
+	_, _, err = client.DeleteMessage(channelID, otherTs)
+	require.NoError(t, err)
+	require.NoError(t, client.AddReaction("eyes", slack.NewRefToMessage(channelID, replyTs)))
+	require.NoError(t, client.AddPin(channelID, slack.NewRefToMessage(channelID, parentTs)))
+
+	parent, ok := ws.Message(channelID, parentTs)
+	require.True(t, ok)
+	assert.Equal(t, "incident resolved", parent.Text)
+	assert.NotNil(t, parent.Edited)
+	assert.Equal(t, 1, parent.ReplyCount)
+	assert.Equal(t, []string{channelID}, parent.PinnedTo)
+
+	reply, _ := ws.Message(channelID, replyTs)
+	assert.Equal(t, []slack.ItemReaction{{Name: "eyes", Count: 1, Users: []string{defaultBotID}}}, reply.Reactions)
+
+	_, ok = ws.Message(channelID, otherTs)
+	assert.False(t, ok, "deleted message should be gone")
+
+	history, err := client.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channelID})
+	require.NoError(t, err)
+	require.Len(t, history.Messages, 1, "replies are not part of the history")
+	assert.Equal(t, parentTs, history.Messages[0].Timestamp)
+
+	replies, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: parentTs})
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, "investigating", replies[1].Text)
+
+	err = client.AddReaction("eyes", slack.NewRefToMessage(channelID, replyTs))
+	assert.EqualError(t, err, "already_reacted")
+	_, _, err = client.PostMessage("C404", slack.MsgOptionText("hello", false))
+	assert.EqualError(t, err, "channel_not_found")
+}
+
+func TestWorkspacePagination(t *testing.T) {
+	_, client, channelID := newWorkspaceTestServer(t)
+
+	_, parentTs, err := client.PostMessage(channelID, slack.MsgOptionText("0", false))
+	require.NoError(t, err)
+	for i := 1; i < 5; i++ {
+		_, _, err = client.PostMessage(channelID, slack.MsgOptionText(strconv.Itoa(i), false), slack.MsgOptionTS(parentTs))
+		require.NoError(t, err)
+		_, _, err = client.PostMessage(channelID, slack.MsgOptionText(strconv.Itoa(i), false))
+		require.NoError(t, err)
+	}
+
+	var texts []string
+	params := &slack.GetConversationHistoryParameters{ChannelID: channelID, Limit: 2}
+	for {
+		history, err := client.GetConversationHistory(params)
+		require.NoError(t, err)
+		for _, m := range history.Messages {
+			texts = append(texts, m.Text)
+		}
+		if !history.HasMore {
+			assert.Empty(t, history.ResponseMetaData.NextCursor)
+			break
+		}
+		require.NotEmpty(t, history.ResponseMetaData.NextCursor)
+		params.Cursor = history.ResponseMetaData.NextCursor
+	}
+	assert.Equal(t, []string{"4", "3", "2", "1", "0"}, texts)
+
+	texts = nil
+	repliesParams := &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: parentTs, Limit: 3}
+	for {
+		replies, hasMore, nextCursor, err := client.GetConversationReplies(repliesParams)
+		require.NoError(t, err)
+		for _, m := range replies {
+			texts = append(texts, m.Text)
+		}
+		if !hasMore {
+			break
+		}
+		repliesParams.Cursor = nextCursor
+	}
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, texts)
+
+	_, err = client.GetConversationHistory(&slack.GetConversationHistoryParameters{ChannelID: channelID, Cursor: "bogus"})
+	assert.EqualError(t, err, "invalid_cursor")
+}
+
+func TestWorkspaceDeleteThread(t *testing.T) {
+	ws, client, channelID := newWorkspaceTestServer(t)
+
+	_, parentTs, err := client.PostMessage(channelID, slack.MsgOptionText("incident declared", false))
+	require.NoError(t, err)
+	_, firstTs, err := client.PostMessage(channelID, slack.MsgOptionText("investigating", false), slack.MsgOptionTS(parentTs))
+	require.NoError(t, err)
+	_, lastTs, err := client.PostMessage(channelID, slack.MsgOptionText("fixed", false), slack.MsgOptionTS(parentTs))
+	require.NoError(t, err)
+
+	_, _, err = client.DeleteMessage(channelID, lastTs)
+	require.NoError(t, err)
+	parent, _ := ws.Message(channelID, parentTs)
+	assert.Equal(t, 1, parent.ReplyCount)
+	assert.Equal(t, firstTs, parent.LatestReply)
+	assert.Equal(t, []string{defaultBotID}, parent.ReplyUsers)
+
+	// The parent of a thread leaves a tombstone, until its last reply is deleted
+	_, _, err = client.DeleteMessage(channelID, parentTs)
+	require.NoError(t, err)
+	parent, ok := ws.Message(channelID, parentTs)
+	require.True(t, ok)
+	assert.Equal(t, "tombstone", parent.SubType)
+	replies, _, _, err := client.GetConversationReplies(&slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: parentTs})
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, "investigating", replies[1].Text)
+
+	_, _, err = client.DeleteMessage(channelID, firstTs)
+	require.NoError(t, err)
+	assert.Empty(t, ws.Messages(channelID))
+}
+
+func TestWorkspaceConversations(t *testing.T) {
+	ws, client, _ := newWorkspaceTestServer(t)
+
+	channel, err := client.CreateConversation(slack.CreateConversationParams{ChannelName: "inc-42"})
+	require.NoError(t, err)
+	assert.Equal(t, "inc-42", channel.Name)
+
+	_, err = client.CreateConversation(slack.CreateConversationParams{ChannelName: "inc-42"})
+	assert.EqualError(t, err, "name_taken")
+
+	_, err = client.InviteUsersToConversation(channel.ID, "U1", "U2")
+	require.NoError(t, err)
+	require.NoError(t, client.KickUserFromConversation(channel.ID, "U2"))
+	assert.EqualError(t, client.KickUserFromConversation(channel.ID, "U2"), "not_in_channel")
+
+	stored, ok := ws.ChannelByName("inc-42")
+	require.True(t, ok)
+	assert.Equal(t, []string{defaultBotID, "U1"}, stored.Members)
+
+	require.NoError(t, client.ArchiveConversation(channel.ID))
+	stored, _ = ws.Channel(channel.ID)
+	assert.True(t, stored.IsArchived)
+
+	_, _, err = client.PostMessage(channel.ID, slack.MsgOptionText("hello", false))
+	assert.EqualError(t, err, "is_archived")
+}
+
+func TestWorkspaceBookmarks(t *testing.T) {
+	ws, client, channelID := newWorkspaceTestServer(t)
+
+	bookmark, err := client.AddBookmark(channelID, slack.AddBookmarkParameters{Title: "Runbook", Type: "link", Link: "https://example.com"})
+	require.NoError(t, err)
+
+	title := "Updated runbook"
+	_, err = client.EditBookmark(channelID, bookmark.ID, slack.EditBookmarkParameters{Title: &title})
+	require.NoError(t, err)
+
+	bookmarks, err := client.ListBookmarks(channelID)
+	require.NoError(t, err)
+	require.Len(t, bookmarks, 1)
+	assert.Equal(t, "Updated runbook", bookmarks[0].Title)
+
+	require.NoError(t, client.RemoveBookmark(channelID, bookmark.ID))
+	assert.Empty(t, ws.Bookmarks(channelID))
+}
+
+func TestWorkspaceUsers(t *testing.T) {
+	_, client, _ := newWorkspaceTestServer(t)
+
+	user, err := client.GetUserInfo("U2")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.Name)
+
+	_, err = client.GetUserInfo("U404")
+	assert.EqualError(t, err, "user_not_found")
+
+	groups, err := client.GetUserGroups(slack.GetUserGroupsOptionIncludeUsers(true))
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{"U1"}, groups[0].Users)
+}
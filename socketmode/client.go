@@ -2,6 +2,7 @@ package socketmode
 
 import (
 	"encoding/json"
+	"sync/atomic"
 	"time"
 
 	"github.com/incident-io/slack"
@@ -50,10 +51,20 @@ type Client struct {
 	// until Client considers the WebSocket connection is dead and needs to be reopened.
 	maxPingInterval time.Duration
 
+	// connections is the number of WebSocket connections held open in parallel by RunContext.
+	// Zero means a single connection that is reopened on disconnect, without a connection pool.
+	connections int
+
 	// Connection life-cycle
 	Events              chan Event
 	socketModeResponses chan *Response
 
+	// shutdown tracks the envelopes in flight for Shutdown
+	shutdown shutdownState
+
+	// pool is the connection pool while RunContext runs one
+	pool atomic.Pointer[connPool]
+
 	// dialer is a gorilla/websocket Dialer. If nil, use the default
 	// Dialer.
 	dialer *websocket.Dialer
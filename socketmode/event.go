@@ -22,6 +22,17 @@ type ErrorWriteFailed struct {
 	Response *Response
 }
 
+// HandlerPanic is the data of an EventTypeHandlerPanic event, emitted when a SocketmodeHandlerFunc
+// panics and SocketmodeHandler recovers it.
+type HandlerPanic struct {
+	// Event is the event the handler was processing
+	Event *Event
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace of the panicking handler
+	Stack []byte
+}
+
 type errorRequestedDisconnect struct {
 }
 
@@ -43,17 +43,57 @@ func (smc *Client) Run() error {
 // This function exists with an error only when a reconnection is failued due to some reason.
 // If you want to retry even on reconnection failure, you'd need to write your own wrapper for this function
 // to do so.
+//
+// RunContext returns nil once the client is gracefully stopped with Client.Shutdown.
 func (smc *Client) RunContext(ctx context.Context) error {
+	defer smc.shutdown.startRun()()
+
+	var err error
+	if smc.connections > 0 {
+		err = smc.runPool(ctx)
+	} else {
+		err = smc.runConnection(ctx)
+	}
+
+	if errors.Is(err, errShutdown) {
+		return nil
+	}
+	return err
+}
+
+func (smc *Client) runConnection(ctx context.Context) error {
+	stop := smc.shutdown.stopped()
+
 	for connectionCount := 0; ; connectionCount++ {
-		if err := smc.run(ctx, connectionCount); err != nil {
+		if err := smc.run(ctx, connectionCount, nil); err != nil {
 			return err
 		}
 
+		select {
+		case <-stop:
+			return errShutdown
+		default:
+		}
+
 		// Continue and run the loop again to reconnect
 	}
 }
 
-func (smc *Client) run(ctx context.Context, connectionCount int) error {
+// connHooks customizes how run serves a connection that is part of a connection pool.
+type connHooks struct {
+	// responses are the responses to send over the connection
+	responses <-chan *Response
+	// received records the envelope received over the connection, and reports whether
+	// it is a duplicate that must not be emitted again
+	received func(envelopeID string) (duplicate bool)
+	// refresh is called when Slack announces that the connection is about to be closed,
+	// so that a replacement is opened before it is
+	refresh func()
+}
+
+// run connects and serves a single WebSocket connection until it fails or is closed.
+// hooks is nil unless the connection is part of a connection pool.
+func (smc *Client) run(ctx context.Context, connectionCount int, hooks *connHooks) error {
 	messages := make(chan json.RawMessage, 1)
 
 	pingChan := make(chan time.Time, 1)
@@ -108,7 +148,12 @@ func (smc *Client) run(ctx context.Context, connectionCount int) error {
 		defer cancel()
 
 		// The response sender sends Socket Mode responses over the WebSocket conn
-		if err := smc.runResponseSender(ctx, conn); err != nil {
+		responses := []<-chan *Response{smc.socketModeResponses}
+		if hooks != nil {
+			// Responses that are not routed yet are flushed by any connection on shutdown
+			responses = []<-chan *Response{hooks.responses, smc.socketModeResponses}
+		}
+		if err := smc.runResponseSender(ctx, conn, responses...); err != nil {
 			sendErr(err)
 		}
 	}()
@@ -119,7 +164,7 @@ func (smc *Client) run(ctx context.Context, connectionCount int) error {
 		defer cancel()
 
 		// The handler reads Socket Mode requests, and enqueues responses for sending by the response sender
-		if err := smc.runRequestHandler(ctx, messages); err != nil {
+		if err := smc.runRequestHandler(ctx, messages, hooks); err != nil {
 			sendErr(err)
 		}
 	}()
@@ -185,7 +230,7 @@ func (smc *Client) run(ctx context.Context, connectionCount int) error {
 		// Or nothing if they all exited nil
 	}
 
-	if errors.Is(err, context.Canceled) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, errShutdown) {
 		return err
 	}
 
@@ -278,6 +323,9 @@ func (smc *Client) connect(ctx context.Context, connectionCount int, additionalP
 		case <-ctx.Done():
 			timer.Stop()
 			return nil, nil, ctx.Err()
+		case <-smc.shutdown.stopped():
+			timer.Stop()
+			return nil, nil, errShutdown
 		}
 	}
 }
@@ -335,35 +383,68 @@ func (smc *Client) openAndDial(ctx context.Context, additionalPingHandler func(s
 	return info, conn, err
 }
 
-// runResponseSender runs the handler that reads Socket Mode responses enqueued onto Client.socketModeResponses channel
+// runResponseSender runs the handler that reads Socket Mode responses enqueued onto the first responses channel,
+// which is Client.socketModeResponses unless the connection is part of a pool,
 // and sends them one by one over the WebSocket connection.
 // Gorilla WebSocket is not goroutine safe hence this needs to be the single place you write to the WebSocket connection.
-func (smc *Client) runResponseSender(ctx context.Context, conn *websocket.Conn) error {
+//
+// On Client.Shutdown, it sends the responses pending on all the responses channels and closes the connection.
+func (smc *Client) runResponseSender(ctx context.Context, conn *websocket.Conn, responses ...<-chan *Response) error {
+	stop := smc.shutdown.stopped()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		// 3. listen for messages that need to be sent
-		case res := <-smc.socketModeResponses:
-			smc.Debugf("Sending Socket Mode response with envelope ID %q: %v", res.EnvelopeID, res)
+		case <-stop:
+			smc.flushResponses(ctx, conn, responses)
 
-			if err := unsafeWriteSocketModeResponse(conn, res); err != nil {
-				smc.sendEvent(ctx, newEvent(EventTypeErrorWriteFailed, &ErrorWriteFailed{
-					Cause:    err,
-					Response: res,
-				}))
+			smc.Debugf("Closing the WebSocket connection on shutdown")
+			err := conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			if err != nil {
+				smc.Debugf("Failed to close the WebSocket connection: %v", err)
 			}
 
-			smc.Debugf("Finished sending Socket Mode response with envelope ID %q", res.EnvelopeID)
+			return errShutdown
+		// 3. listen for messages that need to be sent
+		case res := <-responses[0]:
+			smc.writeResponse(ctx, conn, res)
+		}
+	}
+}
+
+// flushResponses sends the responses already enqueued, without waiting for more.
+func (smc *Client) flushResponses(ctx context.Context, conn *websocket.Conn, responses []<-chan *Response) {
+	for _, ch := range responses {
+		for drained := false; !drained; {
+			select {
+			case res := <-ch:
+				smc.writeResponse(ctx, conn, res)
+			default:
+				drained = true
+			}
 		}
 	}
 }
 
+func (smc *Client) writeResponse(ctx context.Context, conn *websocket.Conn, res *Response) {
+	smc.Debugf("Sending Socket Mode response with envelope ID %q: %v", res.EnvelopeID, res)
+
+	if err := unsafeWriteSocketModeResponse(conn, res); err != nil {
+		smc.sendEvent(ctx, newEvent(EventTypeErrorWriteFailed, &ErrorWriteFailed{
+			Cause:    err,
+			Response: res,
+		}))
+	}
+
+	smc.Debugf("Finished sending Socket Mode response with envelope ID %q", res.EnvelopeID)
+}
+
 // runRequestHandler is a blocking function that runs the Socket Mode request receiver.
 //
 // It reads WebSocket messages sent from Slack's Socket Mode WebSocket connection,
 // parses them as Socket Mode requests, and processes them and optionally emit our own events into Client.Events channel.
-func (smc *Client) runRequestHandler(ctx context.Context, websocket chan json.RawMessage) error {
+func (smc *Client) runRequestHandler(ctx context.Context, websocket chan json.RawMessage, hooks *connHooks) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -386,11 +467,30 @@ func (smc *Client) runRequestHandler(ctx context.Context, websocket chan json.Ra
 				}))
 			} else if evt != nil {
 				if evt.Type == EventTypeDisconnect {
+					if hooks != nil && isRefreshDisconnect(evt.Request) {
+						// Slack closes this connection shortly. Open the replacement now and keep serving
+						// this connection until then, so that no envelope is missed.
+						hooks.refresh()
+						continue
+					}
+
 					// We treat the `disconnect` request from Slack as an error internally,
 					// so that we can tell the consumer of this function to reopen the connection on it.
 					return errorRequestedDisconnect{}
 				}
 
+				if evt.Request != nil && evt.Request.EnvelopeID != "" {
+					if hooks != nil && hooks.received(evt.Request.EnvelopeID) {
+						smc.Debugf("Ignoring redelivered envelope ID %q", evt.Request.EnvelopeID)
+						continue
+					}
+					if !smc.shutdown.received(evt.Request.EnvelopeID) {
+						// Left unacknowledged, so that Slack delivers it again
+						smc.Debugf("Dropping envelope ID %q received while shutting down", evt.Request.EnvelopeID)
+						continue
+					}
+				}
+
 				smc.sendEvent(ctx, *evt)
 			}
 		}
@@ -486,12 +586,21 @@ func (smc *Client) SendCtx(ctx context.Context, res Response) error {
 		}
 	}
 
+	// Claim the envelope first, so that the interactive ack timeout of SocketmodeHandler
+	// doesn't ack it meanwhile
+	claimed := smc.shutdown.claim(res.EnvelopeID)
+
 	select {
 	case <-ctx.Done():
+		if claimed {
+			smc.shutdown.unclaim(res.EnvelopeID)
+		}
 		return ctx.Err()
 	case smc.socketModeResponses <- &res:
 	}
 
+	smc.shutdown.responded(res.EnvelopeID)
+
 	return nil
 }
 
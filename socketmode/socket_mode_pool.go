@@ -0,0 +1,285 @@
+package socketmode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/incident-io/slack/internal/expiry"
+)
+
+const (
+	// disconnect reasons announcing that Slack is about to close a connection
+	disconnectReasonWarning          = "warning"
+	disconnectReasonRefreshRequested = "refresh_requested"
+
+	// envelopeTTL is how long received envelope IDs are remembered, to route their
+	// responses and detect redeliveries
+	envelopeTTL = 10 * time.Minute
+)
+
+func isRefreshDisconnect(req *Request) bool {
+	return req != nil && (req.Reason == disconnectReasonWarning || req.Reason == disconnectReasonRefreshRequested)
+}
+
+// connPool holds Client.connections WebSocket connections open in parallel.
+//
+// Responses enqueued with Client.Send are routed to the connection that received the
+// envelope, or to any open connection if that one is gone.
+type connPool struct {
+	smc *Client
+
+	mu    sync.Mutex
+	conns map[*poolConn]struct{}
+	// envelopes maps the envelope IDs received recently to the connection they came from
+	envelopes map[string]envelopeEntry
+	// expiries holds the envelope IDs in the order they expire, to forget them without
+	// scanning envelopes on every message
+	expiries expiry.Queue[string]
+	// changed is closed and replaced whenever a connection is added
+	changed chan struct{}
+
+	wg   sync.WaitGroup
+	errc chan error
+}
+
+type poolConn struct {
+	responses chan *Response
+	closed    bool
+
+	// replaced is set once a replacement has been opened for the connection
+	replaced bool
+}
+
+type envelopeEntry struct {
+	conn       *poolConn
+	receivedAt time.Time
+}
+
+func (smc *Client) runPool(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := &connPool{
+		smc:       smc,
+		conns:     make(map[*poolConn]struct{}),
+		envelopes: make(map[string]envelopeEntry),
+		changed:   make(chan struct{}),
+		errc:      make(chan error, 1),
+	}
+	smc.pool.Store(p)
+	defer smc.pool.Store(nil)
+
+	for i := 0; i < smc.connections; i++ {
+		p.spawn(ctx)
+	}
+
+	go p.routeResponses(ctx)
+
+	var err error
+	select {
+	case err = <-p.errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if errors.Is(err, errShutdown) {
+		// Let every connection flush its responses before cancelling
+		p.wg.Wait()
+	}
+
+	cancel()
+	p.wg.Wait()
+
+	return err
+}
+
+// spawn starts a connection that is reopened until it fails or is replaced.
+func (p *connPool) spawn(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		stop := p.smc.shutdown.stopped()
+
+		for connectionCount := 0; ; connectionCount++ {
+			select {
+			case <-stop:
+				p.fail(errShutdown)
+				return
+			default:
+			}
+
+			c := p.add()
+			err := p.smc.run(ctx, connectionCount, &connHooks{
+				responses: c.responses,
+				received: func(envelopeID string) bool {
+					return p.received(c, envelopeID)
+				},
+				refresh: func() {
+					if p.replace(c) {
+						p.smc.Debugf("Opening a replacement for a connection Slack is about to close")
+						p.spawn(ctx)
+					}
+				},
+			})
+			replaced := p.remove(ctx, c)
+
+			if err != nil {
+				p.fail(err)
+				return
+			}
+			if replaced {
+				return
+			}
+		}
+	}()
+}
+
+// fail reports the error that stops the pool, unless one was already reported.
+func (p *connPool) fail(err error) {
+	select {
+	case p.errc <- err:
+	default:
+	}
+}
+
+func (p *connPool) add() *poolConn {
+	c := &poolConn{responses: make(chan *Response, 20)}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.conns[c] = struct{}{}
+	close(p.changed)
+	p.changed = make(chan struct{})
+
+	return c
+}
+
+// remove removes a connection that is no longer served, and reroutes the responses
+// that were queued for it. It reports whether the connection had been replaced.
+func (p *connPool) remove(ctx context.Context, c *poolConn) bool {
+	p.mu.Lock()
+	delete(p.conns, c)
+	c.closed = true
+	replaced := c.replaced
+	p.mu.Unlock()
+
+	for {
+		select {
+		case res := <-c.responses:
+			go p.deliver(ctx, res)
+		default:
+			return replaced
+		}
+	}
+}
+
+// replace marks the connection as replaced, and reports whether it wasn't already.
+func (p *connPool) replace(c *poolConn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c.replaced {
+		return false
+	}
+	c.replaced = true
+	return true
+}
+
+// received records that the envelope was received over c, and reports whether it
+// was already received over any connection.
+func (p *connPool) received(c *poolConn, envelopeID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.expiries.PopExpired(now, func(id string) {
+		// The envelope may have been forgotten and received again since
+		if e, ok := p.envelopes[id]; ok && now.Sub(e.receivedAt) >= envelopeTTL {
+			delete(p.envelopes, id)
+		}
+	})
+	if _, ok := p.envelopes[envelopeID]; ok {
+		return true
+	}
+
+	p.envelopes[envelopeID] = envelopeEntry{conn: c, receivedAt: now}
+	p.expiries.Push(envelopeID, now.Add(envelopeTTL))
+
+	return false
+}
+
+// forget forgets that the envelope was received, so that its redelivery is emitted again.
+func (p *connPool) forget(envelopeID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.envelopes, envelopeID)
+}
+
+// nack gives up on an envelope without responding to it, so that it is not waited for on
+// shutdown, and that Slack's redelivery of it is emitted again rather than deduplicated.
+func (smc *Client) nack(envelopeID string) {
+	smc.shutdown.responded(envelopeID)
+	if p := smc.pool.Load(); p != nil {
+		p.forget(envelopeID)
+	}
+}
+
+func (p *connPool) routeResponses(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case res := <-p.smc.socketModeResponses:
+			p.deliver(ctx, res)
+		}
+	}
+}
+
+// deliver enqueues the response on the connection that received its envelope, or on
+// any open connection, waiting for one to open if there is none.
+func (p *connPool) deliver(ctx context.Context, res *Response) {
+	for {
+		p.mu.Lock()
+		if c := p.pick(res.EnvelopeID); c != nil {
+			select {
+			case c.responses <- res:
+				p.mu.Unlock()
+				return
+			default:
+			}
+		}
+		changed := p.changed
+		p.mu.Unlock()
+
+		timer := time.NewTimer(100 * time.Millisecond)
+		select {
+		case <-changed:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+	}
+}
+
+// pick returns the connection to send the response to the envelope over. p.mu must be held.
+func (p *connPool) pick(envelopeID string) *poolConn {
+	if e, ok := p.envelopes[envelopeID]; ok && !e.conn.closed {
+		return e.conn
+	}
+	for c := range p.conns {
+		if !c.replaced {
+			return c
+		}
+	}
+	for c := range p.conns {
+		return c
+	}
+	return nil
+}
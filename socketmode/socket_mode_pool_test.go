@@ -0,0 +1,236 @@
+package socketmode
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/incident-io/slack"
+)
+
+// socketTestServer is a minimal Socket Mode server: every connection gets a hello,
+// followed by whatever onConnect writes.
+type socketTestServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	opened  int
+	open    int
+	maxOpen int
+	acks    []Response
+	// closed counts the connections closed by the client with a normal closure
+	closed int
+
+	onConnect func(n int, conn *websocket.Conn)
+}
+
+func newSocketTestServer(onConnect func(n int, conn *websocket.Conn)) *socketTestServer {
+	s := &socketTestServer{onConnect: onConnect}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		url := "ws" + strings.TrimPrefix(s.URL, "http") + "/ws"
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "url": url})
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		s.mu.Lock()
+		s.opened++
+		s.open++
+		if s.open > s.maxOpen {
+			s.maxOpen = s.open
+		}
+		n := s.opened
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			s.open--
+			s.mu.Unlock()
+		}()
+
+		_ = conn.WriteJSON(map[string]interface{}{"type": "hello"})
+		go s.onConnect(n, conn)
+
+		for {
+			var res Response
+			if err := conn.ReadJSON(&res); err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					s.mu.Lock()
+					s.closed++
+					s.mu.Unlock()
+				}
+				return
+			}
+			s.mu.Lock()
+			s.acks = append(s.acks, res)
+			s.mu.Unlock()
+		}
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+func (s *socketTestServer) stats() (opened, maxOpen int, acks []Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opened, s.maxOpen, append([]Response(nil), s.acks...)
+}
+
+// waitClosed waits until the server has read the given number of acks and clean closes.
+func (s *socketTestServer) waitClosed(acks, closed int) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		s.mu.Lock()
+		done := len(s.acks) == acks && s.closed == closed
+		s.mu.Unlock()
+		if done {
+			return true
+		}
+	}
+	return false
+}
+
+func newSocketTestClient(s *socketTestServer, connections int) *Client {
+	api := slack.New("xoxb-test", slack.OptionAPIURL(s.URL+"/"), slack.OptionAppLevelToken("xapp-test"))
+	return New(api, OptionConnections(connections))
+}
+
+const testEnvelope = `{"type":"events_api","envelope_id":"e1","payload":{"type":"event_callback","event":{"type":"app_mention","channel":"C1"}}}`
+
+func TestConnectionPool_DeduplicatesEnvelopes(t *testing.T) {
+	s := newSocketTestServer(func(n int, conn *websocket.Conn) {
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(testEnvelope))
+	})
+	defer s.Close()
+
+	client := newSocketTestClient(s, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunContext(ctx)
+
+	var events int
+	timeout := time.After(500 * time.Millisecond)
+loop:
+	for {
+		select {
+		case evt := <-client.Events:
+			if evt.Type == EventTypeEventsAPI {
+				events++
+				client.Ack(*evt.Request)
+			}
+		case <-timeout:
+			break loop
+		}
+	}
+
+	if events != 1 {
+		t.Fatalf("expected the envelope to be emitted once, got %d", events)
+	}
+
+	opened, _, acks := s.stats()
+	if opened != 2 {
+		t.Fatalf("expected 2 connections, got %d", opened)
+	}
+	if len(acks) != 1 || acks[0].EnvelopeID != "e1" {
+		t.Fatalf("expected a single ack for e1, got %+v", acks)
+	}
+}
+
+func TestConnectionPool_ForgetsExpiredEnvelopes(t *testing.T) {
+	p := &connPool{envelopes: make(map[string]envelopeEntry)}
+	c := &poolConn{}
+
+	p.envelopes["old"] = envelopeEntry{conn: c, receivedAt: time.Now().Add(-2 * envelopeTTL)}
+	p.expiries.Push("old", time.Now().Add(-envelopeTTL))
+
+	if p.received(c, "new") {
+		t.Fatal("expected new to be received for the first time")
+	}
+	if !p.received(c, "new") {
+		t.Fatal("expected new to be detected as a redelivery")
+	}
+	if _, ok := p.envelopes["old"]; ok || p.expiries.Len() != 1 {
+		t.Fatalf("expected old to be forgotten, got %+v", p.envelopes)
+	}
+	if p.received(c, "old") {
+		t.Fatal("expected old to be received again once forgotten")
+	}
+}
+
+func TestConnectionPool_ForgetsNackedEnvelopes(t *testing.T) {
+	smc := &Client{}
+	p := &connPool{smc: smc, envelopes: make(map[string]envelopeEntry)}
+	smc.pool.Store(p)
+	c := &poolConn{}
+
+	if p.received(c, "e1") {
+		t.Fatal("expected e1 to be received for the first time")
+	}
+	smc.nack("e1")
+	if p.received(c, "e1") {
+		t.Fatal("expected the redelivery of nacked e1 to be emitted again")
+	}
+	if !p.received(c, "e1") {
+		t.Fatal("expected e1 to be detected as a redelivery once received again")
+	}
+}
+
+func TestConnectionPool_ReplacesConnectionBeforeClose(t *testing.T) {
+	s := newSocketTestServer(func(n int, conn *websocket.Conn) {
+		if n != 1 {
+			return
+		}
+		_ = conn.WriteJSON(map[string]interface{}{"type": "disconnect", "reason": "warning"})
+
+		// Slack closes the connection some time after the warning
+		time.Sleep(200 * time.Millisecond)
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(testEnvelope))
+		time.Sleep(100 * time.Millisecond)
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		conn.Close()
+	})
+	defer s.Close()
+
+	client := newSocketTestClient(s, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.RunContext(ctx)
+
+	var events int
+	timeout := time.After(time.Second)
+loop:
+	for {
+		select {
+		case evt := <-client.Events:
+			if evt.Type == EventTypeEventsAPI {
+				events++
+			}
+		case <-timeout:
+			break loop
+		}
+	}
+
+	opened, maxOpen, _ := s.stats()
+	if opened != 2 {
+		t.Fatalf("expected a single replacement connection, got %d connections", opened)
+	}
+	if maxOpen != 2 {
+		t.Fatalf("expected the replacement to be opened before the old connection closed")
+	}
+	if events != 1 {
+		t.Fatalf("expected the envelope sent after the warning to be received, got %d", events)
+	}
+}
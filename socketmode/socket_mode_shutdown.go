@@ -0,0 +1,202 @@
+package socketmode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/incident-io/slack/internal/expiry"
+)
+
+// errShutdown is returned internally by the connection loops once Client.Shutdown has
+// flushed the pending responses, and turned into a nil error by RunContext.
+var errShutdown = errors.New("socket mode client shut down")
+
+// shutdownState tracks the envelopes in flight, and coordinates Client.Shutdown with the
+// connection loops. SocketmodeHandler also uses it to ack the envelopes left unacknowledged. Its zero value is ready to use.
+type shutdownState struct {
+	mu sync.Mutex
+	// inflight maps the envelopes emitted on Client.Events and not yet responded to,
+	// to the time they were received
+	inflight map[string]time.Time
+	// claimed holds the envelopes in flight that a response is being enqueued for
+	claimed map[string]struct{}
+	// expiries holds the envelope IDs in the order they were received, to forget the ones
+	// never responded to without scanning inflight
+	expiries expiry.Queue[string]
+	draining bool
+	// idle is closed once draining and no envelope is in flight
+	idle chan struct{}
+	// stop is closed to make the connections flush their responses and close
+	stop chan struct{}
+	// running is closed when RunContext returns, and nil if it was never called
+	running chan struct{}
+}
+
+// Shutdown gracefully stops a running client.
+//
+// Envelopes received from then on are not emitted on Client.Events and not acknowledged, so
+// that Slack delivers them again, e.g. to another instance of the app. Shutdown waits until
+// every envelope already emitted is responded to with Client.Ack or Client.Send, or until ctx
+// is done. It then sends the pending responses, closes the WebSocket connections cleanly and
+// waits for RunContext to return nil.
+//
+// Shutdown returns the number of emitted envelopes that were abandoned without a response,
+// and ctx.Err() if ctx was done first. A client that has been shut down cannot be run again.
+func (smc *Client) Shutdown(ctx context.Context) (abandoned int, err error) {
+	s := &smc.shutdown
+	idle, running := s.drain()
+
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.mu.Lock()
+	abandoned = len(s.inflight)
+	stop := s.stopChan()
+	select {
+	case <-stop:
+	default:
+		close(stop)
+	}
+	s.mu.Unlock()
+
+	if running == nil {
+		return abandoned, err
+	}
+
+	select {
+	case <-running:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return abandoned, err
+}
+
+// startRun records that RunContext is running, and returns the function to call when it returns.
+func (s *shutdownState) startRun() func() {
+	running := make(chan struct{})
+
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+
+	return func() { close(running) }
+}
+
+// drain stops the emission of new envelopes. It returns the channel closed once no envelope
+// is in flight, and the channel closed when RunContext returns.
+func (s *shutdownState) drain() (idle, running <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.draining {
+		s.draining = true
+		s.idle = make(chan struct{})
+		if len(s.inflight) == 0 {
+			close(s.idle)
+		}
+	}
+
+	return s.idle, s.running
+}
+
+// stopChan returns the channel closed when the connections must flush and close. s.mu must be held.
+func (s *shutdownState) stopChan() chan struct{} {
+	if s.stop == nil {
+		s.stop = make(chan struct{})
+	}
+	return s.stop
+}
+
+func (s *shutdownState) stopped() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stopChan()
+}
+
+// received records an envelope about to be emitted on Client.Events. It reports false if
+// the client is shutting down, in which case the envelope must be dropped.
+func (s *shutdownState) received(envelopeID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return false
+	}
+
+	now := time.Now()
+	if s.inflight == nil {
+		s.inflight = make(map[string]time.Time)
+	}
+	// Envelopes that are never acknowledged must not be remembered forever
+	s.expiries.PopExpired(now, func(id string) {
+		// The envelope may have been responded to and received again since
+		if receivedAt, ok := s.inflight[id]; ok && now.Sub(receivedAt) >= envelopeTTL {
+			delete(s.inflight, id)
+			delete(s.claimed, id)
+		}
+	})
+	s.inflight[envelopeID] = now
+	s.expiries.Push(envelopeID, now.Add(envelopeTTL))
+
+	return true
+}
+
+// pending reports whether the envelope was emitted and not responded to yet.
+func (s *shutdownState) pending(envelopeID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.inflight[envelopeID]
+	return ok
+}
+
+// responded records that a response to the envelope was enqueued, and stops tracking it.
+func (s *shutdownState) responded(envelopeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inflight[envelopeID]; !ok {
+		return
+	}
+	delete(s.inflight, envelopeID)
+	delete(s.claimed, envelopeID)
+
+	if s.draining && len(s.inflight) == 0 {
+		close(s.idle)
+	}
+}
+
+// claim reports whether the envelope is pending and not claimed yet, and claims it. Only the
+// caller it returns true to may respond to the envelope, e.g. to ack it on behalf of a late
+// handler. The envelope stays in flight until the response is enqueued.
+func (s *shutdownState) claim(envelopeID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.inflight[envelopeID]; !ok {
+		return false
+	}
+	if _, ok := s.claimed[envelopeID]; ok {
+		return false
+	}
+	if s.claimed == nil {
+		s.claimed = make(map[string]struct{})
+	}
+	s.claimed[envelopeID] = struct{}{}
+	return true
+}
+
+// unclaim gives up on responding to a claimed envelope.
+func (s *shutdownState) unclaim(envelopeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claimed, envelopeID)
+}
@@ -0,0 +1,127 @@
+package socketmode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendTestEnvelope(n int, conn *websocket.Conn) {
+	_ = conn.WriteMessage(websocket.TextMessage, []byte(testEnvelope))
+}
+
+func nextEnvelope(t *testing.T, client *Client) *Event {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case evt := <-client.Events:
+			if evt.Request != nil && evt.Request.EnvelopeID != "" {
+				return &evt
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for an envelope")
+		}
+	}
+}
+
+func TestShutdown_FlushesAcks(t *testing.T) {
+	s := newSocketTestServer(sendTestEnvelope)
+	defer s.Close()
+
+	client := newSocketTestClient(s, 0)
+	runErr := make(chan error, 1)
+	go func() { runErr <- client.RunContext(context.Background()) }()
+
+	evt := nextEnvelope(t, client)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		client.Ack(*evt.Request)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	abandoned, err := client.Shutdown(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, abandoned)
+	assert.NoError(t, <-runErr)
+
+	assert.True(t, s.waitClosed(1, 1), "expected the ack to be flushed before a clean close")
+}
+
+func TestShutdown_ReportsAbandonedEnvelopes(t *testing.T) {
+	s := newSocketTestServer(sendTestEnvelope)
+	defer s.Close()
+
+	client := newSocketTestClient(s, 2)
+	runErr := make(chan error, 1)
+	go func() { runErr <- client.RunContext(context.Background()) }()
+
+	nextEnvelope(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	abandoned, err := client.Shutdown(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, abandoned)
+
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("RunContext did not return")
+	}
+}
+
+func TestSocketmodeHandler_ShutdownWaitsForHandlers(t *testing.T) {
+	s := newSocketTestServer(sendTestEnvelope)
+	defer s.Close()
+
+	r := NewSocketmodeHandler(newSocketTestClient(s, 0))
+	handled := make(chan struct{})
+	r.Handle(EventTypeEventsAPI, func(evt *Event, c *Client) {
+		close(handled)
+		time.Sleep(50 * time.Millisecond)
+		c.Ack(*evt.Request)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() { runErr <- r.RunEventLoopContext(ctx) }()
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the handler")
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+	defer shutdownCancel()
+	abandoned, err := r.Shutdown(shutdownCtx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, abandoned)
+	assert.NoError(t, <-runErr)
+
+	assert.True(t, s.waitClosed(1, 1), "expected the ack to be flushed before a clean close")
+}
+
+func TestShutdownState_ForgetsExpiredEnvelopes(t *testing.T) {
+	var s shutdownState
+	now := time.Now()
+
+	// old was received long ago and never responded to, kept was received again since
+	s.inflight = map[string]time.Time{"old": now.Add(-2 * envelopeTTL), "kept": now}
+	s.expiries.Push("old", now.Add(-envelopeTTL))
+	s.expiries.Push("kept", now.Add(-envelopeTTL))
+
+	assert.True(t, s.received("new"))
+	assert.False(t, s.pending("old"))
+	assert.True(t, s.pending("kept"))
+	assert.True(t, s.pending("new"))
+}
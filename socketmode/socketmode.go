@@ -36,6 +36,7 @@ const (
 	EventTypeIncomingError    = EventType("incoming_error")
 	EventTypeErrorWriteFailed = EventType("write_error")
 	EventTypeErrorBadMessage  = EventType("error_bad_message")
+	EventTypeHandlerPanic     = EventType("handler_panic")
 
 	//
 	// The following event types are guaranteed to not change unless Slack changes
@@ -49,6 +50,9 @@ const (
 
 	websocketDefaultTimeout = 10 * time.Second
 	defaultMaxPingInterval  = 30 * time.Second
+
+	// maxConnections is the maximum number of concurrent Socket Mode connections allowed by Slack
+	maxConnections = 10
 )
 
 // Open calls the "apps.connections.open" endpoint and returns the provided URL and the full Info block.
@@ -88,6 +92,19 @@ func OptionPingInterval(d time.Duration) Option {
 	}
 }
 
+// OptionConnections makes the client hold n WebSocket connections open in parallel, up to
+// the maximum of 10 allowed by Slack. Slack load-balances envelopes across the connections,
+// all of which feed Client.Events. Envelopes redelivered over another connection are emitted
+// only once, and a connection Slack is about to close is replaced before it is closed.
+func OptionConnections(n int) Option {
+	return func(smc *Client) {
+		if n > maxConnections {
+			n = maxConnections
+		}
+		smc.connections = n
+	}
+}
+
 // OptionDebug enable debugging for the client
 func OptionDebug(b bool) func(*Client) {
 	return func(c *Client) {
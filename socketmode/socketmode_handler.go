@@ -2,6 +2,9 @@ package socketmode
 
 import (
 	"context"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/incident-io/slack"
 	"github.com/incident-io/slack/slackevents"
@@ -18,6 +21,24 @@ type SocketmodeHandler struct {
 	//lvl 3 - the most userfriendly way of managing event
 	InteractionBlockActionEventMap map[string]SocketmodeHandlerFunc
 	SlashCommandMap                map[string]SocketmodeHandlerFunc
+	ViewSubmissionMap              map[string]SocketmodeHandlerFunc
+	ViewClosedMap                  map[string]SocketmodeHandlerFunc
+	ShortcutMap                    map[string]SocketmodeHandlerFunc
+	MessageShortcutMap             map[string]SocketmodeHandlerFunc
+	BlockSuggestionMap             map[string]SocketmodeHandlerFunc
+
+	// interactionRoutes are the pattern based routes, tried in registration
+	// order when no exact ID matches.
+	interactionRoutes []interactionRoute
+
+	// pool runs the handlers when configured with UseWorkerPool
+	pool *workerPool
+
+	// running counts the events whose handlers have not returned yet
+	running runningCount
+
+	// runtime configures how the handlers are run, see UseRuntime
+	runtime RuntimeConfig
 
 	Default SocketmodeHandlerFunc
 }
@@ -25,6 +46,46 @@ type SocketmodeHandler struct {
 // Handler have access to the event and socketmode client
 type SocketmodeHandlerFunc func(*Event, *Client)
 
+// SocketmodeAckHandlerFunc is a handler that returns the payload to ack the
+// request with, e.g. a *slack.ViewSubmissionResponse or *slack.OptionsResponse.
+// A nil payload acks the request without a payload.
+type SocketmodeAckHandlerFunc func(*Event, *Client) interface{}
+
+// AckWith adapts a SocketmodeAckHandlerFunc into a SocketmodeHandlerFunc that
+// acks the request with the returned payload, so that the handler does not
+// have to call Client.Ack itself.
+func AckWith(f SocketmodeAckHandlerFunc) SocketmodeHandlerFunc {
+	return func(evt *Event, c *Client) {
+		payload := f(evt, c)
+		if evt.Request == nil {
+			return
+		}
+		c.Ack(*evt.Request, payload)
+	}
+}
+
+// IDMatcher reports whether an action_id or callback_id matches a pattern
+// based route.
+type IDMatcher func(id string) bool
+
+// MatchPrefix returns an IDMatcher matching IDs starting with prefix.
+func MatchPrefix(prefix string) IDMatcher {
+	return func(id string) bool {
+		return strings.HasPrefix(id, prefix)
+	}
+}
+
+// MatchRegexp returns an IDMatcher matching IDs matched by re.
+func MatchRegexp(re *regexp.Regexp) IDMatcher {
+	return re.MatchString
+}
+
+type interactionRoute struct {
+	interactionType slack.InteractionType
+	match           IDMatcher
+	f               SocketmodeHandlerFunc
+}
+
 // Middleware accept SocketmodeHandlerFunc, and return SocketmodeHandlerFunc
 type SocketmodeMiddlewareFunc func(SocketmodeHandlerFunc) SocketmodeHandlerFunc
 
@@ -36,6 +97,11 @@ func NewSocketmodeHandler(client *Client) *SocketmodeHandler {
 
 	interactionBlockActionEventMap := make(map[string]SocketmodeHandlerFunc)
 	slackCommandMap := make(map[string]SocketmodeHandlerFunc)
+	viewSubmissionMap := make(map[string]SocketmodeHandlerFunc)
+	viewClosedMap := make(map[string]SocketmodeHandlerFunc)
+	shortcutMap := make(map[string]SocketmodeHandlerFunc)
+	messageShortcutMap := make(map[string]SocketmodeHandlerFunc)
+	blockSuggestionMap := make(map[string]SocketmodeHandlerFunc)
 
 	return &SocketmodeHandler{
 		Client:                         client,
@@ -44,6 +110,11 @@ func NewSocketmodeHandler(client *Client) *SocketmodeHandler {
 		InteractionEventMap:            interactionEventMap,
 		InteractionBlockActionEventMap: interactionBlockActionEventMap,
 		SlashCommandMap:                slackCommandMap,
+		ViewSubmissionMap:              viewSubmissionMap,
+		ViewClosedMap:                  viewClosedMap,
+		ShortcutMap:                    shortcutMap,
+		MessageShortcutMap:             messageShortcutMap,
+		BlockSuggestionMap:             blockSuggestionMap,
 		Default: func(e *Event, c *Client) {
 			c.log.Printf("Unexpected event type received: %v\n", e.Type)
 		},
@@ -60,8 +131,12 @@ func (r *SocketmodeHandler) Handle(et EventType, f SocketmodeHandlerFunc) {
 // There is several types of interactions, decated functions lets you better handle them
 // See
 // * HandleInteractionBlockAction
-// * (Not Implemented) HandleShortcut
-// * (Not Implemented) HandleView
+// * HandleBlockSuggestion
+// * HandleViewSubmission
+// * HandleViewClosed
+// * HandleShortcut
+// * HandleMessageShortcut
+// * HandleInteractionMatch
 func (r *SocketmodeHandler) HandleInteraction(et slack.InteractionType, f SocketmodeHandlerFunc) {
 	r.InteractionEventMap[et] = append(r.InteractionEventMap[et], f)
 }
@@ -80,6 +155,62 @@ func (r *SocketmodeHandler) HandleInteractionBlockAction(actionID string, f Sock
 	r.InteractionBlockActionEventMap[actionID] = f
 }
 
+// Register a middleware or handler for a Block Suggestion referenced by its ActionID
+func (r *SocketmodeHandler) HandleBlockSuggestion(actionID string, f SocketmodeHandlerFunc) {
+	registerInteractionID(r.BlockSuggestionMap, "actionID", actionID, f)
+}
+
+// Register a middleware or handler for a View Submission referenced by the CallbackID of the view
+func (r *SocketmodeHandler) HandleViewSubmission(callbackID string, f SocketmodeHandlerFunc) {
+	registerInteractionID(r.ViewSubmissionMap, "callbackID", callbackID, f)
+}
+
+// Register a middleware or handler for a View Closed referenced by the CallbackID of the view
+func (r *SocketmodeHandler) HandleViewClosed(callbackID string, f SocketmodeHandlerFunc) {
+	registerInteractionID(r.ViewClosedMap, "callbackID", callbackID, f)
+}
+
+// Register a middleware or handler for a global Shortcut referenced by its CallbackID
+func (r *SocketmodeHandler) HandleShortcut(callbackID string, f SocketmodeHandlerFunc) {
+	registerInteractionID(r.ShortcutMap, "callbackID", callbackID, f)
+}
+
+// Register a middleware or handler for a Message Shortcut referenced by its CallbackID
+func (r *SocketmodeHandler) HandleMessageShortcut(callbackID string, f SocketmodeHandlerFunc) {
+	registerInteractionID(r.MessageShortcutMap, "callbackID", callbackID, f)
+}
+
+// Register a middleware or handler for interactions of the given type whose ID matches.
+// The ID is the ActionID for block_actions and block_suggestion, and the CallbackID
+// for view_submission, view_closed, shortcut and message_action.
+// Handlers registered for an exact ID take precedence, then routes are tried in registration order.
+func (r *SocketmodeHandler) HandleInteractionMatch(et slack.InteractionType, match IDMatcher, f SocketmodeHandlerFunc) {
+	if match == nil {
+		panic("invalid matcher cannot be nil")
+	}
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+	r.interactionRoutes = append(r.interactionRoutes, interactionRoute{
+		interactionType: et,
+		match:           match,
+		f:               f,
+	})
+}
+
+func registerInteractionID(m map[string]SocketmodeHandlerFunc, kind, id string, f SocketmodeHandlerFunc) {
+	if id == "" {
+		panic("invalid " + kind + " cannot be empty")
+	}
+	if f == nil {
+		panic("invalid handler cannot be nil")
+	}
+	if _, exist := m[id]; exist {
+		panic("multiple registrations for " + kind + " " + id)
+	}
+	m[id] = f
+}
+
 // Register a middleware or handler for an Event (from slackevents)
 func (r *SocketmodeHandler) HandleEvents(et slackevents.EventsAPIType, f SocketmodeHandlerFunc) {
 	r.EventApiMap[et] = append(r.EventApiMap[et], f)
@@ -120,6 +251,11 @@ func (r *SocketmodeHandler) RunEventLoopContext(ctx context.Context) error {
 
 // Call the dispatcher for each incomming event
 func (r *SocketmodeHandler) runEventLoop(ctx context.Context) {
+	if r.pool != nil {
+		r.pool.start(r.Client)
+		defer r.pool.stop()
+	}
+
 	for {
 		select {
 		case evt, ok := <-r.Client.Events:
@@ -137,125 +273,217 @@ func (r *SocketmodeHandler) runEventLoop(ctx context.Context) {
 
 // Dispatch events to the specialized dispatcher
 func (r *SocketmodeHandler) dispatcher(evt Event) {
-	var ishandled bool
+	var handlers []SocketmodeHandlerFunc
 
 	// Some eventType can be further decomposed
 	switch evt.Type {
 	case EventTypeInteractive:
-		ishandled = r.interactionDispatcher(&evt)
+		handlers = r.interactionDispatcher(&evt)
 	case EventTypeEventsAPI:
-		ishandled = r.eventAPIDispatcher(&evt)
+		handlers = r.eventAPIDispatcher(&evt)
 	case EventTypeSlashCommand:
-		ishandled = r.slashCommandDispatcher(&evt)
+		handlers = r.slashCommandDispatcher(&evt)
 	default:
-		ishandled = r.socketmodeDispatcher(&evt)
+		handlers = r.socketmodeDispatcher(&evt)
+	}
+
+	if len(handlers) == 0 {
+		handlers = []SocketmodeHandlerFunc{r.Default}
+	}
+
+	r.run(&evt, handlers)
+}
+
+// Run the handlers of an event, each in its own goroutine or on the worker pool
+func (r *SocketmodeHandler) run(evt *Event, handlers []SocketmodeHandlerFunc) {
+	handlers = r.prepare(evt, handlers)
+
+	if r.pool != nil {
+		r.running.add(1)
+		r.pool.submit(evt, handlers, r.running.done)
+		return
 	}
 
-	if !ishandled {
-		go r.Default(&evt, r.Client)
+	r.running.add(len(handlers))
+	for _, f := range handlers {
+		go func(f SocketmodeHandlerFunc) {
+			defer r.running.done()
+			f(evt, r.Client)
+		}(f)
 	}
 }
 
-// Dispatch socketmode events to the registered middleware
-func (r *SocketmodeHandler) socketmodeDispatcher(evt *Event) bool {
-	if handlers, ok := r.EventMap[evt.Type]; ok {
-		// If we registered an event
-		for _, f := range handlers {
-			go f(evt, r.Client)
-		}
+// Shutdown gracefully stops the client of the handler, see Client.Shutdown. It waits for the
+// handlers that are running or queued on the worker pool to return, or for ctx to be done,
+// before waiting for the envelopes in flight to be acknowledged.
+//
+// It returns the number of envelopes abandoned without a response.
+func (r *SocketmodeHandler) Shutdown(ctx context.Context) (int, error) {
+	// Stop receiving new envelopes first, so that no handler starts meanwhile
+	r.Client.shutdown.drain()
+
+	select {
+	case <-r.running.idle():
+	case <-ctx.Done():
+	}
+
+	return r.Client.Shutdown(ctx)
+}
 
-		return true
+// runningCount counts the handlers that have not returned yet. Unlike a sync.WaitGroup, it can be
+// incremented while Shutdown waits for it to drop to zero. Its zero value is ready to use.
+type runningCount struct {
+	mu sync.Mutex
+	n  int
+	// zero is closed once n drops to zero, and nil until idle is called
+	zero chan struct{}
+}
+
+func (c *runningCount) add(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.n += n
+}
+
+func (c *runningCount) done() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.n--
+	if c.n == 0 && c.zero != nil {
+		close(c.zero)
+		c.zero = nil
 	}
+}
+
+// idle returns a channel closed once no handler is running.
+func (c *runningCount) idle() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return false
+	if c.n == 0 {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+	if c.zero == nil {
+		c.zero = make(chan struct{})
+	}
+	return c.zero
 }
 
-// Dispatch interactions to the registered middleware
-func (r *SocketmodeHandler) interactionDispatcher(evt *Event) bool {
-	var ishandled bool = false
+// Dispatch socketmode events to the registered middleware
+func (r *SocketmodeHandler) socketmodeDispatcher(evt *Event) []SocketmodeHandlerFunc {
+	// Copy, as the callers append to the returned slice
+	return append([]SocketmodeHandlerFunc(nil), r.EventMap[evt.Type]...)
+}
 
+// Dispatch interactions to the registered middleware
+func (r *SocketmodeHandler) interactionDispatcher(evt *Event) []SocketmodeHandlerFunc {
 	interaction, ok := evt.Data.(slack.InteractionCallback)
 	if !ok {
 		r.Client.log.Printf("Ignored %+v\n", evt)
-		return false
+		return nil
 	}
 
 	// Level 1 - socketmode EventType
-	ishandled = r.socketmodeDispatcher(evt)
+	handlers := r.socketmodeDispatcher(evt)
 
 	// Level 2 - interaction EventType
-	if handlers, ok := r.InteractionEventMap[interaction.Type]; ok {
-		// If we registered an event
-		for _, f := range handlers {
-			go f(evt, r.Client)
-		}
-
-		ishandled = true
-	}
+	handlers = append(handlers, r.InteractionEventMap[interaction.Type]...)
 
-	// Level 3 - interaction with actionID
-	blockActions := interaction.ActionCallback.BlockActions
+	// Level 3 - interaction with actionID or callbackID
 	// outmoded approach won`t be implemented
 	// attachments_actions := interaction.ActionCallback.AttachmentActions
+	idMap := r.interactionIDMap(interaction.Type)
 
-	for _, action := range blockActions {
-		if handler, ok := r.InteractionBlockActionEventMap[action.ActionID]; ok {
+	for _, id := range interactionIDs(interaction) {
+		if handler, ok := idMap[id]; ok {
+			handlers = append(handlers, handler)
+			continue
+		}
 
-			go handler(evt, r.Client)
+		for _, route := range r.interactionRoutes {
+			if route.interactionType == interaction.Type && route.match(id) {
+				handlers = append(handlers, route.f)
+				break
+			}
+		}
+	}
+	return handlers
+}
 
-			ishandled = true
+// interactionIDMap returns the handlers registered by exact ID for the interaction type
+func (r *SocketmodeHandler) interactionIDMap(et slack.InteractionType) map[string]SocketmodeHandlerFunc {
+	switch et {
+	case slack.InteractionTypeBlockActions:
+		return r.InteractionBlockActionEventMap
+	case slack.InteractionTypeBlockSuggestion:
+		return r.BlockSuggestionMap
+	case slack.InteractionTypeViewSubmission:
+		return r.ViewSubmissionMap
+	case slack.InteractionTypeViewClosed:
+		return r.ViewClosedMap
+	case slack.InteractionTypeShortcut:
+		return r.ShortcutMap
+	case slack.InteractionTypeMessageAction:
+		return r.MessageShortcutMap
+	}
+	return nil
+}
+
+// interactionIDs returns the IDs an interaction is routed by
+func interactionIDs(interaction slack.InteractionCallback) []string {
+	switch interaction.Type {
+	case slack.InteractionTypeBlockActions:
+		ids := make([]string, 0, len(interaction.ActionCallback.BlockActions))
+		for _, action := range interaction.ActionCallback.BlockActions {
+			ids = append(ids, action.ActionID)
 		}
+		return ids
+	case slack.InteractionTypeBlockSuggestion:
+		return []string{interaction.ActionID}
+	case slack.InteractionTypeViewSubmission, slack.InteractionTypeViewClosed:
+		return []string{interaction.View.CallbackID}
+	case slack.InteractionTypeShortcut, slack.InteractionTypeMessageAction:
+		return []string{interaction.CallbackID}
 	}
-	return ishandled
+	return nil
 }
 
 // Dispatch eventAPI events to the registered middleware
-func (r *SocketmodeHandler) eventAPIDispatcher(evt *Event) bool {
-	var ishandled bool = false
+func (r *SocketmodeHandler) eventAPIDispatcher(evt *Event) []SocketmodeHandlerFunc {
 	eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
 	if !ok {
 		r.Client.log.Printf("Ignored %+v\n", evt)
-		return false
+		return nil
 	}
 
 	innerEventType := slackevents.EventsAPIType(eventsAPIEvent.InnerEvent.Type)
 
 	// Level 1 - socketmode EventType
-	ishandled = r.socketmodeDispatcher(evt)
+	handlers := r.socketmodeDispatcher(evt)
 
 	// Level 2 - EventAPI EventType
-	if handlers, ok := r.EventApiMap[innerEventType]; ok {
-		// If we registered an event
-		for _, f := range handlers {
-			go f(evt, r.Client)
-		}
-
-		ishandled = true
-	}
-
-	return ishandled
+	return append(handlers, r.EventApiMap[innerEventType]...)
 }
 
 // Dispatch SlashCommands events to the registered middleware
-func (r *SocketmodeHandler) slashCommandDispatcher(evt *Event) bool {
-	var ishandled bool = false
+func (r *SocketmodeHandler) slashCommandDispatcher(evt *Event) []SocketmodeHandlerFunc {
 	slashCommandEvent, ok := evt.Data.(slack.SlashCommand)
 	if !ok {
 		r.Client.log.Printf("Ignored %+v\n", evt)
-		return false
+		return nil
 	}
 
 	// Level 1 - socketmode EventType
-	ishandled = r.socketmodeDispatcher(evt)
+	handlers := r.socketmodeDispatcher(evt)
 
 	// Level 2 - SlackCommand by name
 	if handler, ok := r.SlashCommandMap[slashCommandEvent.Command]; ok {
-
-		go handler(evt, r.Client)
-
-		ishandled = true
+		handlers = append(handlers, handler)
 	}
 
-	return ishandled
-
+	return handlers
 }
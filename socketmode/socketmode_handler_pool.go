@@ -0,0 +1,288 @@
+package socketmode
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+)
+
+const (
+	defaultPoolConcurrency = 10
+	defaultPoolQueueSize   = 100
+)
+
+// OverflowPolicy decides what happens to an event when the queue of the worker pool is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for room in the queue. The event loop stops reading Client.Events meanwhile,
+	// which in turn stops the client from reading the WebSocket.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop acks the request, unless it was already acked, and discards the event.
+	OverflowDrop
+	// OverflowNack discards the event without acking the request, so that Slack retries it later.
+	OverflowNack
+)
+
+// WorkerPoolConfig configures the worker pool of a SocketmodeHandler.
+type WorkerPoolConfig struct {
+	// MaxConcurrency is the maximum number of events processed at once. Defaults to 10.
+	MaxConcurrency int
+	// QueueSize is the maximum number of events waiting for a worker. Defaults to 100.
+	QueueSize int
+	// Key returns the ordering key of an event. When set, events with the same non-empty key are
+	// processed one at a time, in the order they were received. See KeyByChannel and KeyByThread.
+	Key func(*Event) string
+	// Overflow is the policy applied to events received while the queue is full.
+	Overflow OverflowPolicy
+	// OnOverflow is called for every event dropped or nacked by the Overflow policy, e.g. to record a metric.
+	OnOverflow func(*Event, OverflowPolicy)
+}
+
+// UseWorkerPool makes the handler process events on a bounded pool of workers instead of starting a
+// goroutine per handler. The handlers matching an event run one after the other on the same worker.
+// It must be called before running the event loop.
+func (r *SocketmodeHandler) UseWorkerPool(cfg WorkerPoolConfig) {
+	r.pool = newWorkerPool(cfg)
+}
+
+type poolJob struct {
+	evt      *Event
+	handlers []SocketmodeHandlerFunc
+	// done is called once the job is processed or discarded
+	done func()
+}
+
+type workerPool struct {
+	cfg    WorkerPoolConfig
+	queues []chan poolJob
+	next   uint64
+
+	client *Client
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newWorkerPool(cfg WorkerPoolConfig) *workerPool {
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = defaultPoolConcurrency
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultPoolQueueSize
+	}
+
+	p := &workerPool{cfg: cfg}
+
+	// Without ordering all workers share a single queue. With ordering each worker has its own
+	// queue, and events are assigned to a worker by key.
+	if cfg.Key == nil {
+		p.queues = []chan poolJob{make(chan poolJob, cfg.QueueSize)}
+		return p
+	}
+
+	size := cfg.QueueSize / cfg.MaxConcurrency
+	if size < 1 {
+		size = 1
+	}
+	for i := 0; i < cfg.MaxConcurrency; i++ {
+		p.queues = append(p.queues, make(chan poolJob, size))
+	}
+	return p
+}
+
+func (p *workerPool) start(client *Client) {
+	p.client = client
+	p.done = make(chan struct{})
+
+	for i := 0; i < p.cfg.MaxConcurrency; i++ {
+		q := p.queues[i%len(p.queues)]
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work(q)
+		}()
+	}
+}
+
+// stop stops the workers once they finish their current event. The events still queued are
+// released without a response, so that Slack delivers them again.
+func (p *workerPool) stop() {
+	close(p.done)
+	p.wg.Wait()
+
+	for _, q := range p.queues {
+	drain:
+		for {
+			select {
+			case job := <-q:
+				p.release(job.evt)
+				job.done()
+			default:
+				break drain
+			}
+		}
+	}
+}
+
+// release gives up on the envelope of an event without acking it, so that it is neither acked
+// late nor waited for on shutdown
+func (p *workerPool) release(evt *Event) {
+	if evt.Request != nil && evt.Request.EnvelopeID != "" {
+		p.client.nack(evt.Request.EnvelopeID)
+	}
+}
+
+func (p *workerPool) work(q chan poolJob) {
+	for {
+		// Once stopped, leave the queued events to stop even if some are ready
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+
+		select {
+		case <-p.done:
+			return
+		case job := <-q:
+			for _, f := range job.handlers {
+				f(job.evt, p.client)
+			}
+			job.done()
+		}
+	}
+}
+
+func (p *workerPool) submit(evt *Event, handlers []SocketmodeHandlerFunc, done func()) {
+	q := p.queue(evt)
+	job := poolJob{evt: evt, handlers: handlers, done: done}
+
+	if p.cfg.Overflow == OverflowBlock {
+		select {
+		case q <- job:
+		case <-p.done:
+			done()
+		}
+		return
+	}
+
+	select {
+	case q <- job:
+		return
+	default:
+	}
+
+	done()
+
+	if p.cfg.Overflow == OverflowDrop && evt.Request != nil && evt.Request.EnvelopeID != "" {
+		// The envelope may already be acked, e.g. with RuntimeConfig.AutoAckEventsAPI
+		if p.client.shutdown.claim(evt.Request.EnvelopeID) {
+			p.client.Ack(*evt.Request)
+		}
+	} else {
+		// Nacked envelopes are left for Slack to retry
+		p.release(evt)
+	}
+	if p.cfg.OnOverflow != nil {
+		p.cfg.OnOverflow(evt, p.cfg.Overflow)
+	}
+}
+
+func (p *workerPool) queue(evt *Event) chan poolJob {
+	if len(p.queues) == 1 {
+		return p.queues[0]
+	}
+
+	key := p.cfg.Key(evt)
+	if key == "" {
+		return p.queues[atomic.AddUint64(&p.next, 1)%uint64(len(p.queues))]
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return p.queues[h.Sum32()%uint32(len(p.queues))]
+}
+
+// KeyByChannel is a WorkerPoolConfig.Key that processes the events of each channel in order.
+func KeyByChannel(evt *Event) string {
+	channel, _ := eventLocation(evt)
+	return channel
+}
+
+// KeyByThread is a WorkerPoolConfig.Key that processes the events of each thread in order.
+// Events outside of a thread are ordered per channel.
+func KeyByThread(evt *Event) string {
+	channel, thread := eventLocation(evt)
+	if channel == "" || thread == "" {
+		return channel
+	}
+	return channel + "/" + thread
+}
+
+// eventLocation returns the channel and thread timestamp an event relates to, if any.
+func eventLocation(evt *Event) (channel, thread string) {
+	switch data := evt.Data.(type) {
+	case slackevents.EventsAPIEvent:
+		var raw []byte
+		if evt.Request != nil && len(evt.Request.Payload) > 0 {
+			var payload struct {
+				Event json.RawMessage `json:"event"`
+			}
+			if err := json.Unmarshal(evt.Request.Payload, &payload); err == nil {
+				raw = payload.Event
+			}
+		}
+		if raw == nil {
+			var err error
+			if raw, err = json.Marshal(data.InnerEvent.Data); err != nil {
+				return "", ""
+			}
+		}
+		return innerEventLocation(raw)
+	case slack.InteractionCallback:
+		channel = data.Channel.ID
+		if channel == "" {
+			channel = data.Container.ChannelID
+		}
+		thread = data.Container.ThreadTs
+		if thread == "" {
+			thread = data.Message.ThreadTimestamp
+		}
+		return channel, thread
+	case slack.SlashCommand:
+		return data.ChannelID, ""
+	}
+	return "", ""
+}
+
+func innerEventLocation(raw []byte) (channel, thread string) {
+	var loc struct {
+		Channel  json.RawMessage `json:"channel"`
+		ThreadTs string          `json:"thread_ts"`
+		Item     struct {
+			Channel string `json:"channel"`
+		} `json:"item"`
+	}
+	if err := json.Unmarshal(raw, &loc); err != nil {
+		return "", ""
+	}
+
+	// channel is an ID for most events, but an object for e.g. channel_created
+	if err := json.Unmarshal(loc.Channel, &channel); err != nil {
+		var obj struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(loc.Channel, &obj) == nil {
+			channel = obj.ID
+		}
+	}
+	if channel == "" {
+		channel = loc.Item.Channel
+	}
+	return channel, loc.ThreadTs
+}
@@ -0,0 +1,288 @@
+package socketmode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+)
+
+func slashCommandEvent(channel string, n int) Event {
+	return Event{
+		Type:    EventTypeSlashCommand,
+		Data:    slack.SlashCommand{Command: "/incident", ChannelID: channel, Text: string(rune('a' + n))},
+		Request: &Request{EnvelopeID: "envelope"},
+	}
+}
+
+func TestWorkerPool_MaxConcurrency(t *testing.T) {
+	r := init_SocketmodeHandler()
+	r.UseWorkerPool(WorkerPoolConfig{MaxConcurrency: 2, QueueSize: 10})
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	r.HandleSlashCommand("/incident", func(evt *Event, c *Client) {
+		defer wg.Done()
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+
+	r.pool.start(r.Client)
+	defer r.pool.stop()
+
+	wg.Add(6)
+	for i := 0; i < 6; i++ {
+		r.dispatcher(slashCommandEvent("C1", i))
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent handlers, got %d", peak)
+	}
+}
+
+func TestWorkerPool_OrderedByKey(t *testing.T) {
+	r := init_SocketmodeHandler()
+	r.UseWorkerPool(WorkerPoolConfig{MaxConcurrency: 4, QueueSize: 40, Key: KeyByChannel})
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	got := map[string]string{}
+	r.HandleSlashCommand("/incident", func(evt *Event, c *Client) {
+		defer wg.Done()
+		cmd := evt.Data.(slack.SlashCommand)
+		mu.Lock()
+		got[cmd.ChannelID] += cmd.Text
+		mu.Unlock()
+	})
+
+	r.pool.start(r.Client)
+	defer r.pool.stop()
+
+	wg.Add(10)
+	for i := 0; i < 5; i++ {
+		r.dispatcher(slashCommandEvent("C1", i))
+		r.dispatcher(slashCommandEvent("C2", i))
+	}
+	wg.Wait()
+
+	for _, channel := range []string{"C1", "C2"} {
+		if got[channel] != "abcde" {
+			t.Fatalf("expected events of %s in order, got %q", channel, got[channel])
+		}
+	}
+}
+
+func TestWorkerPool_Overflow(t *testing.T) {
+	for _, tt := range []struct {
+		policy OverflowPolicy
+		acked  bool
+	}{
+		{OverflowDrop, true},
+		{OverflowNack, false},
+	} {
+		r := init_SocketmodeHandler()
+		r.Client.socketModeResponses = make(chan *Response, 10)
+
+		var overflowed int32
+		r.UseWorkerPool(WorkerPoolConfig{
+			MaxConcurrency: 1,
+			QueueSize:      1,
+			Overflow:       tt.policy,
+			OnOverflow: func(evt *Event, policy OverflowPolicy) {
+				if policy != tt.policy {
+					t.Errorf("unexpected policy %v", policy)
+				}
+				atomic.AddInt32(&overflowed, 1)
+			},
+		})
+		r.HandleSlashCommand("/incident", func(evt *Event, c *Client) {})
+		r.pool.client = r.Client
+
+		// Without started workers the queue holds a single event
+		for i := 0; i < 3; i++ {
+			evt := slashCommandEvent("C1", i)
+			evt.Request.EnvelopeID = fmt.Sprintf("e%d", i)
+			r.Client.shutdown.received(evt.Request.EnvelopeID)
+			r.dispatcher(evt)
+		}
+
+		if overflowed != 2 {
+			t.Fatalf("expected 2 overflowed events, got %d", overflowed)
+		}
+		if acked := len(r.Client.socketModeResponses) == 2; acked != tt.acked {
+			t.Fatalf("expected acked to be %v with policy %v", tt.acked, tt.policy)
+		}
+	}
+}
+
+func TestWorkerPool_DropAutoAcked(t *testing.T) {
+	r := init_SocketmodeHandler()
+	r.Client.socketModeResponses = make(chan *Response, 10)
+	r.UseRuntime(RuntimeConfig{AutoAckEventsAPI: true})
+	r.UseWorkerPool(WorkerPoolConfig{MaxConcurrency: 1, QueueSize: 1, Overflow: OverflowDrop})
+	r.HandleEvents(slackevents.AppMention, func(evt *Event, c *Client) {})
+	r.pool.client = r.Client
+
+	for i := 0; i < 2; i++ {
+		evt := Event{
+			Type: EventTypeEventsAPI,
+			Data: slackevents.EventsAPIEvent{
+				Type:       slackevents.CallbackEvent,
+				InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.AppMention), Data: &slackevents.AppMentionEvent{}},
+			},
+			Request: &Request{EnvelopeID: fmt.Sprintf("e%d", i)},
+		}
+		r.Client.shutdown.received(evt.Request.EnvelopeID)
+		r.dispatcher(evt)
+	}
+
+	if n := len(r.Client.socketModeResponses); n != 2 {
+		t.Fatalf("expected each envelope to be acked once, got %d acks", n)
+	}
+}
+
+func TestKeyByThread(t *testing.T) {
+	tests := []struct {
+		name string
+		evt  Event
+		want string
+	}{
+		{
+			name: "message in thread",
+			evt: Event{
+				Type: EventTypeEventsAPI,
+				Data: slackevents.EventsAPIEvent{},
+				Request: &Request{
+					Payload: json.RawMessage(`{"event":{"type":"message","channel":"C1","thread_ts":"123.456"}}`),
+				},
+			},
+			want: "C1/123.456",
+		},
+		{
+			name: "reaction on item",
+			evt: Event{
+				Type: EventTypeEventsAPI,
+				Data: slackevents.EventsAPIEvent{
+					InnerEvent: slackevents.EventsAPIInnerEvent{
+						Data: &slackevents.ReactionAddedEvent{Item: slackevents.Item{Channel: "C2"}},
+					},
+				},
+			},
+			want: "C2",
+		},
+		{
+			name: "channel object",
+			evt: Event{
+				Type: EventTypeEventsAPI,
+				Data: slackevents.EventsAPIEvent{},
+				Request: &Request{
+					Payload: json.RawMessage(`{"event":{"type":"channel_created","channel":{"id":"C3"}}}`),
+				},
+			},
+			want: "C3",
+		},
+		{
+			name: "interaction",
+			evt: Event{
+				Type: EventTypeInteractive,
+				Data: slack.InteractionCallback{Container: slack.Container{ChannelID: "C4", ThreadTs: "1.2"}},
+			},
+			want: "C4/1.2",
+		},
+		{
+			name: "connection event",
+			evt:  Event{Type: EventTypeConnected},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyByThread(&tt.evt); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWorkerPool_StopReleasesQueuedEvents(t *testing.T) {
+	r := init_SocketmodeHandler()
+	r.UseWorkerPool(WorkerPoolConfig{MaxConcurrency: 1, QueueSize: 10})
+
+	started, unblock := make(chan struct{}), make(chan struct{})
+	var calls int32
+	r.HandleSlashCommand("/incident", func(evt *Event, c *Client) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-unblock
+		}
+	})
+
+	r.pool.start(r.Client)
+	for i := 0; i < 3; i++ {
+		evt := slashCommandEvent("C1", i)
+		evt.Request.EnvelopeID = string(rune('a' + i))
+		r.Client.shutdown.received(evt.Request.EnvelopeID)
+		r.dispatcher(evt)
+	}
+	<-started
+
+	idle := r.running.idle()
+	stopped := make(chan struct{})
+	go func() {
+		r.pool.stop()
+		close(stopped)
+	}()
+	// Let stop close the pool before the running handler returns
+	time.Sleep(10 * time.Millisecond)
+	close(unblock)
+	<-stopped
+
+	select {
+	case <-idle:
+	case <-time.After(time.Second):
+		t.Fatal("expected the queued events to be released")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected the queued events not to be handled, got %d calls", n)
+	}
+	for _, id := range []string{"b", "c"} {
+		if r.Client.shutdown.pending(id) {
+			t.Fatalf("expected envelope %s to be released", id)
+		}
+	}
+}
+
+func TestSocketmodeHandler_RunningCount(t *testing.T) {
+	var c runningCount
+	select {
+	case <-c.idle():
+	default:
+		t.Fatal("expected a zero count to be idle")
+	}
+
+	c.add(2)
+	idle := c.idle()
+	c.done()
+	c.add(1)
+	c.done()
+	select {
+	case <-idle:
+		t.Fatal("expected the count not to be idle yet")
+	default:
+	}
+	c.done()
+	<-idle
+}
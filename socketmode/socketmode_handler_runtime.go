@@ -0,0 +1,88 @@
+package socketmode
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// maxInteractiveAckTimeout leaves time for the ack to reach Slack, which requires acks within 3 seconds.
+const maxInteractiveAckTimeout = 2500 * time.Millisecond
+
+// RuntimeConfig configures how a SocketmodeHandler runs its handlers.
+type RuntimeConfig struct {
+	// AutoAckEventsAPI acks events_api envelopes as soon as they are received, before running the
+	// handlers, which then must not ack them.
+	AutoAckEventsAPI bool
+	// InteractiveAckTimeout is the time after which an interactive envelope that its handlers haven't
+	// acked is acked with an empty payload, so that users don't see an error in Slack. It is capped
+	// at 2.5s. Zero disables it.
+	InteractiveAckTimeout time.Duration
+	// RecoverPanics recovers the panics of handlers into EventTypeHandlerPanic events, which can be
+	// handled with Handle, instead of crashing the program.
+	RecoverPanics bool
+}
+
+// UseRuntime configures how the handlers are run. It must be called before running the event loop.
+func (r *SocketmodeHandler) UseRuntime(cfg RuntimeConfig) {
+	if cfg.InteractiveAckTimeout > maxInteractiveAckTimeout {
+		cfg.InteractiveAckTimeout = maxInteractiveAckTimeout
+	}
+	r.runtime = cfg
+}
+
+// prepare applies the RuntimeConfig to an event about to be handled, and returns the handlers to run.
+func (r *SocketmodeHandler) prepare(evt *Event, handlers []SocketmodeHandlerFunc) []SocketmodeHandlerFunc {
+	if evt.Request != nil && evt.Request.EnvelopeID != "" {
+		switch {
+		case evt.Type == EventTypeEventsAPI && r.runtime.AutoAckEventsAPI:
+			r.Client.Ack(*evt.Request)
+		case evt.Type == EventTypeInteractive && r.runtime.InteractiveAckTimeout > 0:
+			req := *evt.Request
+			time.AfterFunc(r.runtime.InteractiveAckTimeout, func() {
+				// Claim the envelope, so that neither the handler nor the concurrent timers,
+				// e.g. of a redelivered envelope, ack it as well
+				if r.Client.shutdown.claim(req.EnvelopeID) {
+					r.Client.Debugf("Acking envelope ID %q that was not acked in time", req.EnvelopeID)
+					r.Client.Ack(req)
+				}
+			})
+		}
+	}
+
+	if !r.runtime.RecoverPanics {
+		return handlers
+	}
+
+	recovering := make([]SocketmodeHandlerFunc, len(handlers))
+	for i, f := range handlers {
+		recovering[i] = r.recoverPanics(f)
+	}
+	return recovering
+}
+
+func (r *SocketmodeHandler) recoverPanics(f SocketmodeHandlerFunc) SocketmodeHandlerFunc {
+	return func(evt *Event, c *Client) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+
+			stack := debug.Stack()
+			if evt.Type == EventTypeHandlerPanic {
+				// Don't loop on a panicking panic handler
+				r.Client.log.Printf("Panic in the handler of a panic: %v\n%s", v, stack)
+				return
+			}
+
+			// Never block the handler: the event loop may be waiting on the worker pool
+			select {
+			case c.Events <- newEvent(EventTypeHandlerPanic, &HandlerPanic{Event: evt, Value: v, Stack: stack}):
+			default:
+				r.Client.log.Printf("Dropped the panic of a handler: %v\n%s", v, stack)
+			}
+		}()
+
+		f(evt, c)
+	}
+}
@@ -0,0 +1,134 @@
+package socketmode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/incident-io/slack"
+	"github.com/incident-io/slack/slackevents"
+	"github.com/stretchr/testify/assert"
+)
+
+func initRuntimeHandler(cfg RuntimeConfig) *SocketmodeHandler {
+	r := init_SocketmodeHandler()
+	r.Client.Events = make(chan Event, 10)
+	r.Client.socketModeResponses = make(chan *Response, 10)
+	r.UseRuntime(cfg)
+	return r
+}
+
+func TestRuntime_AutoAckEventsAPI(t *testing.T) {
+	r := initRuntimeHandler(RuntimeConfig{AutoAckEventsAPI: true})
+	r.Handle(EventTypeEventsAPI, func(evt *Event, c *Client) {})
+
+	r.dispatcher(Event{
+		Type:    EventTypeEventsAPI,
+		Data:    slackevents.EventsAPIEvent{},
+		Request: &Request{EnvelopeID: "e1"},
+	})
+
+	select {
+	case res := <-r.Client.socketModeResponses:
+		assert.Equal(t, "e1", res.EnvelopeID)
+	default:
+		t.Fatal("expected the envelope to be acked on receipt")
+	}
+}
+
+func TestRuntime_InteractiveAckTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		acks  bool
+		wants int
+	}{
+		{"handler acks", true, 1},
+		{"handler forgets to ack", false, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := initRuntimeHandler(RuntimeConfig{InteractiveAckTimeout: 20 * time.Millisecond})
+			r.Handle(EventTypeInteractive, func(evt *Event, c *Client) {
+				if tt.acks {
+					c.Ack(*evt.Request)
+				}
+			})
+
+			// Received over the WebSocket
+			r.Client.shutdown.received("e1")
+			r.dispatcher(Event{
+				Type:    EventTypeInteractive,
+				Data:    slack.InteractionCallback{Type: slack.InteractionTypeBlockActions},
+				Request: &Request{EnvelopeID: "e1"},
+			})
+
+			time.Sleep(50 * time.Millisecond)
+			assert.Len(t, r.Client.socketModeResponses, tt.wants)
+		})
+	}
+}
+
+func TestRuntime_InteractiveAckTimeoutAcksRedeliveriesOnce(t *testing.T) {
+	r := initRuntimeHandler(RuntimeConfig{InteractiveAckTimeout: 20 * time.Millisecond})
+	r.Handle(EventTypeInteractive, func(evt *Event, c *Client) {})
+
+	r.Client.shutdown.received("e1")
+	for i := 0; i < 2; i++ {
+		r.dispatcher(Event{
+			Type:    EventTypeInteractive,
+			Data:    slack.InteractionCallback{Type: slack.InteractionTypeBlockActions},
+			Request: &Request{EnvelopeID: "e1"},
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, r.Client.socketModeResponses, 1)
+}
+
+func TestRuntime_InteractiveAckTimeoutWhileHandlerAcks(t *testing.T) {
+	r := initRuntimeHandler(RuntimeConfig{InteractiveAckTimeout: 20 * time.Millisecond})
+	// The ack of the handler is still being queued when the timeout expires
+	r.Client.socketModeResponses = make(chan *Response)
+	r.Handle(EventTypeInteractive, AckWith(func(evt *Event, c *Client) interface{} {
+		return "payload"
+	}))
+
+	r.Client.shutdown.received("e1")
+	r.dispatcher(Event{
+		Type:    EventTypeInteractive,
+		Data:    slack.InteractionCallback{Type: slack.InteractionTypeBlockActions},
+		Request: &Request{EnvelopeID: "e1"},
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	res := <-r.Client.socketModeResponses
+	assert.Equal(t, "payload", res.Payload)
+	select {
+	case res := <-r.Client.socketModeResponses:
+		t.Fatalf("expected a single ack, got another one with %v", res.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRuntime_InteractiveAckTimeoutIsCapped(t *testing.T) {
+	r := initRuntimeHandler(RuntimeConfig{InteractiveAckTimeout: 5 * time.Second})
+	assert.Equal(t, maxInteractiveAckTimeout, r.runtime.InteractiveAckTimeout)
+}
+
+func TestRuntime_RecoverPanics(t *testing.T) {
+	r := initRuntimeHandler(RuntimeConfig{RecoverPanics: true})
+	r.HandleSlashCommand("/incident", func(evt *Event, c *Client) {
+		panic("boom")
+	})
+
+	r.dispatcher(slashCommandEvent("C1", 0))
+
+	select {
+	case evt := <-r.Client.Events:
+		assert.Equal(t, EventTypeHandlerPanic, evt.Type)
+		p := evt.Data.(*HandlerPanic)
+		assert.Equal(t, "boom", p.Value)
+		assert.Equal(t, EventTypeSlashCommand, p.Event.Type)
+		assert.Contains(t, string(p.Stack), "TestRuntime_RecoverPanics")
+	case <-time.After(time.Second):
+		t.Fatal("expected a handler_panic event")
+	}
+}
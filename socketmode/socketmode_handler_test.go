@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"regexp"
 	"runtime"
 	"testing"
 
@@ -27,6 +28,11 @@ func init_SocketmodeHandler() *SocketmodeHandler {
 		InteractionEventMap:            interactioneventMap,
 		InteractionBlockActionEventMap: interactionBlockActionEventMap,
 		SlashCommandMap:                slashCommandMap,
+		ViewSubmissionMap:              make(map[string]SocketmodeHandlerFunc),
+		ViewClosedMap:                  make(map[string]SocketmodeHandlerFunc),
+		ShortcutMap:                    make(map[string]SocketmodeHandlerFunc),
+		MessageShortcutMap:             make(map[string]SocketmodeHandlerFunc),
+		BlockSuggestionMap:             make(map[string]SocketmodeHandlerFunc),
 	}
 }
 
@@ -513,3 +519,201 @@ func TestSocketmodeHandler_Handle_errors(t *testing.T) {
 		})
 	}
 }
+
+func middleware_interaction_callback_id(evt *Event, client *Client) {
+	//do nothing
+}
+
+func middleware_interaction_pattern(evt *Event, client *Client) {
+	//do nothing
+}
+
+func TestSocketmodeHandler_HandleInteractionByID(t *testing.T) {
+	type args struct {
+		evt      Event
+		register func(*SocketmodeHandler, chan<- string)
+	}
+	tests := []struct {
+		name string
+		args args
+		want string //what is the name of the function we want to be called
+	}{
+		{
+			name: "View submission matches callbackID",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type: slack.InteractionTypeViewSubmission,
+						View: slack.View{CallbackID: "create_incident"},
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleViewSubmission("create_incident", testing_wrapper(c, middleware_interaction_callback_id))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_callback_id",
+		}, {
+			name: "View closed matches callbackID",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type: slack.InteractionTypeViewClosed,
+						View: slack.View{CallbackID: "create_incident"},
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleViewSubmission("create_incident", testing_wrapper(c, middleware_interaction))
+					r.HandleViewClosed("create_incident", testing_wrapper(c, middleware_interaction_callback_id))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_callback_id",
+		}, {
+			name: "Shortcut matches callbackID",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type:       slack.InteractionTypeShortcut,
+						CallbackID: "declare",
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleShortcut("declare", testing_wrapper(c, middleware_interaction_callback_id))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_callback_id",
+		}, {
+			name: "Message shortcut matches callbackID",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type:       slack.InteractionTypeMessageAction,
+						CallbackID: "declare",
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleShortcut("declare", testing_wrapper(c, middleware_interaction))
+					r.HandleMessageShortcut("declare", testing_wrapper(c, middleware_interaction_callback_id))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_callback_id",
+		}, {
+			name: "Block suggestion matches actionID",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type:     slack.InteractionTypeBlockSuggestion,
+						ActionID: "severity",
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleBlockSuggestion("severity", testing_wrapper(c, middleware_interaction_callback_id))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_callback_id",
+		}, {
+			name: "Block action matches prefix",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type: slack.InteractionTypeBlockActions,
+						ActionCallback: slack.ActionCallbacks{
+							BlockActions: []*slack.BlockAction{{ActionID: "incident:01ABC:resolve"}},
+						},
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleInteractionMatch(slack.InteractionTypeBlockActions, MatchPrefix("incident:"), testing_wrapper(c, middleware_interaction_pattern))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_pattern",
+		}, {
+			name: "View submission matches regexp",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type: slack.InteractionTypeViewSubmission,
+						View: slack.View{CallbackID: "edit_incident_42"},
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleInteractionMatch(slack.InteractionTypeViewSubmission, MatchRegexp(regexp.MustCompile(`^edit_incident_\d+$`)), testing_wrapper(c, middleware_interaction_pattern))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_pattern",
+		}, {
+			name: "Exact ID takes precedence over pattern",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type:       slack.InteractionTypeShortcut,
+						CallbackID: "declare",
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleInteractionMatch(slack.InteractionTypeShortcut, MatchPrefix("dec"), testing_wrapper(c, middleware_interaction_pattern))
+					r.HandleShortcut("declare", testing_wrapper(c, middleware_interaction_callback_id))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.middleware_interaction_callback_id",
+		}, {
+			name: "Pattern for another interaction type does not match",
+			args: args{
+				evt: Event{
+					Type: EventTypeInteractive,
+					Data: slack.InteractionCallback{
+						Type:       slack.InteractionTypeShortcut,
+						CallbackID: "declare",
+					},
+				},
+				register: func(r *SocketmodeHandler, c chan<- string) {
+					r.HandleInteractionMatch(slack.InteractionTypeMessageAction, MatchPrefix("dec"), testing_wrapper(c, middleware_interaction_pattern))
+				},
+			},
+			want: "github.com/incident-io/slack/socketmode.defaultmiddleware",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := init_SocketmodeHandler()
+
+			c := make(chan string)
+
+			tt.args.register(r, c)
+			r.HandleDefault(testing_wrapper(c, defaultmiddleware))
+
+			r.dispatcher(tt.args.evt)
+
+			got := <-c
+
+			if got != tt.want {
+				t.Fatalf("%s was not called for EventTy(\"%v\"), got %v", tt.want, tt.args.evt.Type, got)
+			}
+		})
+	}
+}
+
+func TestAckWith(t *testing.T) {
+	c := &Client{socketModeResponses: make(chan *Response, 1)}
+	payload := slack.NewErrorsViewSubmissionResponse(map[string]string{"title": "required"})
+
+	f := AckWith(func(evt *Event, client *Client) interface{} {
+		return payload
+	})
+	f(&Event{Type: EventTypeInteractive, Request: &Request{EnvelopeID: "envelope"}}, c)
+
+	res := <-c.socketModeResponses
+	if res.EnvelopeID != "envelope" {
+		t.Fatalf("unexpected envelope ID %q", res.EnvelopeID)
+	}
+	if res.Payload != payload {
+		t.Fatalf("unexpected payload %v", res.Payload)
+	}
+}
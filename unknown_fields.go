@@ -0,0 +1,136 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// knownFieldsCache caches the set of JSON object keys modelled by a struct type.
+var knownFieldsCache sync.Map // map[reflect.Type]map[string]struct{}
+
+// knownFields returns the (lower-cased) JSON keys that encoding/json maps onto
+// fields of t, following promoted fields of embedded structs.
+func knownFields(t reflect.Type) map[string]struct{} {
+	if cached, ok := knownFieldsCache.Load(t); ok {
+		return cached.(map[string]struct{})
+	}
+
+	known := map[string]struct{}{}
+	collectKnownFields(t, known)
+	knownFieldsCache.Store(t, known)
+	return known
+}
+
+func collectKnownFields(t reflect.Type, known map[string]struct{}) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectKnownFields(ft, known)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		known[strings.ToLower(name)] = struct{}{}
+	}
+}
+
+// unmarshalUnknownFields returns the members of the JSON object in data whose
+// keys are not modelled by the struct type of v. It returns nil when there are
+// none, so that values without extra fields compare equal to literals.
+func unmarshalUnknownFields(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	known := knownFields(reflect.TypeOf(v))
+	var unknown map[string]json.RawMessage
+	for k, val := range raw {
+		if _, ok := known[strings.ToLower(k)]; ok {
+			continue
+		}
+		if unknown == nil {
+			unknown = map[string]json.RawMessage{}
+		}
+		unknown[k] = val
+	}
+	return unknown, nil
+}
+
+// marshalWithUnknownFields marshals v and appends the members of unknown to the
+// resulting JSON object. Keys that v already models are skipped so that the
+// typed fields always win.
+func marshalWithUnknownFields(v interface{}, unknown map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendJSONObjectFields(b, unknown, knownFields(reflect.TypeOf(v)))
+}
+
+// appendJSONObjectFields appends fields to the JSON object in obj, in key order,
+// skipping any key present in skip.
+func appendJSONObjectFields(obj []byte, fields map[string]json.RawMessage, skip map[string]struct{}) ([]byte, error) {
+	if len(fields) == 0 {
+		return obj, nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if _, ok := skip[strings.ToLower(k)]; ok {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return obj, nil
+	}
+	sort.Strings(keys)
+
+	obj = bytes.TrimSpace(obj)
+	buf := bytes.NewBuffer(obj[:len(obj)-1])
+	empty := len(bytes.TrimSpace(obj[1:len(obj)-1])) == 0
+	for _, k := range keys {
+		if !empty {
+			buf.WriteByte(',')
+		}
+		empty = false
+
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(fields[k])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package slack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var messageWithUnknownFields = `{
+    "type": "message",
+    "user": "U2147483697",
+    "text": "Hello world",
+    "ts": "1355517523.000005",
+    "some_new_field": {"nested": [1, 2, 3]},
+    "attachments": [
+        {
+            "text": "attachment",
+            "new_attachment_field": "kept"
+        }
+    ],
+    "blocks": [
+        {
+            "type": "some_future_block",
+            "block_id": "b1",
+            "payload": {"a": "b"}
+        }
+    ],
+    "message": {
+        "text": "sub message",
+        "sub_message_field": true
+    }
+}`
+
+func TestMessageUnknownFields(t *testing.T) {
+	message, err := unmarshalMessage(messageWithUnknownFields)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "Hello world", message.Text)
+	assert.Equal(t, map[string]json.RawMessage{
+		"some_new_field": json.RawMessage(`{"nested": [1, 2, 3]}`),
+	}, message.UnknownFields)
+
+	assert.Len(t, message.Attachments, 1)
+	assert.Equal(t, map[string]json.RawMessage{
+		"new_attachment_field": json.RawMessage(`"kept"`),
+	}, message.Attachments[0].UnknownFields)
+
+	assert.NotNil(t, message.SubMessage)
+	assert.Equal(t, "sub message", message.SubMessage.Text)
+	assert.Equal(t, map[string]json.RawMessage{
+		"sub_message_field": json.RawMessage(`true`),
+	}, message.SubMessage.UnknownFields)
+
+	block, ok := message.Blocks.BlockSet[0].(*UnknownBlock)
+	assert.True(t, ok)
+	assert.Equal(t, "b1", block.BlockID)
+
+	out, err := json.Marshal(message)
+	assert.Nil(t, err)
+
+	var expected, actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(messageWithUnknownFields), &expected))
+	assert.Nil(t, json.Unmarshal(out, &actual))
+	assert.Equal(t, expected["some_new_field"], actual["some_new_field"])
+	assert.Equal(t, "kept", actual["attachments"].([]interface{})[0].(map[string]interface{})["new_attachment_field"])
+	assert.Equal(t, expected["blocks"], actual["blocks"])
+	assert.Equal(t, expected["message"].(map[string]interface{})["sub_message_field"],
+		actual["message"].(map[string]interface{})["sub_message_field"])
+}
+
+func TestMessageWithoutUnknownFields(t *testing.T) {
+	message, err := unmarshalMessage(simpleMessage)
+	assert.Nil(t, err)
+	assert.Nil(t, message.UnknownFields)
+	assert.Nil(t, message.SubMessage)
+}
+
+func TestMessageEventUnknownFields(t *testing.T) {
+	var event MessageEvent
+	err := json.Unmarshal([]byte(messageWithUnknownFields), &event)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello world", event.Text)
+	assert.NotNil(t, event.SubMessage)
+	assert.Contains(t, event.UnknownFields, "some_new_field")
+	assert.NotContains(t, event.UnknownFields, "message")
+}
+
+func TestChannelUnknownFields(t *testing.T) {
+	raw := `{"id":"C1","name":"general","is_channel":true,"is_new_thing":true}`
+
+	var channel Channel
+	err := json.Unmarshal([]byte(raw), &channel)
+	assert.Nil(t, err)
+	assert.Equal(t, "C1", channel.ID)
+	assert.Equal(t, "general", channel.Name)
+	assert.Equal(t, map[string]json.RawMessage{
+		"is_new_thing": json.RawMessage(`true`),
+	}, channel.UnknownFields)
+
+	out, err := json.Marshal(channel)
+	assert.Nil(t, err)
+
+	var actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out, &actual))
+	assert.Equal(t, true, actual["is_new_thing"])
+	assert.Equal(t, "general", actual["name"])
+}
+
+func TestUserUnknownFields(t *testing.T) {
+	raw := `{"id":"U1","name":"spengler","is_workflow_bot":false,"who_can_share_contact_card":"EVERYONE"}`
+
+	var user User
+	err := json.Unmarshal([]byte(raw), &user)
+	assert.Nil(t, err)
+	assert.Equal(t, "U1", user.ID)
+	assert.Equal(t, "EVERYONE", user.WhoCanShareContactCard)
+	assert.Equal(t, map[string]json.RawMessage{
+		"is_workflow_bot": json.RawMessage(`false`),
+	}, user.UnknownFields)
+
+	out, err := json.Marshal(user)
+	assert.Nil(t, err)
+
+	var actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out, &actual))
+	assert.Equal(t, false, actual["is_workflow_bot"])
+}
+
+func TestMarshalUnknownFieldsDoesNotOverrideKnownFields(t *testing.T) {
+	msg := Msg{
+		Text: "typed",
+		UnknownFields: map[string]json.RawMessage{
+			"text":  json.RawMessage(`"raw"`),
+			"extra": json.RawMessage(`1`),
+		},
+	}
+
+	out, err := json.Marshal(msg)
+	assert.Nil(t, err)
+
+	var actual map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out, &actual))
+	assert.Equal(t, "typed", actual["text"])
+	assert.Equal(t, float64(1), actual["extra"])
+}
@@ -147,6 +147,32 @@ type User struct {
 	Updated                JSONTime       `json:"updated"`
 	WhoCanShareContactCard string         `json:"who_can_share_contact_card,omitempty"`
 	Enterprise             EnterpriseUser `json:"enterprise_user,omitempty"`
+
+	// UnknownFields holds any fields of the user that this library does not
+	// model. They are re-emitted when the user is marshalled.
+	UnknownFields map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for User,
+// retaining unrecognized fields in UnknownFields.
+func (u *User) UnmarshalJSON(data []byte) error {
+	type alias User
+	if err := json.Unmarshal(data, (*alias)(u)); err != nil {
+		return err
+	}
+	unknown, err := unmarshalUnknownFields(data, (*alias)(u))
+	if err != nil {
+		return err
+	}
+	u.UnknownFields = unknown
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for User, re-emitting
+// any UnknownFields.
+func (u User) MarshalJSON() ([]byte, error) {
+	type alias User
+	return marshalWithUnknownFields((*alias)(&u), u.UnknownFields)
 }
 
 // UserPresence contains details about a user online status
@@ -206,7 +232,7 @@ type TeamIdentity struct {
 
 type userResponseFull struct {
 	Members []User `json:"members,omitempty"`
-	User    `json:"user,omitempty"`
+	User    User   `json:"user,omitempty"`
 	Users   []User `json:"users,omitempty"`
 	UserPresence
 	SlackResponse
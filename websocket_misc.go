@@ -32,6 +32,18 @@ func (s RTMError) Error() string {
 // MessageEvent represents a Slack Message (used as the event type for an incoming message)
 type MessageEvent Message
 
+// UnmarshalJSON implements the json.Unmarshaler interface for MessageEvent by
+// delegating to Message.
+func (e *MessageEvent) UnmarshalJSON(data []byte) error {
+	return (*Message)(e).UnmarshalJSON(data)
+}
+
+// MarshalJSON implements the json.Marshaler interface for MessageEvent by
+// delegating to Message.
+func (e MessageEvent) MarshalJSON() ([]byte, error) {
+	return Message(e).MarshalJSON()
+}
+
 // RTMEvent is the main wrapper. You will find all the other messages attached
 type RTMEvent struct {
 	Type string